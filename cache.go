@@ -1,6 +1,10 @@
 package rf
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
 	"time"
 
 	"gorm.io/gorm"
@@ -15,6 +19,10 @@ const (
 )
 
 // FeedsItemsCache is an interface of feeds items' cache
+//
+// Deprecated: errors are swallowed (logged) in `Exists`, `Fetch`, and `List`,
+// so an unavailable cache looks like an empty one. Use `FeedsItemsCacheV2` instead.
+// (`NewFeedsItemsCacheAdapter` adapts a `FeedsItemsCacheV2` to this interface)
 type FeedsItemsCache interface {
 	Exists(guid string) bool
 	Save(item gofeed.Item, title, summary string) error
@@ -23,32 +31,168 @@ type FeedsItemsCache interface {
 	List(includeItemsMarkedAsRead bool) []CachedItem
 	DeleteOlderThan1Month() error
 
+	// batch operations (run in a single transaction or lock)
+	SaveMany(items []ItemToCache) BatchResults
+	MarkManyAsRead(guids []string) BatchResults
+	DeleteMany(guids []string) BatchResults
+
+	// export/import of cached items
+	ForEach(fn func(item CachedItem) error) error
+	Import(items []CachedItem, mode ImportMode) BatchResults
+
+	SetVerbose(v bool)
+	Close() error
+}
+
+// FeedsItemsCacheV2 is an interface of feeds items' cache with contexts and errors
+//
+// Errors wrap `ErrNotFound` when a cached item does not exist,
+// and `ErrUnavailable` when the cache itself failed (eg. locked or corrupt DB file).
+type FeedsItemsCacheV2 interface {
+	Exists(ctx context.Context, guid string) (bool, error)
+	Save(ctx context.Context, item ItemToCache) error
+	Fetch(ctx context.Context, guid string) (*CachedItem, error)
+	MarkAsRead(ctx context.Context, guid string) error
+	List(ctx context.Context, includeItemsMarkedAsRead bool) ([]CachedItem, error)
+	ListWithFilter(ctx context.Context, filter ListFilter) ([]CachedItem, error)
+	ListCategories(ctx context.Context) ([]string, error) // (distinct categories of cached items, sorted)
+	DeleteOlderThan1Month(ctx context.Context) error
+
+	// batch operations (run in a single transaction or lock)
+	SaveMany(ctx context.Context, items []ItemToCache) BatchResults
+	MarkManyAsRead(ctx context.Context, guids []string) BatchResults
+	DeleteMany(ctx context.Context, guids []string) BatchResults
+
+	// export/import of cached items
+	ForEach(ctx context.Context, fn func(item CachedItem) error) error
+	Import(ctx context.Context, items []CachedItem, mode ImportMode) BatchResults
+
+	// original contents of cached items (deleted along with their items)
+	SaveContent(ctx context.Context, content OriginalContent) error
+	FetchContent(ctx context.Context, guid string) (*OriginalContent, error)
+
+	// summaries and their revisions (deleted along with their items)
+	UpdateSummary(ctx context.Context, guid, title, summary string) error
+	SaveRevision(ctx context.Context, revision SummaryRevision) error
+	ListRevisions(ctx context.Context, guid string) ([]SummaryRevision, error)
+
+	// feeds which cached items came from
+	SaveFeed(ctx context.Context, feed Feed) error
+	FetchFeed(ctx context.Context, url string) (*Feed, error)
+	ListFeeds(ctx context.Context) ([]Feed, error)
+	MoveFeed(ctx context.Context, oldURL, newURL string) error // (cached items of the feed are moved too)
+
+	// digests of cached items
+	SaveDigest(ctx context.Context, digest *Digest) error // (id and timestamps of `digest` are set)
+	FetchDigest(ctx context.Context, id uint) (*Digest, error)
+	ListDigests(ctx context.Context) ([]Digest, error)
+
+	// deliveries of cached items to sinks (deleted along with their items)
+	SaveDelivery(ctx context.Context, delivery Delivery) error // (inserted, or updated by its sink and guid)
+	ListDeliveries(ctx context.Context, sink string) ([]Delivery, error)
+
 	SetVerbose(v bool)
+	Close() error
+}
+
+// errors of cache
+var (
+	ErrNotFound    = errors.New("cached item not found")
+	ErrUnavailable = errors.New("cache unavailable")
+)
+
+// ItemToCache is a struct for an item to be cached
+type ItemToCache struct {
+	Item    gofeed.Item
+	Title   string
+	Summary string
+	FeedURL string // url of the feed which the item came from (optional)
+}
+
+// convert to a CachedItem
+func (i ItemToCache) cachedItem() CachedItem {
+	cached := newCachedItem(i.Item, i.Title, i.Summary)
+	cached.FeedURL = i.FeedURL
+	return cached
+}
+
+// ListFilter is a struct for filtering listed cached items
+type ListFilter struct {
+	IncludeItemsMarkedAsRead bool
+	FeedURL                  string    // url of the feed which items came from (empty for all feeds)
+	Category                 string    // category which items belong to (empty for all categories)
+	Since                    time.Time // items cached at or after this time (zero for no lower bound)
+	Until                    time.Time // items cached before this time (zero for no upper bound)
+}
+
+// BatchResult is a struct for the result of a batch operation on a single item
+type BatchResult struct {
+	GUID string
+	Err  error
+}
+
+// BatchResults is a list of per-item results of a batch operation
+type BatchResults []BatchResult
+
+// Succeeded returns the guids of items which were processed successfully.
+func (r BatchResults) Succeeded() (guids []string) {
+	for _, result := range r {
+		if result.Err == nil {
+			guids = append(guids, result.GUID)
+		}
+	}
+	return guids
+}
+
+// Err returns the errors of failed items joined, or nil if all items succeeded.
+func (r BatchResults) Err() error {
+	var errs []error
+	for _, result := range r {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("item '%s': %w", result.GUID, result.Err))
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // CachedItem is a struct for a cached item
 type CachedItem struct {
 	gorm.Model
 
-	Title       string
-	Link        string // url to the original article
-	Comments    string // url to the community comments
-	GUID        string `gorm:"uniqueIndex"`
-	Author      string
-	PublishDate string
-	Description string
+	Title         string
+	OriginalTitle string // title before translation (for re-summarizing)
+	Link          string // url to the original article
+	Comments      string // url to the community comments
+	GUID          string `gorm:"uniqueIndex"`
+	Author        string
+	PublishDate   string
+	UpdateDate    string
+	Description   string
+	FeedURL       string `gorm:"index"` // url of the feed which the item came from
+
+	ImageURL   string
+	Categories []string    `gorm:"serializer:json"`
+	Enclosures []Enclosure `gorm:"serializer:json"` // eg. audio of podcasts
 
 	Summary      string
 	MarkedAsRead bool `gorm:"index"`
 }
 
+// Enclosure is a struct for a media file attached to a cached item
+type Enclosure struct {
+	URL    string
+	Length string // in bytes
+	Type   string // eg. "audio/mpeg"
+}
+
 // newCachedItem converts a gofeed.Item to a CachedItem.
 func newCachedItem(item gofeed.Item, title, summary string) CachedItem {
 	cached := CachedItem{
-		Title:       title,
-		GUID:        item.GUID,
-		Description: item.Description,
-		Summary:     summary,
+		Title:         title,
+		OriginalTitle: item.Title,
+		GUID:          item.GUID,
+		Description:   item.Description,
+		Summary:       summary,
 	}
 	if len(item.Links) > 0 {
 		cached.Link = item.Links[0]
@@ -66,5 +210,21 @@ func newCachedItem(item gofeed.Item, title, summary string) CachedItem {
 	if item.PublishedParsed != nil {
 		cached.PublishDate = item.PublishedParsed.Format(time.RFC3339)
 	}
+	if item.UpdatedParsed != nil {
+		cached.UpdateDate = item.UpdatedParsed.Format(time.RFC3339)
+	}
+	if item.Image != nil {
+		cached.ImageURL = item.Image.URL
+	}
+	cached.Categories = slices.Clone(item.Categories)
+	for _, enclosure := range item.Enclosures {
+		if enclosure != nil && len(enclosure.URL) > 0 {
+			cached.Enclosures = append(cached.Enclosures, Enclosure{
+				URL:    enclosure.URL,
+				Length: enclosure.Length,
+				Type:   enclosure.Type,
+			})
+		}
+	}
 	return cached
 }
@@ -0,0 +1,108 @@
+package rf
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/mmcdole/gofeed"
+)
+
+////////////////
+//
+// (adapter of `FeedsItemsCacheV2` to `FeedsItemsCache`)
+//
+
+// feedsItemsCacheAdapter adapts a `FeedsItemsCacheV2` to the old `FeedsItemsCache` interface
+type feedsItemsCacheAdapter struct {
+	cache FeedsItemsCacheV2
+}
+
+// NewFeedsItemsCacheAdapter returns a `FeedsItemsCache` which delegates to given `FeedsItemsCacheV2`.
+//
+// Errors of `Exists`, `Fetch`, and `List` are logged and swallowed as before.
+func NewFeedsItemsCacheAdapter(cache FeedsItemsCacheV2) FeedsItemsCache {
+	return &feedsItemsCacheAdapter{cache: cache}
+}
+
+// Exists checks for the existence of `guid` in the cache.
+func (a *feedsItemsCacheAdapter) Exists(guid string) bool {
+	exists, err := a.cache.Exists(context.Background(), guid)
+	if err != nil {
+		log.Printf("failed to check existence of cached item with guid '%s': %s", guid, err)
+		return false
+	}
+	return exists
+}
+
+// Save saves given item to the cache.
+func (a *feedsItemsCacheAdapter) Save(item gofeed.Item, title, summary string) error {
+	return a.cache.Save(context.Background(), ItemToCache{Item: item, Title: title, Summary: summary})
+}
+
+// Fetch fetches the cached item with given `guid`.
+func (a *feedsItemsCacheAdapter) Fetch(guid string) *CachedItem {
+	cached, err := a.cache.Fetch(context.Background(), guid)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			log.Printf("failed to fetch cached item with guid '%s': %s", guid, err)
+		}
+		return nil
+	}
+	return cached
+}
+
+// MarkAsRead marks a cached item as read.
+func (a *feedsItemsCacheAdapter) MarkAsRead(guid string) error {
+	return a.cache.MarkAsRead(context.Background(), guid)
+}
+
+// List lists cached items.
+func (a *feedsItemsCacheAdapter) List(includeItemsMarkedAsRead bool) []CachedItem {
+	items, err := a.cache.List(context.Background(), includeItemsMarkedAsRead)
+	if err != nil {
+		log.Printf("failed to list cached items: %s", err)
+		return nil
+	}
+	return items
+}
+
+// DeleteOlderThan1Month deletes cached items which are older than 1 month.
+func (a *feedsItemsCacheAdapter) DeleteOlderThan1Month() error {
+	return a.cache.DeleteOlderThan1Month(context.Background())
+}
+
+// SaveMany saves given items to the cache.
+func (a *feedsItemsCacheAdapter) SaveMany(items []ItemToCache) BatchResults {
+	return a.cache.SaveMany(context.Background(), items)
+}
+
+// MarkManyAsRead marks cached items with given `guids` as read.
+func (a *feedsItemsCacheAdapter) MarkManyAsRead(guids []string) BatchResults {
+	return a.cache.MarkManyAsRead(context.Background(), guids)
+}
+
+// DeleteMany deletes cached items with given `guids`.
+func (a *feedsItemsCacheAdapter) DeleteMany(guids []string) BatchResults {
+	return a.cache.DeleteMany(context.Background(), guids)
+}
+
+// ForEach calls `fn` for each cached item.
+func (a *feedsItemsCacheAdapter) ForEach(fn func(item CachedItem) error) error {
+	return a.cache.ForEach(context.Background(), fn)
+}
+
+// Import imports given cached items with `mode`.
+func (a *feedsItemsCacheAdapter) Import(items []CachedItem, mode ImportMode) BatchResults {
+	return a.cache.Import(context.Background(), items, mode)
+}
+
+// SetVerbose sets the verbosity of cache.
+func (a *feedsItemsCacheAdapter) SetVerbose(v bool) {
+	a.cache.SetVerbose(v)
+}
+
+// Close closes the cache.
+func (a *feedsItemsCacheAdapter) Close() error {
+	return a.cache.Close()
+}
@@ -0,0 +1,77 @@
+package rf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OriginalContent is a struct for the original content of a cached item
+//
+// (fetched page text or file which was used for the summary)
+type OriginalContent struct {
+	gorm.Model
+
+	GUID        string `gorm:"uniqueIndex"`
+	ContentType string
+	Compressed  []byte // gzip-compressed content
+	Size        int    // size of the content before compression
+	FetchedAt   time.Time
+}
+
+// newOriginalContent compresses given content for storing.
+func newOriginalContent(guid, contentType string, content []byte, fetchedAt time.Time) (OriginalContent, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(content); err != nil {
+		return OriginalContent{}, fmt.Errorf("failed to compress content of '%s': %w", guid, err)
+	}
+	if err := zw.Close(); err != nil {
+		return OriginalContent{}, fmt.Errorf("failed to compress content of '%s': %w", guid, err)
+	}
+
+	return OriginalContent{
+		GUID:        guid,
+		ContentType: contentType,
+		Compressed:  buf.Bytes(),
+		Size:        len(content),
+		FetchedAt:   fetchedAt,
+	}, nil
+}
+
+// Content returns the decompressed content.
+func (c OriginalContent) Content() ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(c.Compressed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress content of '%s': %w", c.GUID, err)
+	}
+	defer func() { _ = zr.Close() }()
+
+	content, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress content of '%s': %w", c.GUID, err)
+	}
+	return content, nil
+}
+
+// FetchOriginalContent fetches the stored original content of the cached item with given `guid`.
+//
+// It returns an error wrapping `ErrNotFound` if no content was stored for the item.
+// (see `SetStoreOriginalContent`)
+func (c *Client) FetchOriginalContent(ctx context.Context, guid string) (*OriginalContent, error) {
+	return c.cache.FetchContent(ctx, guid)
+}
+
+// save the fetched content of given summary result
+func (c *Client) saveOriginalContent(ctx context.Context, guid string, summarized summaryResult) error {
+	content, err := newOriginalContent(guid, summarized.fetchedContentType, summarized.fetched, summarized.fetchedAt)
+	if err != nil {
+		return err
+	}
+	return c.cache.SaveContent(ctx, content)
+}
@@ -0,0 +1,130 @@
+package rf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// test compression of original contents
+func TestOriginalContent(t *testing.T) {
+	original := bytes.Repeat([]byte("some page text to be compressed. "), 100)
+
+	content, err := newOriginalContent("content-1", "text/html", original, time.Now())
+	if err != nil {
+		t.Fatalf("failed to create original content: %s", err)
+	}
+	if len(content.Compressed) >= len(original) {
+		t.Errorf("expected content to be compressed: %d >= %d", len(content.Compressed), len(original))
+	}
+	if content.Size != len(original) {
+		t.Errorf("expected size %d, got %d", len(original), content.Size)
+	}
+
+	decompressed, err := content.Content()
+	if err != nil {
+		t.Fatalf("failed to decompress content: %s", err)
+	}
+	if !bytes.Equal(decompressed, original) {
+		t.Error("expected decompressed content to be the same as the original one")
+	}
+}
+
+// test storing original contents in caches
+func TestCacheOriginalContent(t *testing.T) {
+	ctx := context.Background()
+
+	dbc, err := newDBCache(filepath.Join(t.TempDir(), "content.db"))
+	if err != nil {
+		t.Fatalf("failed to create dbCache: %s", err)
+	}
+
+	for name, cache := range map[string]FeedsItemsCacheV2{
+		"mem": newMemCache(),
+		"db":  dbc,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := cache.FetchContent(ctx, "content-1"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected ErrNotFound, got %v", err)
+			}
+
+			_ = cache.Save(ctx, ItemToCache{Item: testFeedItem("content-1", "Title"), Title: "Title", Summary: "Summary"})
+
+			for _, text := range []string{"first fetch", "second fetch"} {
+				content, _ := newOriginalContent("content-1", "text/plain", []byte(text), time.Now())
+				if err := cache.SaveContent(ctx, content); err != nil {
+					t.Fatalf("SaveContent failed: %s", err)
+				}
+			}
+
+			fetched, err := cache.FetchContent(ctx, "content-1")
+			if err != nil {
+				t.Fatalf("FetchContent failed: %s", err)
+			}
+			if decompressed, _ := fetched.Content(); string(decompressed) != "second fetch" {
+				t.Errorf("expected the latest content, got %q", decompressed)
+			}
+			if fetched.ContentType != "text/plain" {
+				t.Errorf("expected content type 'text/plain', got %q", fetched.ContentType)
+			}
+
+			// deleted along with its item
+			if err := cache.DeleteMany(ctx, []string{"content-1"}).Err(); err != nil {
+				t.Fatalf("DeleteMany failed: %s", err)
+			}
+			if _, err := cache.FetchContent(ctx, "content-1"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected content to be deleted, got %v", err)
+			}
+		})
+	}
+}
+
+// test that old items' contents are deleted from dbCache
+func TestDBCacheDeleteOldContents(t *testing.T) {
+	ctx := context.Background()
+
+	dbc, err := newDBCache(filepath.Join(t.TempDir(), "old_contents.db"))
+	if err != nil {
+		t.Fatalf("failed to create dbCache: %s", err)
+	}
+
+	for _, guid := range []string{"old", "new"} {
+		_ = dbc.Save(ctx, ItemToCache{Item: testFeedItem(guid, guid), Title: guid, Summary: guid})
+		content, _ := newOriginalContent(guid, "text/plain", []byte(guid), time.Now())
+		_ = dbc.SaveContent(ctx, content)
+	}
+	if err := dbc.db.Model(&CachedItem{}).Where("guid = ?", "old").Update("created_at", time.Now().Add(-35*24*time.Hour)).Error; err != nil {
+		t.Fatalf("failed to backdate item: %s", err)
+	}
+
+	if err := dbc.DeleteOlderThan1Month(ctx); err != nil {
+		t.Fatalf("DeleteOlderThan1Month failed: %s", err)
+	}
+	if _, err := dbc.FetchContent(ctx, "old"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected content of old item to be deleted, got %v", err)
+	}
+	if _, err := dbc.FetchContent(ctx, "new"); err != nil {
+		t.Errorf("expected content of new item to remain, got %v", err)
+	}
+}
+
+// test that contents and revisions of unknown items are not saved in memCache
+func TestMemCacheUnknownContents(t *testing.T) {
+	ctx := context.Background()
+
+	mc := newMemCache()
+
+	content, _ := newOriginalContent("unknown", "text/plain", []byte("content"), time.Now())
+	if err := mc.SaveContent(ctx, content); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for content of unknown item, got %v", err)
+	}
+	if err := mc.SaveRevision(ctx, SummaryRevision{GUID: "unknown", Summary: "summary"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for revision of unknown item, got %v", err)
+	}
+	if mc.bytes != 0 {
+		t.Errorf("expected no bytes for unknown items, got %d", mc.bytes)
+	}
+}
@@ -1,12 +1,13 @@
 package rf
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"time"
 
-	"github.com/mmcdole/gofeed"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -25,63 +26,80 @@ type dbCache struct {
 	verbose bool
 }
 
-// Exists checks for the existence of `id` in the cache.
-func (c *dbCache) Exists(guid string) (exists bool) {
+// Exists checks for the existence of `guid` in the cache.
+func (c *dbCache) Exists(ctx context.Context, guid string) (exists bool, err error) {
 	v(c.verbose, "dbCache - checking existence of cached item with guid: %s", guid)
 
-	err := c.db.Model(&CachedItem{}).Where("guid = ?", guid).Select("count(*) > 0").Find(&exists).Error
-	if err == nil {
-		return exists
+	err = c.db.WithContext(ctx).Model(&CachedItem{}).Where("guid = ?", guid).Select("count(*) > 0").Find(&exists).Error
+	if err != nil {
+		return false, fmt.Errorf("%w: failed to check existence of cached item with guid '%s': %w", ErrUnavailable, guid, err)
 	}
 
-	log.Printf("failed to check existence of cached item with guid '%s': %s", guid, err)
-
-	return false
+	return exists, nil
 }
 
 // Save saves given item to the cache.
-func (c *dbCache) Save(item gofeed.Item, title, summary string) error {
-	v(c.verbose, "dbCache - saving item to cache: %s (%s)", item.Title, title)
+func (c *dbCache) Save(ctx context.Context, item ItemToCache) error {
+	v(c.verbose, "dbCache - saving item to cache: %s (%s)", item.Item.Title, item.Title)
 
-	cached := newCachedItem(item, title, summary)
+	return upsertCachedItem(c.db.WithContext(ctx), item.cachedItem())
+}
 
-	err := c.db.Clauses(clause.OnConflict{
+// upsert given cached item with `tx`
+func upsertCachedItem(tx *gorm.DB, cached CachedItem) error {
+	err := tx.Clauses(clause.OnConflict{
 		Columns: []clause.Column{{Name: "guid"}},
-		DoUpdates: clause.AssignmentColumns([]string{
+		DoUpdates: append(clause.AssignmentColumns([]string{
 			"title",
+			"original_title",
 			"summary",
+			"update_date",
+			"image_url",
+			"categories",
+			"enclosures",
+		}), clause.Assignment{
+			// NOTE: feed urls are not overwritten with empty ones
+			Column: clause.Column{Name: "feed_url"},
+			Value:  gorm.Expr("CASE WHEN excluded.feed_url <> '' THEN excluded.feed_url ELSE feed_url END"),
 		}),
 	}).Create(&cached).Error
 	if err != nil {
-		return fmt.Errorf("failed to upsert cached item '%s': %w", item.GUID, err)
+		return fmt.Errorf("%w: failed to upsert cached item '%s': %w", ErrUnavailable, cached.GUID, err)
 	}
 
 	return nil
 }
 
 // Fetch fetches the cached item with given `guid`.
-func (c *dbCache) Fetch(guid string) *CachedItem {
+func (c *dbCache) Fetch(ctx context.Context, guid string) (*CachedItem, error) {
 	v(c.verbose, "dbCache - fetching cached item with guid: %s", guid)
 
 	var cached CachedItem
-	err := c.db.Where("guid = ?", guid).First(&cached).Error
+	err := c.db.WithContext(ctx).Where("guid = ?", guid).First(&cached).Error
 	if err != nil {
-		log.Printf("failed to fetch cached item with guid '%s': %s", guid, err)
-		return nil
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: '%s'", ErrNotFound, guid)
+		}
+		return nil, fmt.Errorf("%w: failed to fetch cached item with guid '%s': %w", ErrUnavailable, guid, err)
 	}
-	return &cached
+	return &cached, nil
 }
 
 // MarkAsRead marks a cached item as read.
-func (c *dbCache) MarkAsRead(guid string) error {
+func (c *dbCache) MarkAsRead(ctx context.Context, guid string) error {
 	v(c.verbose, "dbCache - marking cached item with guid: %s as read", guid)
 
-	result := c.db.Model(&CachedItem{}).Where("guid = ?", guid).Update("marked_as_read", true)
+	return markCachedItemAsRead(c.db.WithContext(ctx), guid)
+}
+
+// mark cached item with given `guid` as read with `tx`
+func markCachedItemAsRead(tx *gorm.DB, guid string) error {
+	result := tx.Model(&CachedItem{}).Where("guid = ?", guid).Update("marked_as_read", true)
 	if result.Error != nil {
-		return fmt.Errorf("failed to mark cached item '%s' as read: %w", guid, result.Error)
+		return fmt.Errorf("%w: failed to mark cached item '%s' as read: %w", ErrUnavailable, guid, result.Error)
 	}
 	if result.RowsAffected != 1 {
-		return fmt.Errorf("unexpected rows affected when marking '%s' as read: %d", guid, result.RowsAffected)
+		return fmt.Errorf("%w: unexpected rows affected when marking '%s' as read: %d", ErrNotFound, guid, result.RowsAffected)
 	}
 
 	return nil
@@ -90,41 +108,86 @@ func (c *dbCache) MarkAsRead(guid string) error {
 // List lists cached items.
 //
 // NOTE: when including items marked as read, the count will be limited to `listLimit`.
-func (c *dbCache) List(includeItemsMarkedAsRead bool) (items []CachedItem) {
-	v(c.verbose, "dbCache - listing cached items with includeItemsMarkedAsRead = %v", includeItemsMarkedAsRead)
+func (c *dbCache) List(ctx context.Context, includeItemsMarkedAsRead bool) (items []CachedItem, err error) {
+	return c.ListWithFilter(ctx, ListFilter{IncludeItemsMarkedAsRead: includeItemsMarkedAsRead})
+}
+
+// ListWithFilter lists cached items which match given `filter`.
+//
+// NOTE: when including items marked as read, the count will be limited to `listLimit`.
+func (c *dbCache) ListWithFilter(ctx context.Context, filter ListFilter) (items []CachedItem, err error) {
+	v(c.verbose, "dbCache - listing cached items with filter: %+v", filter)
 
-	tx := c.db.Model(&CachedItem{})
-	if !includeItemsMarkedAsRead {
+	tx := c.db.WithContext(ctx).Model(&CachedItem{})
+	if len(filter.FeedURL) > 0 {
+		tx = tx.Where("feed_url = ?", filter.FeedURL)
+	}
+	if len(filter.Category) > 0 {
+		tx = tx.Where("EXISTS (SELECT 1 FROM json_each(cached_items.categories) WHERE json_each.value = ?)", filter.Category)
+	}
+	if !filter.Since.IsZero() {
+		tx = tx.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		tx = tx.Where("created_at < ?", filter.Until)
+	}
+	if !filter.IncludeItemsMarkedAsRead {
 		tx = tx.Where("marked_as_read = ?", false).Order("created_at DESC")
 	} else {
 		tx = tx.Order("created_at DESC").Limit(listLimit)
 	}
 
-	err := tx.Find(&items).Error
-	if err != nil {
-		log.Printf("failed to list cached items: %s", err)
-		return nil
+	if err := tx.Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("%w: failed to list cached items: %w", ErrUnavailable, err)
 	}
 
-	return items
+	return items, nil
+}
+
+// ListCategories lists distinct categories of cached items, sorted.
+func (c *dbCache) ListCategories(ctx context.Context) (categories []string, err error) {
+	v(c.verbose, "dbCache - listing categories of cached items")
+
+	if err := c.db.WithContext(ctx).Model(&CachedItem{}).
+		Joins(", json_each(cached_items.categories)").
+		Where("json_each.type = ?", "text").
+		Distinct().
+		Order("json_each.value").
+		Pluck("json_each.value", &categories).Error; err != nil {
+		return nil, fmt.Errorf("%w: failed to list categories of cached items: %w", ErrUnavailable, err)
+	}
+	return categories, nil
 }
 
 // DeleteOlderThan1Month physically deletes cached items which are older than
 // 1 month, then reclaims freed pages via incremental_vacuum.
-func (c *dbCache) DeleteOlderThan1Month() error {
+func (c *dbCache) DeleteOlderThan1Month(ctx context.Context) error {
 	v(c.verbose, "dbCache - deleting cached items older than 1 month")
 
+	db := c.db.WithContext(ctx)
+
 	cutoff := time.Now().Add(-30 * 24 * time.Hour)
-	result := c.db.Unscoped().Where("created_at < ?", cutoff).Delete(&CachedItem{})
+	result := db.Unscoped().Where("created_at < ?", cutoff).Delete(&CachedItem{})
 	if result.Error != nil {
-		return fmt.Errorf("failed to delete cached items older than 1 month: %w", result.Error)
+		return fmt.Errorf("%w: failed to delete cached items older than 1 month: %w", ErrUnavailable, result.Error)
+	}
+
+	// delete original contents, revisions, and deliveries of deleted items
+	if err := db.Unscoped().Where("guid NOT IN (?)", db.Unscoped().Model(&CachedItem{}).Select("guid")).Delete(&OriginalContent{}).Error; err != nil {
+		return fmt.Errorf("%w: failed to delete original contents of deleted items: %w", ErrUnavailable, err)
+	}
+	if err := db.Unscoped().Where("guid NOT IN (?)", db.Unscoped().Model(&CachedItem{}).Select("guid")).Delete(&SummaryRevision{}).Error; err != nil {
+		return fmt.Errorf("%w: failed to delete revisions of deleted items: %w", ErrUnavailable, err)
+	}
+	if err := db.Unscoped().Where("guid NOT IN (?)", db.Unscoped().Model(&CachedItem{}).Select("guid")).Delete(&Delivery{}).Error; err != nil {
+		return fmt.Errorf("%w: failed to delete deliveries of deleted items: %w", ErrUnavailable, err)
 	}
 
 	if result.RowsAffected > 0 {
 		v(c.verbose, "dbCache - deleted %d cached items", result.RowsAffected)
 
 		// reclaim freed pages (non-fatal on failure; retried on next delete)
-		if err := c.db.Exec("PRAGMA incremental_vacuum").Error; err != nil {
+		if err := db.Exec("PRAGMA incremental_vacuum").Error; err != nil {
 			v(c.verbose, "dbCache - incremental_vacuum failed: %s", err)
 		}
 	}
@@ -132,11 +195,406 @@ func (c *dbCache) DeleteOlderThan1Month() error {
 	return nil
 }
 
+// SaveMany saves given items to the cache in a single transaction.
+func (c *dbCache) SaveMany(ctx context.Context, items []ItemToCache) BatchResults {
+	v(c.verbose, "dbCache - saving %d items to cache", len(items))
+
+	guids := make([]string, 0, len(items))
+	for _, item := range items {
+		guids = append(guids, item.Item.GUID)
+	}
+
+	return c.batch(ctx, guids, func(tx *gorm.DB, i int) error {
+		return upsertCachedItem(tx, items[i].cachedItem())
+	})
+}
+
+// MarkManyAsRead marks cached items with given `guids` as read in a single transaction.
+func (c *dbCache) MarkManyAsRead(ctx context.Context, guids []string) BatchResults {
+	v(c.verbose, "dbCache - marking %d cached items as read", len(guids))
+
+	return c.batch(ctx, guids, func(tx *gorm.DB, i int) error {
+		return markCachedItemAsRead(tx, guids[i])
+	})
+}
+
+// DeleteMany physically deletes cached items with given `guids` in a single transaction.
+func (c *dbCache) DeleteMany(ctx context.Context, guids []string) BatchResults {
+	v(c.verbose, "dbCache - deleting %d cached items", len(guids))
+
+	return c.batch(ctx, guids, func(tx *gorm.DB, i int) error {
+		result := tx.Unscoped().Where("guid = ?", guids[i]).Delete(&CachedItem{})
+		if result.Error != nil {
+			return fmt.Errorf("%w: failed to delete cached item '%s': %w", ErrUnavailable, guids[i], result.Error)
+		}
+		if result.RowsAffected != 1 {
+			return fmt.Errorf("%w: unexpected rows affected when deleting '%s': %d", ErrNotFound, guids[i], result.RowsAffected)
+		}
+		if err := tx.Unscoped().Where("guid = ?", guids[i]).Delete(&OriginalContent{}).Error; err != nil {
+			return fmt.Errorf("%w: failed to delete original content of '%s': %w", ErrUnavailable, guids[i], err)
+		}
+		if err := tx.Unscoped().Where("guid = ?", guids[i]).Delete(&SummaryRevision{}).Error; err != nil {
+			return fmt.Errorf("%w: failed to delete revisions of '%s': %w", ErrUnavailable, guids[i], err)
+		}
+		if err := tx.Unscoped().Where("guid = ?", guids[i]).Delete(&Delivery{}).Error; err != nil {
+			return fmt.Errorf("%w: failed to delete deliveries of '%s': %w", ErrUnavailable, guids[i], err)
+		}
+		return nil
+	})
+}
+
+// ForEach calls `fn` for each cached item in the order of creation,
+// stopping at the first error.
+func (c *dbCache) ForEach(ctx context.Context, fn func(item CachedItem) error) error {
+	v(c.verbose, "dbCache - iterating cached items")
+
+	var batch []CachedItem
+	var fnErr error
+	err := c.db.WithContext(ctx).Model(&CachedItem{}).Order("created_at ASC, id ASC").FindInBatches(&batch, listLimit, func(_ *gorm.DB, _ int) error {
+		for _, item := range batch {
+			if fnErr = fn(item); fnErr != nil {
+				return fnErr
+			}
+		}
+		return nil
+	}).Error
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		return fmt.Errorf("%w: failed to iterate cached items: %w", ErrUnavailable, err)
+	}
+
+	return nil
+}
+
+// Import imports given cached items with `mode` in a single transaction.
+func (c *dbCache) Import(ctx context.Context, items []CachedItem, mode ImportMode) BatchResults {
+	v(c.verbose, "dbCache - importing %d cached items with mode: %s", len(items), mode)
+
+	guids := make([]string, 0, len(items))
+	for _, item := range items {
+		guids = append(guids, item.GUID)
+	}
+
+	return c.batch(ctx, guids, func(tx *gorm.DB, i int) error {
+		item := items[i]
+		item.ID = 0
+
+		var existing CachedItem
+		err := tx.Where("guid = ?", item.GUID).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if err := tx.Create(&item).Error; err != nil {
+				return fmt.Errorf("%w: failed to create cached item '%s': %w", ErrUnavailable, item.GUID, err)
+			}
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("%w: failed to fetch cached item '%s': %w", ErrUnavailable, item.GUID, err)
+		}
+
+		switch mode {
+		case ImportModeSkipExisting:
+			return nil
+		case ImportModeMerge:
+			item = mergeCachedItems(existing, item)
+		}
+		item.ID = existing.ID
+
+		if err := tx.Save(&item).Error; err != nil {
+			return fmt.Errorf("%w: failed to update cached item '%s': %w", ErrUnavailable, item.GUID, err)
+		}
+		return nil
+	})
+}
+
+// SaveContent saves the original content of a cached item.
+func (c *dbCache) SaveContent(ctx context.Context, content OriginalContent) error {
+	v(c.verbose, "dbCache - saving original content of cached item with guid: %s", content.GUID)
+
+	err := c.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "guid"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"content_type",
+			"compressed",
+			"size",
+			"fetched_at",
+			"updated_at",
+		}),
+	}).Create(&content).Error
+	if err != nil {
+		return fmt.Errorf("%w: failed to upsert original content of '%s': %w", ErrUnavailable, content.GUID, err)
+	}
+
+	return nil
+}
+
+// FetchContent fetches the original content of the cached item with given `guid`.
+func (c *dbCache) FetchContent(ctx context.Context, guid string) (*OriginalContent, error) {
+	v(c.verbose, "dbCache - fetching original content of cached item with guid: %s", guid)
+
+	var content OriginalContent
+	err := c.db.WithContext(ctx).Where("guid = ?", guid).First(&content).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: original content of '%s'", ErrNotFound, guid)
+		}
+		return nil, fmt.Errorf("%w: failed to fetch original content of '%s': %w", ErrUnavailable, guid, err)
+	}
+	return &content, nil
+}
+
+// UpdateSummary updates the title and summary of the cached item with given `guid`.
+func (c *dbCache) UpdateSummary(ctx context.Context, guid, title, summary string) error {
+	v(c.verbose, "dbCache - updating summary of cached item with guid: %s", guid)
+
+	result := c.db.WithContext(ctx).Model(&CachedItem{}).Where("guid = ?", guid).Updates(map[string]any{
+		"title":   title,
+		"summary": summary,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("%w: failed to update summary of '%s': %w", ErrUnavailable, guid, result.Error)
+	}
+	if result.RowsAffected != 1 {
+		return fmt.Errorf("%w: unexpected rows affected when updating summary of '%s': %d", ErrNotFound, guid, result.RowsAffected)
+	}
+
+	return nil
+}
+
+// SaveRevision saves a revision of a cached item's summary.
+func (c *dbCache) SaveRevision(ctx context.Context, revision SummaryRevision) error {
+	v(c.verbose, "dbCache - saving revision of cached item with guid: %s", revision.GUID)
+
+	revision.ID = 0
+	if err := c.db.WithContext(ctx).Create(&revision).Error; err != nil {
+		return fmt.Errorf("%w: failed to save revision of '%s': %w", ErrUnavailable, revision.GUID, err)
+	}
+
+	return nil
+}
+
+// ListRevisions lists the revisions of the cached item with given `guid`, newest first.
+func (c *dbCache) ListRevisions(ctx context.Context, guid string) (revisions []SummaryRevision, err error) {
+	v(c.verbose, "dbCache - listing revisions of cached item with guid: %s", guid)
+
+	if err := c.db.WithContext(ctx).Where("guid = ?", guid).Order("id DESC").Find(&revisions).Error; err != nil {
+		return nil, fmt.Errorf("%w: failed to list revisions of '%s': %w", ErrUnavailable, guid, err)
+	}
+
+	return revisions, nil
+}
+
+// SaveFeed saves (or updates) given feed.
+func (c *dbCache) SaveFeed(ctx context.Context, feed Feed) error {
+	v(c.verbose, "dbCache - saving feed: %s", feed.URL)
+
+	feed.ID = 0
+	err := c.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "url"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"title",
+			"site_link",
+			"icon",
+			"language",
+			"last_fetched_at",
+			"last_error",
+			"self_link",
+			"moved_to",
+			"move_confirmations",
+			"last_success_at",
+			"last_http_status",
+			"consecutive_failures",
+			"failing_since",
+			"fetch_count",
+			"average_latency",
+			"next_retry_at",
+			"disabled",
+			"hub_url",
+			"topic_url",
+			"web_sub_secret",
+			"web_sub_requested_at",
+			"web_sub_lease_until",
+			"updated_at",
+		}),
+	}).Create(&feed).Error
+	if err != nil {
+		return fmt.Errorf("%w: failed to upsert feed '%s': %w", ErrUnavailable, feed.URL, err)
+	}
+
+	return nil
+}
+
+// FetchFeed fetches the feed with given `url`.
+func (c *dbCache) FetchFeed(ctx context.Context, url string) (*Feed, error) {
+	v(c.verbose, "dbCache - fetching feed: %s", url)
+
+	var feed Feed
+	err := c.db.WithContext(ctx).Where("url = ?", url).First(&feed).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: feed '%s'", ErrNotFound, url)
+		}
+		return nil, fmt.Errorf("%w: failed to fetch feed '%s': %w", ErrUnavailable, url, err)
+	}
+	return &feed, nil
+}
+
+// ListFeeds lists all feeds in the order of creation.
+func (c *dbCache) ListFeeds(ctx context.Context) (feeds []Feed, err error) {
+	v(c.verbose, "dbCache - listing feeds")
+
+	if err := c.db.WithContext(ctx).Order("id ASC").Find(&feeds).Error; err != nil {
+		return nil, fmt.Errorf("%w: failed to list feeds: %w", ErrUnavailable, err)
+	}
+
+	return feeds, nil
+}
+
+// SaveDigest saves given digest.
+func (c *dbCache) SaveDigest(ctx context.Context, digest *Digest) error {
+	v(c.verbose, "dbCache - saving digest: %s", digest.Title)
+
+	digest.ID = 0
+	if err := c.db.WithContext(ctx).Create(digest).Error; err != nil {
+		return fmt.Errorf("%w: failed to save digest '%s': %w", ErrUnavailable, digest.Title, err)
+	}
+
+	return nil
+}
+
+// FetchDigest fetches the digest with given `id`.
+func (c *dbCache) FetchDigest(ctx context.Context, id uint) (*Digest, error) {
+	v(c.verbose, "dbCache - fetching digest: %d", id)
+
+	var digest Digest
+	err := c.db.WithContext(ctx).First(&digest, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: digest %d", ErrNotFound, id)
+		}
+		return nil, fmt.Errorf("%w: failed to fetch digest %d: %w", ErrUnavailable, id, err)
+	}
+	return &digest, nil
+}
+
+// ListDigests lists all digests, newest first.
+func (c *dbCache) ListDigests(ctx context.Context) (digests []Digest, err error) {
+	v(c.verbose, "dbCache - listing digests")
+
+	if err := c.db.WithContext(ctx).Order("id DESC").Find(&digests).Error; err != nil {
+		return nil, fmt.Errorf("%w: failed to list digests: %w", ErrUnavailable, err)
+	}
+
+	return digests, nil
+}
+
+// SaveDelivery saves (or updates) given delivery.
+func (c *dbCache) SaveDelivery(ctx context.Context, delivery Delivery) error {
+	v(c.verbose, "dbCache - saving delivery of cached item with guid: %s to sink: %s", delivery.GUID, delivery.Sink)
+
+	delivery.ID = 0
+	err := c.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "sink"}, {Name: "guid"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"delivered_at",
+			"attempts",
+			"last_error",
+			"delivered_parts",
+			"updated_at",
+		}),
+	}).Create(&delivery).Error
+	if err != nil {
+		return fmt.Errorf("%w: failed to save delivery of '%s' to '%s': %w", ErrUnavailable, delivery.GUID, delivery.Sink, err)
+	}
+
+	return nil
+}
+
+// ListDeliveries lists the deliveries to the sink with given name.
+func (c *dbCache) ListDeliveries(ctx context.Context, sink string) (deliveries []Delivery, err error) {
+	v(c.verbose, "dbCache - listing deliveries to sink: %s", sink)
+
+	if err := c.db.WithContext(ctx).Where("sink = ?", sink).Order("id ASC").Find(&deliveries).Error; err != nil {
+		return nil, fmt.Errorf("%w: failed to list deliveries to '%s': %w", ErrUnavailable, sink, err)
+	}
+
+	return deliveries, nil
+}
+
+// MoveFeed changes the url of the feed at `oldURL` to `newURL`, along with its cached items.
+func (c *dbCache) MoveFeed(ctx context.Context, oldURL, newURL string) error {
+	v(c.verbose, "dbCache - moving feed: %s => %s", oldURL, newURL)
+
+	return c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// (replace the feed which already has the new url, if any)
+		if err := tx.Unscoped().Where("url = ?", newURL).Delete(&Feed{}).Error; err != nil {
+			return fmt.Errorf("%w: failed to delete feed '%s': %w", ErrUnavailable, newURL, err)
+		}
+
+		result := tx.Model(&Feed{}).Where("url = ?", oldURL).Updates(map[string]any{
+			"url":                newURL,
+			"moved_to":           "",
+			"move_confirmations": 0,
+		})
+		if result.Error != nil {
+			return fmt.Errorf("%w: failed to move feed '%s': %w", ErrUnavailable, oldURL, result.Error)
+		}
+		if result.RowsAffected != 1 {
+			return fmt.Errorf("%w: unexpected rows affected when moving feed '%s': %d", ErrNotFound, oldURL, result.RowsAffected)
+		}
+
+		if err := tx.Model(&CachedItem{}).Where("feed_url = ?", oldURL).Update("feed_url", newURL).Error; err != nil {
+			return fmt.Errorf("%w: failed to move cached items of feed '%s': %w", ErrUnavailable, oldURL, err)
+		}
+		return nil
+	})
+}
+
+// batch runs `fn` for each of `guids` in a single transaction.
+//
+// Each item runs in its own savepoint, so a failed item is rolled back
+// without affecting the others. If the transaction itself fails,
+// every item is reported with that error.
+func (c *dbCache) batch(ctx context.Context, guids []string, fn func(tx *gorm.DB, i int) error) BatchResults {
+	results := make(BatchResults, len(guids))
+
+	err := c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, guid := range guids {
+			results[i] = BatchResult{
+				GUID: guid,
+				Err: tx.Transaction(func(tx *gorm.DB) error {
+					return fn(tx, i)
+				}),
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		for i, guid := range guids {
+			results[i] = BatchResult{
+				GUID: guid,
+				Err:  fmt.Errorf("%w: transaction failed: %w", ErrUnavailable, err),
+			}
+		}
+	}
+
+	return results
+}
+
 // SetVerbose sets the verbosity of cache.
 func (c *dbCache) SetVerbose(v bool) {
 	c.verbose = v
 }
 
+// Close closes the underlying database.
+func (c *dbCache) Close() error {
+	db, err := c.db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get underlying db: %w", err)
+	}
+	return db.Close()
+}
+
 // return a new db cache
 func newDBCache(filepath string) (cache *dbCache, err error) {
 	if db, err := gorm.Open(sqlite.Open(filepath), &gorm.Config{
@@ -153,14 +611,14 @@ func newDBCache(filepath string) (cache *dbCache, err error) {
 	}); err == nil {
 		// enable incremental auto_vacuum before any table is created,
 		// so that a newly created DB file reclaims freed pages on
-		// `PRAGMA incremental_vacuum`. (existing files must be converted
-		// once manually with `PRAGMA auto_vacuum=INCREMENTAL; VACUUM;`)
+		// `PRAGMA incremental_vacuum`. (existing files are converted
+		// once by a schema migration)
 		if err := db.Exec("PRAGMA auto_vacuum = INCREMENTAL").Error; err != nil {
 			return nil, fmt.Errorf("failed to set auto_vacuum: %w", err)
 		}
 
 		// migrate the schema
-		if err := db.AutoMigrate(&CachedItem{}); err != nil {
+		if err := migrateDB(db, filepath); err != nil {
 			return nil, fmt.Errorf("failed to migrate db: %w", err)
 		}
 
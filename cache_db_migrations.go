@@ -0,0 +1,190 @@
+package rf
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+////////////////
+//
+// (schema migrations of DB cache)
+//
+
+// ErrDBSchemaTooNew is returned when the DB file was written by a newer version of this library.
+var ErrDBSchemaTooNew = errors.New("db schema is newer than supported")
+
+// schemaVersion is a struct for an applied schema migration
+type schemaVersion struct {
+	Version   int `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+// TableName returns the table name of schema versions.
+func (schemaVersion) TableName() string {
+	return "schema_versions"
+}
+
+// dbMigration is a struct for a single schema migration step
+type dbMigration struct {
+	version int
+	name    string
+
+	destructive bool // if true, the DB file will be backed up before this step
+	noTx        bool // if true, this step runs outside of a transaction (eg. `VACUUM`)
+
+	migrate func(db *gorm.DB) error
+}
+
+// models of the tables of DB cache
+//
+// Additive changes of these models (new tables, columns, and indexes) are applied by `AutoMigrate`
+// on every open before the versioned migrations, so they don't need migration steps of their own.
+//
+// NOTE: columns are never dropped or altered by `AutoMigrate`, so changes other than
+// additive ones (eg. renaming, converting, or dropping columns) need versioned migrations.
+var dbModels = []any{
+	&CachedItem{},
+	&OriginalContent{},
+	&SummaryRevision{},
+	&Feed{},
+	&Digest{},
+	&Delivery{},
+}
+
+// ordered schema migrations which are not additive (see `dbModels`)
+//
+// NOTE: append new steps to the end with increasing versions, never modify applied ones.
+var dbMigrations = []dbMigration{
+	{
+		version:     1,
+		name:        "convert auto_vacuum to incremental",
+		destructive: true,
+		noTx:        true,
+		migrate:     convertToIncrementalAutoVacuum,
+	},
+}
+
+// latest schema version supported by this library
+func latestSchemaVersion() int {
+	return dbMigrations[len(dbMigrations)-1].version
+}
+
+// migrateDB applies additive changes of models and pending schema migrations to `db` (of file at `filepath`) in order.
+//
+// Applied migrations and backups of existing db files are logged.
+func migrateDB(db *gorm.DB, filepath string) error {
+	fresh := !db.Migrator().HasTable(&CachedItem{}) && !db.Migrator().HasTable(&schemaVersion{})
+
+	if err := db.AutoMigrate(&schemaVersion{}); err != nil {
+		return fmt.Errorf("failed to create schema versions table: %w", err)
+	}
+
+	var current int
+	if err := db.Model(&schemaVersion{}).Select("coalesce(max(version), 0)").Scan(&current).Error; err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	latest := latestSchemaVersion()
+	if current > latest {
+		return fmt.Errorf("%w: db file '%s' has schema version %d, but the latest supported one is %d", ErrDBSchemaTooNew, filepath, current, latest)
+	}
+
+	// create or alter tables
+	if err := db.AutoMigrate(dbModels...); err != nil {
+		return fmt.Errorf("failed to migrate tables: %w", err)
+	}
+
+	for _, m := range dbMigrations {
+		if m.version <= current {
+			continue
+		}
+
+		if !fresh {
+			log.Printf("applying schema migration %d of db '%s': %s", m.version, filepath, m.name)
+		}
+
+		// backup before destructive steps (not needed for a fresh db)
+		if m.destructive && !fresh {
+			if backup, err := backupDB(db, filepath, current); err != nil {
+				return fmt.Errorf("failed to backup db before migration %d (%s): %w", m.version, m.name, err)
+			} else if len(backup) > 0 {
+				log.Printf("backed up db '%s' to '%s'", filepath, backup)
+			}
+		}
+
+		record := func(tx *gorm.DB) error {
+			return tx.Create(&schemaVersion{
+				Version:   m.version,
+				Name:      m.name,
+				AppliedAt: time.Now(),
+			}).Error
+		}
+
+		var err error
+		if m.noTx {
+			if err = m.migrate(db); err == nil {
+				err = record(db)
+			}
+		} else {
+			err = db.Transaction(func(tx *gorm.DB) error {
+				if err := m.migrate(tx); err != nil {
+					return err
+				}
+				return record(tx)
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to apply migration %d (%s): %w", m.version, m.name, err)
+		}
+
+		current = m.version
+	}
+
+	return nil
+}
+
+// backup the db file at `filepath` with `VACUUM INTO`, and return the path of the backup.
+//
+// NOTE: in-memory dbs are not backed up. (returns an empty path)
+func backupDB(db *gorm.DB, filepath string, version int) (backup string, err error) {
+	if filepath == ":memory:" || strings.HasPrefix(filepath, "file:") {
+		return "", nil
+	}
+
+	backup = fmt.Sprintf("%s.v%d-%s.bak", filepath, version, time.Now().Format("20060102150405"))
+	if err := db.Exec("VACUUM INTO ?", backup).Error; err != nil {
+		return "", err
+	}
+
+	return backup, nil
+}
+
+// convert the db to incremental auto_vacuum mode, if it is not yet.
+//
+// (existing files need a full `VACUUM` for changing their auto_vacuum mode)
+func convertToIncrementalAutoVacuum(db *gorm.DB) error {
+	// NOTE: pragmas are per connection, so run them on a single one
+	return db.Connection(func(conn *gorm.DB) error {
+		var mode int
+		if err := conn.Raw("PRAGMA auto_vacuum").Scan(&mode).Error; err != nil {
+			return fmt.Errorf("failed to read auto_vacuum: %w", err)
+		}
+		if mode == 2 { // already INCREMENTAL
+			return nil
+		}
+
+		if err := conn.Exec("PRAGMA auto_vacuum = INCREMENTAL").Error; err != nil {
+			return fmt.Errorf("failed to set auto_vacuum: %w", err)
+		}
+		if err := conn.Exec("VACUUM").Error; err != nil {
+			return fmt.Errorf("failed to vacuum: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,116 @@
+package rf
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// test that a fresh db has all migrations applied
+func TestMigrateDBFresh(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "fresh.db")
+
+	cache, err := newDBCache(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create dbCache: %s", err)
+	}
+
+	var versions []schemaVersion
+	if err := cache.db.Order("version").Find(&versions).Error; err != nil {
+		t.Fatalf("failed to read schema versions: %s", err)
+	}
+	if len(versions) != len(dbMigrations) {
+		t.Fatalf("expected %d applied migrations, got %d", len(dbMigrations), len(versions))
+	}
+	if versions[len(versions)-1].Version != latestSchemaVersion() {
+		t.Errorf("expected latest version %d, got %d", latestSchemaVersion(), versions[len(versions)-1].Version)
+	}
+
+	// no backup for a fresh db
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.bak"))
+	if len(matches) != 0 {
+		t.Errorf("expected no backups, got %v", matches)
+	}
+
+	// reopening applies nothing new
+	if err := migrateDB(cache.db, dbPath); err != nil {
+		t.Errorf("re-migration failed: %s", err)
+	}
+}
+
+// test migrating a legacy db file (created without auto_vacuum and schema versions)
+func TestMigrateDBLegacy(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "legacy.db")
+
+	legacy, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open legacy db: %s", err)
+	}
+	if err := legacy.AutoMigrate(&CachedItem{}); err != nil {
+		t.Fatalf("failed to create legacy table: %s", err)
+	}
+	if err := legacy.Create(&CachedItem{GUID: "legacy-1", Title: "Legacy"}).Error; err != nil {
+		t.Fatalf("failed to create legacy item: %s", err)
+	}
+	if db, err := legacy.DB(); err == nil {
+		_ = db.Close()
+	}
+
+	cache, err := newDBCache(dbPath)
+	if err != nil {
+		t.Fatalf("failed to migrate legacy db: %s", err)
+	}
+
+	var mode int
+	if err := cache.db.Raw("PRAGMA auto_vacuum").Scan(&mode).Error; err != nil {
+		t.Fatalf("failed to read auto_vacuum pragma: %s", err)
+	}
+	if mode != 2 {
+		t.Errorf("expected auto_vacuum to be converted to INCREMENTAL, got %d", mode)
+	}
+	if exists, err := cache.Exists(context.Background(), "legacy-1"); err != nil || !exists {
+		t.Error("expected legacy item to be kept")
+	}
+	for _, model := range dbModels {
+		if !cache.db.Migrator().HasTable(model) {
+			t.Errorf("expected table of %T to be created", model)
+		}
+	}
+
+	entries, _ := os.ReadDir(dir)
+	backups := 0
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".bak") {
+			backups++
+		}
+	}
+	if backups != 1 {
+		t.Errorf("expected 1 backup before destructive migration, got %d", backups)
+	}
+}
+
+// test that a db written by a newer version fails clearly
+func TestMigrateDBTooNew(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "newer.db")
+
+	cache, err := newDBCache(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create dbCache: %s", err)
+	}
+	if err := cache.db.Create(&schemaVersion{Version: latestSchemaVersion() + 1, Name: "from the future"}).Error; err != nil {
+		t.Fatalf("failed to insert schema version: %s", err)
+	}
+	_ = cache.Close()
+
+	if _, err := newDBCache(dbPath); !errors.Is(err, ErrDBSchemaTooNew) {
+		t.Errorf("expected ErrDBSchemaTooNew, got %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+package rf
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Delivery is a struct for the delivery of a cached item to a sink
+type Delivery struct {
+	gorm.Model
+
+	Sink string `gorm:"uniqueIndex:idx_deliveries_sink_guid"` // name of the sink
+	GUID string `gorm:"uniqueIndex:idx_deliveries_sink_guid"`
+
+	DeliveredAt *time.Time // nil if not delivered yet
+	Attempts    int
+	LastError   string // error of the last attempt (empty if it succeeded)
+
+	DeliveredParts int // number of parts (eg. chat messages) delivered before the last failure, for resuming with `PartialSink`
+}
+
+// Delivered returns whether the item was delivered successfully.
+func (d Delivery) Delivered() bool {
+	return d.DeliveredAt != nil
+}
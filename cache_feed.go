@@ -0,0 +1,257 @@
+package rf
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// key of `gofeed.Feed.Custom` for the url which the feed was fetched from
+const customKeyFeedURL = "rf:feed_url"
+
+// Feed is a struct for a feed which cached items came from
+type Feed struct {
+	gorm.Model
+
+	URL      string `gorm:"uniqueIndex"` // url which the feed is fetched from
+	Title    string
+	SiteLink string // url of the feed's web site
+	Icon     string // url of the feed's icon (or image)
+	Language string
+
+	LastFetchedAt time.Time
+	LastError     string // error of the last fetch (empty if it succeeded)
+
+	// moves of the feed
+	SelfLink          string // url in `<atom:link rel="self">` of the feed
+	MovedTo           string // url which the feed seems to be moved to (not confirmed yet)
+	MoveConfirmations int
+
+	// health of the feed
+	LastSuccessAt       time.Time
+	LastHTTPStatus      int // 0 if there was no response
+	ConsecutiveFailures int
+	FailingSince        time.Time // time of the first one of consecutive failures
+	FetchCount          int
+	AverageLatency      time.Duration
+	NextRetryAt         time.Time // not fetched again until this time (zero if not backing off)
+	Disabled            bool      // disabled for failing too long (see `SetDeadFeedDays`)
+
+	// websub of the feed
+	HubURL            string    // url of the feed's websub hub (empty if not advertised)
+	TopicURL          string    // topic url of the feed for its hub
+	WebSubSecret      string    // secret for the signatures of pushed contents
+	WebSubRequestedAt time.Time // time of the pending subscription request (zero if not pending)
+	WebSubLeaseUntil  time.Time // subscribed until this time (zero if not subscribed)
+}
+
+// fill the metadata of feed with given fetched one
+func (f *Feed) fill(fetched *gofeed.Feed) {
+	f.Title = fetched.Title
+	f.SiteLink = fetched.Link
+	f.Language = fetched.Language
+	if fetched.Image != nil {
+		f.Icon = fetched.Image.URL
+	}
+}
+
+// feedURLOf returns the url which given feed was fetched from with `FetchFeeds`.
+//
+// (empty if it was not fetched with `FetchFeeds`)
+func feedURLOf(feed gofeed.Feed) string {
+	return feed.Custom[customKeyFeedURL]
+}
+
+// ListFeeds lists the feeds which were fetched.
+func (c *Client) ListFeeds(ctx context.Context) ([]Feed, error) {
+	return c.cache.ListFeeds(ctx)
+}
+
+// feedFetchResult is a struct for the result of fetching a feed
+type feedFetchResult struct {
+	fetched *gofeed.Feed
+	status  int    // http status code (0 if there was no response)
+	movedTo string // final url of permanent redirects (empty if not redirected permanently)
+	hub     string // url of the websub hub (empty if not advertised)
+	topic   string // topic url for the websub hub
+	latency time.Duration
+	err     error
+}
+
+// record the result of fetching the feed at `url`
+//
+// It returns the url of the feed, which is changed if its move was confirmed.
+//
+// NOTE: failures are only logged, for not failing the fetch itself.
+// Results of canceled fetches are not recorded, as they are not the feed's failures.
+func (c *Client) recordFeed(ctx context.Context, url string, result feedFetchResult) string {
+	if ctx.Err() != nil {
+		return url
+	}
+
+	feed := Feed{URL: url}
+	if existing, err := c.cache.FetchFeed(ctx, url); err == nil {
+		feed = *existing
+	} else if !errors.Is(err, ErrNotFound) {
+		log.Printf("failed to fetch feed '%s' from cache: %s", url, err)
+		return url
+	}
+
+	var movedTo string
+
+	now := time.Now()
+	feed.LastFetchedAt = now
+	feed.LastHTTPStatus = result.status
+	feed.FetchCount++
+	feed.AverageLatency += (result.latency - feed.AverageLatency) / time.Duration(feed.FetchCount)
+
+	if result.err != nil {
+		feed.LastError = redactText(result.err.Error(), c.googleAIAPIKeys)
+		if feed.ConsecutiveFailures++; feed.ConsecutiveFailures == 1 {
+			feed.FailingSince = now
+		}
+		feed.NextRetryAt = now.Add(c.feedBackoff(feed.ConsecutiveFailures))
+
+		// disable if it has been dead for too long
+		if c.deadFeedDays > 0 && now.Sub(feed.FailingSince) > time.Duration(c.deadFeedDays)*24*time.Hour {
+			log.Printf("disabling feed '%s' which has been failing since %s", url, feed.FailingSince.Format(time.RFC3339))
+
+			feed.Disabled = true
+		}
+	} else {
+		feed.LastError = ""
+		feed.LastSuccessAt = now
+		feed.ConsecutiveFailures = 0
+		feed.FailingSince = time.Time{}
+		feed.NextRetryAt = time.Time{}
+		feed.fill(result.fetched)
+		feed.fillWebSub(result.hub, result.topic)
+
+		movedTo = c.checkFeedMove(&feed, result)
+	}
+
+	if err := c.cache.SaveFeed(ctx, feed); err != nil {
+		log.Printf("failed to save feed '%s' to cache: %s", url, err)
+		return url
+	}
+
+	if movedTo != "" {
+		if err := c.moveFeed(ctx, url, movedTo); err != nil {
+			log.Printf("failed to move feed '%s' to '%s': %s", url, movedTo, err)
+			return url
+		}
+		return movedTo
+	}
+	return url
+}
+
+// exponential backoff for given number of consecutive failures
+func (c *Client) feedBackoff(failures int) time.Duration {
+	backoff := c.feedBackoffBase
+	for i := 1; i < failures && backoff < c.feedBackoffMax; i++ {
+		backoff *= 2
+	}
+	return min(backoff, c.feedBackoffMax)
+}
+
+// check if the feed at `url` should be fetched now (not disabled nor backing off)
+//
+// NOTE: it returns true if the cache is unavailable.
+func (c *Client) shouldFetchFeed(ctx context.Context, url string) bool {
+	feed, err := c.cache.FetchFeed(ctx, url)
+	if err != nil {
+		return true
+	}
+	if feed.Disabled {
+		v(c.verbose, "skipping disabled feed: %s", url)
+		return false
+	}
+	if feed.NextRetryAt.After(time.Now()) {
+		v(c.verbose, "skipping feed backing off until %s: %s", feed.NextRetryAt.Format(time.RFC3339), url)
+		return false
+	}
+	return true
+}
+
+// FeedHealth is a struct for the health report of a feed
+type FeedHealth struct {
+	URL    string
+	Title  string
+	Status FeedStatus
+
+	LastFetchedAt       time.Time
+	LastSuccessAt       time.Time
+	LastHTTPStatus      int
+	LastError           string
+	ConsecutiveFailures int
+	FailingSince        time.Time
+	AverageLatency      time.Duration
+	NextRetryAt         time.Time
+}
+
+// FeedStatus is a type for the status of a feed
+type FeedStatus string
+
+// statuses of feeds
+const (
+	FeedStatusUnknown    FeedStatus = "unknown" // not fetched yet
+	FeedStatusHealthy    FeedStatus = "healthy"
+	FeedStatusBackingOff FeedStatus = "backing_off"
+	FeedStatusDisabled   FeedStatus = "disabled"
+)
+
+// FeedHealth reports the health of the client's feeds.
+func (c *Client) FeedHealth(ctx context.Context) ([]FeedHealth, error) {
+	feedsURLs := c.getFeedsURLs()
+	reports := make([]FeedHealth, 0, len(feedsURLs))
+	for _, url := range feedsURLs {
+		feed, err := c.cache.FetchFeed(ctx, url)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				reports = append(reports, FeedHealth{URL: url, Status: FeedStatusUnknown})
+				continue
+			}
+			return nil, err
+		}
+
+		report := FeedHealth{
+			URL:                 url,
+			Title:               feed.Title,
+			Status:              FeedStatusHealthy,
+			LastFetchedAt:       feed.LastFetchedAt,
+			LastSuccessAt:       feed.LastSuccessAt,
+			LastHTTPStatus:      feed.LastHTTPStatus,
+			LastError:           feed.LastError,
+			ConsecutiveFailures: feed.ConsecutiveFailures,
+			FailingSince:        feed.FailingSince,
+			AverageLatency:      feed.AverageLatency,
+			NextRetryAt:         feed.NextRetryAt,
+		}
+		if feed.Disabled {
+			report.Status = FeedStatusDisabled
+		} else if feed.ConsecutiveFailures > 0 {
+			report.Status = FeedStatusBackingOff
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// EnableFeed re-enables the feed at `url` which was disabled or backing off.
+func (c *Client) EnableFeed(ctx context.Context, url string) error {
+	feed, err := c.cache.FetchFeed(ctx, url)
+	if err != nil {
+		return err
+	}
+	feed.Disabled = false
+	feed.ConsecutiveFailures = 0
+	feed.FailingSince = time.Time{}
+	feed.NextRetryAt = time.Time{}
+
+	return c.cache.SaveFeed(ctx, *feed)
+}
@@ -0,0 +1,203 @@
+package rf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// test feeds and filtering cached items by them in caches
+func TestCacheFeeds(t *testing.T) {
+	ctx := context.Background()
+
+	dbc, err := newDBCache(filepath.Join(t.TempDir(), "feed.db"))
+	if err != nil {
+		t.Fatalf("failed to create dbCache: %s", err)
+	}
+
+	for name, cache := range map[string]FeedsItemsCacheV2{
+		"mem": newMemCache(),
+		"db":  dbc,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := cache.FetchFeed(ctx, "https://example.com/feed-1"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected ErrNotFound, got %v", err)
+			}
+
+			for _, title := range []string{"Feed", "Updated Feed"} {
+				if err := cache.SaveFeed(ctx, Feed{URL: "https://example.com/feed-1", Title: title, LastFetchedAt: time.Now()}); err != nil {
+					t.Fatalf("SaveFeed failed: %s", err)
+				}
+			}
+			_ = cache.SaveFeed(ctx, Feed{URL: "https://example.com/feed-2", Title: "Another Feed"})
+
+			feed, err := cache.FetchFeed(ctx, "https://example.com/feed-1")
+			if err != nil {
+				t.Fatalf("FetchFeed failed: %s", err)
+			}
+			if feed.Title != "Updated Feed" {
+				t.Errorf("expected updated title, got %q", feed.Title)
+			}
+			if feeds, _ := cache.ListFeeds(ctx); len(feeds) != 2 || feeds[0].URL != "https://example.com/feed-1" {
+				t.Errorf("unexpected feeds: %+v", feeds)
+			}
+
+			_ = cache.Save(ctx, ItemToCache{Item: testFeedItem("feed-item-1", "Title"), Title: "Title", Summary: "Summary", FeedURL: "https://example.com/feed-1"})
+			_ = cache.Save(ctx, ItemToCache{Item: testFeedItem("feed-item-2", "Title"), Title: "Title", Summary: "Summary", FeedURL: "https://example.com/feed-2"})
+
+			items, err := cache.ListWithFilter(ctx, ListFilter{FeedURL: "https://example.com/feed-1"})
+			if err != nil {
+				t.Fatalf("ListWithFilter failed: %s", err)
+			}
+			if len(items) != 1 || items[0].GUID != "feed-item-1" {
+				t.Errorf("expected only the item of feed-1, got %+v", items)
+			}
+			if items, _ := cache.ListWithFilter(ctx, ListFilter{}); len(items) != 2 {
+				t.Errorf("expected 2 items without feed filter, got %d", len(items))
+			}
+		})
+	}
+}
+
+// test recording fetched feeds
+func TestFetchFeedsRecordsFeed(t *testing.T) {
+	rssFeed := `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Test Feed</title>
+    <link>https://example.com</link>
+    <language>en-us</language>
+    <item>
+      <title>Article</title>
+      <link>https://example.com/article</link>
+      <guid>guid-recorded</guid>
+      <pubDate>` + time.Now().Format(time.RFC1123Z) + `</pubDate>
+    </item>
+  </channel>
+</rss>`
+
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprint(w, rssFeed)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := NewClient([]string{"key"}, []string{server.URL})
+
+	feeds, err := client.FetchFeeds(ctx, true, 7)
+	if err != nil || len(feeds) != 1 {
+		t.Fatalf("expected 1 feed, got %d (%v)", len(feeds), err)
+	}
+	if feedURLOf(feeds[0]) != server.URL {
+		t.Errorf("expected feed url '%s', got '%s'", server.URL, feedURLOf(feeds[0]))
+	}
+
+	recorded, err := client.ListFeeds(ctx)
+	if err != nil || len(recorded) != 1 {
+		t.Fatalf("expected 1 recorded feed, got %d (%v)", len(recorded), err)
+	}
+	if recorded[0].Title != "Test Feed" || recorded[0].SiteLink != "https://example.com" || recorded[0].Language != "en-us" {
+		t.Errorf("unexpected recorded feed: %+v", recorded[0])
+	}
+
+	// errors are recorded, keeping the metadata
+	failing.Store(true)
+	if _, err := client.FetchFeeds(ctx, true, 7); err == nil {
+		t.Fatal("expected an error from failing feed")
+	}
+	if recorded, _ = client.ListFeeds(ctx); len(recorded[0].LastError) <= 0 || recorded[0].Title != "Test Feed" {
+		t.Errorf("unexpected recorded feed after failure: %+v", recorded[0])
+	}
+}
+
+// test health of failing feeds
+func TestFeedHealth(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := NewClient([]string{"key"}, []string{server.URL})
+	client.SetFeedBackoff(time.Hour, 4*time.Hour)
+	client.SetDeadFeedDays(1)
+
+	if reports, _ := client.FeedHealth(ctx); len(reports) != 1 || reports[0].Status != FeedStatusUnknown {
+		t.Errorf("expected unknown status before fetch, got %+v", reports)
+	}
+
+	if _, err := client.FetchFeeds(ctx, false, 7); err == nil {
+		t.Fatal("expected an error from failing feed")
+	}
+
+	reports, err := client.FeedHealth(ctx)
+	if err != nil {
+		t.Fatalf("FeedHealth failed: %s", err)
+	}
+	if reports[0].Status != FeedStatusBackingOff || reports[0].LastHTTPStatus != http.StatusInternalServerError || reports[0].ConsecutiveFailures != 1 {
+		t.Errorf("unexpected health report: %+v", reports[0])
+	}
+
+	// backing off, so not fetched again
+	if _, err := client.FetchFeeds(ctx, false, 7); err != nil || requests.Load() != 1 {
+		t.Errorf("expected feed to be skipped while backing off, got %d requests (%v)", requests.Load(), err)
+	}
+
+	// failing for too long, so disabled
+	feed, _ := client.cache.FetchFeed(ctx, server.URL)
+	feed.FailingSince = time.Now().Add(-48 * time.Hour)
+	feed.NextRetryAt = time.Time{}
+	_ = client.cache.SaveFeed(ctx, *feed)
+
+	_, _ = client.FetchFeeds(ctx, false, 7)
+	if reports, _ := client.FeedHealth(ctx); reports[0].Status != FeedStatusDisabled || reports[0].ConsecutiveFailures != 2 {
+		t.Errorf("expected feed to be disabled, got %+v", reports[0])
+	}
+
+	if err := client.EnableFeed(ctx, server.URL); err != nil {
+		t.Fatalf("EnableFeed failed: %s", err)
+	}
+	if reports, _ := client.FeedHealth(ctx); reports[0].Status != FeedStatusHealthy {
+		t.Errorf("expected feed to be enabled, got %+v", reports[0])
+	}
+
+	// canceled fetches are not recorded as failures
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	client.recordFeed(canceled, server.URL, feedFetchResult{err: canceled.Err()})
+	if reports, _ := client.FeedHealth(ctx); reports[0].Status != FeedStatusHealthy {
+		t.Errorf("expected canceled fetch not to be recorded, got %+v", reports[0])
+	}
+}
+
+// test exponential backoff of failing feeds
+func TestFeedBackoff(t *testing.T) {
+	client := NewClient([]string{"key"}, nil)
+	client.SetFeedBackoff(time.Minute, 10*time.Minute)
+
+	for failures, expected := range map[int]time.Duration{
+		1:   time.Minute,
+		2:   2 * time.Minute,
+		4:   8 * time.Minute,
+		5:   10 * time.Minute,
+		100: 10 * time.Minute,
+	} {
+		if backoff := client.feedBackoff(failures); backoff != expected {
+			t.Errorf("expected backoff %s for %d failures, got %s", expected, failures, backoff)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package rf
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	importBatchSize = 100
+
+	maxImportLineBytes = 16 * 1024 * 1024 // max size of a line in JSONL
+)
+
+// ImportMode is a type for the mode of importing cached items
+type ImportMode int
+
+// ImportMode constants
+const (
+	// ImportModeMerge merges imported items into existing ones (non-empty fields win, read state is kept)
+	ImportModeMerge ImportMode = iota
+	// ImportModeOverwrite replaces existing items with imported ones
+	ImportModeOverwrite
+	// ImportModeSkipExisting imports only the items which do not exist yet
+	ImportModeSkipExisting
+)
+
+// String returns the name of the import mode.
+func (m ImportMode) String() string {
+	switch m {
+	case ImportModeMerge:
+		return "merge"
+	case ImportModeOverwrite:
+		return "overwrite"
+	case ImportModeSkipExisting:
+		return "skip-existing"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(m))
+	}
+}
+
+// ExportFilter is a struct for filtering cached items to export
+//
+// Zero value exports all cached items.
+type ExportFilter struct {
+	ExcludeItemsMarkedAsRead bool
+
+	CreatedAfter  time.Time // inclusive, ignored if zero
+	CreatedBefore time.Time // exclusive, ignored if zero
+}
+
+// matches checks if given item matches the filter.
+func (f ExportFilter) matches(item CachedItem) bool {
+	if f.ExcludeItemsMarkedAsRead && item.MarkedAsRead {
+		return false
+	}
+	if !f.CreatedAfter.IsZero() && item.CreatedAt.Before(f.CreatedAfter) {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && !item.CreatedAt.Before(f.CreatedBefore) {
+		return false
+	}
+	return true
+}
+
+// ExportCache writes cached items matching `filter` to `w` as JSON Lines
+// (one `CachedItem` per line), and returns the number of exported items.
+func (c *Client) ExportCache(w io.Writer, filter ExportFilter) (exported int, err error) {
+	return exportCachedItems(context.Background(), c.cache, w, filter, c.googleAIAPIKeys)
+}
+
+// ImportCache reads JSON Lines of `CachedItem`s from `r` and imports them
+// into the cache with given `mode`, keyed by GUID.
+//
+// It returns per-item results of the items imported so far, even on error.
+func (c *Client) ImportCache(r io.Reader, mode ImportMode) (results BatchResults, err error) {
+	return importCachedItems(context.Background(), c.cache, r, mode)
+}
+
+// export cached items of `cache` to `w` as JSON Lines
+func exportCachedItems(ctx context.Context, cache FeedsItemsCacheV2, w io.Writer, filter ExportFilter, baddies []string) (exported int, err error) {
+	bw := bufio.NewWriter(w)
+	encoder := json.NewEncoder(bw)
+
+	if err := cache.ForEach(ctx, func(item CachedItem) error {
+		if !filter.matches(item) {
+			return nil
+		}
+		if len(item.Summary) > 0 {
+			item.Summary = redactText(item.Summary, baddies)
+		}
+		if err := encoder.Encode(item); err != nil {
+			return fmt.Errorf("failed to encode cached item '%s': %w", item.GUID, err)
+		}
+		exported++
+		return nil
+	}); err != nil {
+		return exported, fmt.Errorf("failed to export cached items: %w", err)
+	}
+
+	if err := bw.Flush(); err != nil {
+		return exported, fmt.Errorf("failed to flush exported cached items: %w", err)
+	}
+
+	return exported, nil
+}
+
+// import JSON Lines of cached items from `r` into `cache`
+func importCachedItems(ctx context.Context, cache FeedsItemsCacheV2, r io.Reader, mode ImportMode) (results BatchResults, err error) {
+	switch mode {
+	case ImportModeMerge, ImportModeOverwrite, ImportModeSkipExisting:
+	default:
+		return nil, fmt.Errorf("not a supported import mode: %s", mode)
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLineBytes)
+
+	batch := make([]CachedItem, 0, importBatchSize)
+	flush := func() {
+		if len(batch) > 0 {
+			results = append(results, cache.Import(ctx, batch, mode)...)
+			batch = batch[:0]
+		}
+	}
+
+	line := 0
+	for scanner.Scan() {
+		line++
+
+		bytes := scanner.Bytes()
+		if len(bytes) == 0 {
+			continue
+		}
+
+		var item CachedItem
+		if err := json.Unmarshal(bytes, &item); err != nil {
+			flush()
+			return results, fmt.Errorf("failed to decode cached item at line %d: %w", line, err)
+		}
+		if len(item.GUID) == 0 {
+			flush()
+			return results, fmt.Errorf("cached item at line %d has no guid", line)
+		}
+
+		// NOTE: ids are local to each backend, so they are not imported
+		item.Model.ID = 0
+
+		batch = append(batch, item)
+		if len(batch) >= importBatchSize {
+			flush()
+		}
+	}
+	flush()
+
+	if err := scanner.Err(); err != nil {
+		return results, fmt.Errorf("failed to read cached items after line %d: %w", line, err)
+	}
+
+	return results, nil
+}
+
+// merge `imported` into `existing` for `ImportModeMerge`
+func mergeCachedItems(existing, imported CachedItem) CachedItem {
+	merged := existing
+
+	for _, f := range []struct {
+		dst *string
+		src string
+	}{
+		{&merged.Title, imported.Title},
+		{&merged.OriginalTitle, imported.OriginalTitle},
+		{&merged.Link, imported.Link},
+		{&merged.Comments, imported.Comments},
+		{&merged.Author, imported.Author},
+		{&merged.PublishDate, imported.PublishDate},
+		{&merged.UpdateDate, imported.UpdateDate},
+		{&merged.Description, imported.Description},
+		{&merged.FeedURL, imported.FeedURL},
+		{&merged.ImageURL, imported.ImageURL},
+		{&merged.Summary, imported.Summary},
+	} {
+		if len(f.src) > 0 {
+			*f.dst = f.src
+		}
+	}
+	if len(imported.Categories) > 0 {
+		merged.Categories = imported.Categories
+	}
+	if len(imported.Enclosures) > 0 {
+		merged.Enclosures = imported.Enclosures
+	}
+	merged.MarkedAsRead = existing.MarkedAsRead || imported.MarkedAsRead
+
+	if !imported.CreatedAt.IsZero() && (merged.CreatedAt.IsZero() || imported.CreatedAt.Before(merged.CreatedAt)) {
+		merged.CreatedAt = imported.CreatedAt
+	}
+	if imported.UpdatedAt.After(merged.UpdatedAt) {
+		merged.UpdatedAt = imported.UpdatedAt
+	}
+
+	return merged
+}
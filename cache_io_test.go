@@ -0,0 +1,159 @@
+package rf
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// test exporting from memCache and importing into dbCache, and the other way around
+func TestExportImportCacheAcrossBackends(t *testing.T) {
+	ctx := context.Background()
+
+	memClient := NewClient([]string{"secret-key"}, nil)
+	_ = memClient.cache.Save(ctx, ItemToCache{Item: testFeedItem("io-1", "Title 1"), Title: "Translated 1", Summary: "Summary with secret-key"})
+	_ = memClient.cache.Save(ctx, ItemToCache{Item: testFeedItem("io-2", "Title 2"), Title: "Translated 2", Summary: "Summary 2"})
+	_ = memClient.cache.MarkAsRead(ctx, "io-2")
+
+	var buf bytes.Buffer
+	exported, err := memClient.ExportCache(&buf, ExportFilter{})
+	if err != nil {
+		t.Fatalf("ExportCache failed: %s", err)
+	}
+	if exported != 2 {
+		t.Errorf("expected 2 exported items, got %d", exported)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 2 {
+		t.Errorf("expected 2 lines of JSONL, got %d", lines)
+	}
+	if strings.Contains(buf.String(), "secret-key") {
+		t.Error("expected API key to be redacted in exported items")
+	}
+
+	dbClient, err := NewClientWithDB([]string{"key"}, nil, filepath.Join(t.TempDir(), "import.db"))
+	if err != nil {
+		t.Fatalf("failed to create client with DB: %s", err)
+	}
+	results, err := dbClient.ImportCache(bytes.NewReader(buf.Bytes()), ImportModeMerge)
+	if err != nil {
+		t.Fatalf("ImportCache failed: %s", err)
+	}
+	if err := results.Err(); err != nil {
+		t.Fatalf("ImportCache failed for some items: %s", err)
+	}
+
+	imported, err := dbClient.cache.Fetch(ctx, "io-2")
+	if err != nil {
+		t.Fatalf("expected imported item 'io-2': %s", err)
+	}
+	if imported.Title != "Translated 2" || !imported.MarkedAsRead {
+		t.Errorf("unexpected imported item: %+v", imported)
+	}
+
+	// and back into a fresh memCache
+	buf.Reset()
+	if exported, err = dbClient.ExportCache(&buf, ExportFilter{}); err != nil || exported != 2 {
+		t.Fatalf("ExportCache from DB failed: %d, %v", exported, err)
+	}
+	memClient2 := NewClient(nil, nil)
+	if _, err := memClient2.ImportCache(&buf, ImportModeMerge); err != nil {
+		t.Fatalf("ImportCache into memory failed: %s", err)
+	}
+	if items := memClient2.ListCachedItems(true); len(items) != 2 {
+		t.Errorf("expected 2 items, got %d", len(items))
+	}
+}
+
+// test import modes
+func TestImportCacheModes(t *testing.T) {
+	ctx := context.Background()
+
+	for _, backend := range []string{"mem", "db"} {
+		t.Run(backend, func(t *testing.T) {
+			newCache := func() FeedsItemsCacheV2 {
+				if backend == "mem" {
+					return newMemCache()
+				}
+				cache, err := newDBCache(filepath.Join(t.TempDir(), "modes.db"))
+				if err != nil {
+					t.Fatalf("failed to create dbCache: %s", err)
+				}
+				return cache
+			}
+			jsonl := `{"GUID":"mode-1","Title":"Imported Title","Summary":"","MarkedAsRead":false}` + "\n"
+
+			tests := []struct {
+				mode          ImportMode
+				expectTitle   string
+				expectSummary string
+				expectRead    bool
+			}{
+				{ImportModeMerge, "Imported Title", "Existing Summary", true},
+				{ImportModeOverwrite, "Imported Title", "", false},
+				{ImportModeSkipExisting, "Existing Title", "Existing Summary", true},
+			}
+			for _, tt := range tests {
+				cache := newCache()
+				_ = cache.Save(ctx, ItemToCache{Item: testFeedItem("mode-1", "title"), Title: "Existing Title", Summary: "Existing Summary"})
+				_ = cache.MarkAsRead(ctx, "mode-1")
+
+				results, err := importCachedItems(ctx, cache, strings.NewReader(jsonl), tt.mode)
+				if err != nil || results.Err() != nil {
+					t.Fatalf("[%s] import failed: %v, %v", tt.mode, err, results.Err())
+				}
+
+				cached, err := cache.Fetch(ctx, "mode-1")
+				if err != nil {
+					t.Fatalf("[%s] expected cached item: %s", tt.mode, err)
+				}
+				if cached.Title != tt.expectTitle || cached.Summary != tt.expectSummary || cached.MarkedAsRead != tt.expectRead {
+					t.Errorf("[%s] unexpected item: title=%q summary=%q read=%v", tt.mode, cached.Title, cached.Summary, cached.MarkedAsRead)
+				}
+			}
+		})
+	}
+}
+
+// test export filter
+func TestExportFilter(t *testing.T) {
+	now := time.Now()
+
+	read := CachedItem{GUID: "read", MarkedAsRead: true}
+	read.CreatedAt = now.Add(-2 * time.Hour)
+	unread := CachedItem{GUID: "unread"}
+	unread.CreatedAt = now
+
+	if !(ExportFilter{}).matches(read) {
+		t.Error("expected zero filter to match everything")
+	}
+	if (ExportFilter{ExcludeItemsMarkedAsRead: true}).matches(read) {
+		t.Error("expected read item to be excluded")
+	}
+	if (ExportFilter{CreatedAfter: now.Add(-time.Hour)}).matches(read) {
+		t.Error("expected older item to be excluded")
+	}
+	if (ExportFilter{CreatedBefore: now}).matches(unread) {
+		t.Error("expected CreatedBefore to be exclusive")
+	}
+}
+
+// test importing malformed JSONL
+func TestImportCacheMalformed(t *testing.T) {
+	client := NewClient(nil, nil)
+
+	jsonl := `{"GUID":"ok-1"}` + "\n" + `not a json` + "\n"
+	results, err := client.ImportCache(strings.NewReader(jsonl), ImportModeMerge)
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected error at line 2, got %v", err)
+	}
+	if len(results) != 1 {
+		t.Errorf("expected items before the malformed line to be imported, got %d", len(results))
+	}
+
+	if _, err := client.ImportCache(strings.NewReader(`{"Title":"no guid"}`), ImportModeMerge); err == nil {
+		t.Error("expected error for an item without guid")
+	}
+}
@@ -1,11 +1,14 @@
 package rf
 
 import (
+	"cmp"
+	"container/list"
+	"context"
+	"fmt"
 	"maps"
+	"slices"
 	"sync"
 	"time"
-
-	"github.com/mmcdole/gofeed"
 )
 
 ////////////////
@@ -15,14 +18,41 @@ import (
 
 // memory cache
 type memCache struct {
-	mu    sync.RWMutex
-	items map[string]CachedItem
+	mu        sync.RWMutex
+	items     map[string]CachedItem
+	contents  map[string]OriginalContent
+	revisions map[string][]SummaryRevision
+	revision  uint // last id of revisions
+	feeds     map[string]Feed
+	feed      uint // last id of feeds
+	digests   []Digest
+	digest    uint // last id of digests
+
+	deliveries map[string]map[string]Delivery // guid => sink => delivery
+	delivery   uint                           // last id of deliveries
+
+	// LRU lists of guids (front = most recently used), read items are evicted first
+	lruRead   *list.List
+	lruUnread *list.List
+	elems     map[string]*list.Element
+	bytes     int64
+
+	maxItems      int   // 0 for no limit
+	maxBytes      int64 // 0 for no limit
+	evictions     uint64
+	evictedUnread uint64
+	evictedBytes  int64
+
+	snapshotPath string
+	snapshotStop chan struct{}
+	snapshotDone chan struct{}
+	closeOnce    sync.Once
 
 	verbose bool
 }
 
-// Exists checks for the existence of `id` in the cache.
-func (c *memCache) Exists(guid string) bool {
+// Exists checks for the existence of `guid` in the cache.
+func (c *memCache) Exists(ctx context.Context, guid string) (bool, error) {
 	v(c.verbose, "memCache - checking existence of cached item with guid: %s", guid)
 
 	c.mu.RLock()
@@ -30,81 +60,644 @@ func (c *memCache) Exists(guid string) bool {
 
 	_, exists := c.items[guid]
 
-	return exists
+	return exists, nil
 }
 
 // Save saves given item to the cache.
-func (c *memCache) Save(item gofeed.Item, title, summary string) error {
-	v(c.verbose, "memCache - saving item to cache: %s (%s)", item.Title, title)
+func (c *memCache) Save(ctx context.Context, item ItemToCache) error {
+	v(c.verbose, "memCache - saving item to cache: %s (%s)", item.Item.Title, item.Title)
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	cached := newCachedItem(item, title, summary)
-	c.items[item.GUID] = cached
+	c.upsert(item.cachedItem())
+	c.evict()
 
 	return nil
 }
 
 // Fetch fetches the cached item with given `guid`.
-func (c *memCache) Fetch(guid string) *CachedItem {
+func (c *memCache) Fetch(ctx context.Context, guid string) (*CachedItem, error) {
 	v(c.verbose, "memCache - fetching cached item with guid: %s", guid)
 
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	c.mu.Lock() // NOTE: not a read lock, for updating LRU list
+	defer c.mu.Unlock()
 
 	if v, exists := c.items[guid]; exists {
-		return &v
+		c.touch(v)
+		return &v, nil
 	}
-	return nil
+	return nil, fmt.Errorf("%w: '%s'", ErrNotFound, guid)
 }
 
 // MarkAsRead marks a cached item as read.
-func (c *memCache) MarkAsRead(guid string) error {
+func (c *memCache) MarkAsRead(ctx context.Context, guid string) error {
 	v(c.verbose, "memCache - marking cached item with guid: %s as read", guid)
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if item, exists := c.items[guid]; exists {
-		item.MarkedAsRead = true
-		c.items[guid] = item
-	}
-
-	return nil
+	return c.markAsRead(guid)
 }
 
 // List lists all cached items.
-func (c *memCache) List(includeItemsMarkedAsRead bool) []CachedItem {
-	v(c.verbose, "memCache - listing cached items with includeItemsMarkedAsRead = %v", includeItemsMarkedAsRead)
+func (c *memCache) List(ctx context.Context, includeItemsMarkedAsRead bool) ([]CachedItem, error) {
+	return c.ListWithFilter(ctx, ListFilter{IncludeItemsMarkedAsRead: includeItemsMarkedAsRead})
+}
+
+// ListWithFilter lists cached items which match given `filter`.
+func (c *memCache) ListWithFilter(ctx context.Context, filter ListFilter) ([]CachedItem, error) {
+	v(c.verbose, "memCache - listing cached items with filter: %+v", filter)
 
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	var all []CachedItem
 	for _, item := range c.items {
-		if includeItemsMarkedAsRead || !item.MarkedAsRead {
+		if (filter.IncludeItemsMarkedAsRead || !item.MarkedAsRead) &&
+			(len(filter.FeedURL) <= 0 || item.FeedURL == filter.FeedURL) &&
+			(len(filter.Category) <= 0 || slices.Contains(item.Categories, filter.Category)) &&
+			(filter.Since.IsZero() || !item.CreatedAt.Before(filter.Since)) &&
+			(filter.Until.IsZero() || item.CreatedAt.Before(filter.Until)) {
 			all = append(all, item)
 		}
 	}
 
-	return all
+	return all, nil
+}
+
+// ListCategories lists distinct categories of cached items, sorted.
+func (c *memCache) ListCategories(ctx context.Context) ([]string, error) {
+	v(c.verbose, "memCache - listing categories of cached items")
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	categories := map[string]bool{}
+	for _, item := range c.items {
+		for _, category := range item.Categories {
+			categories[category] = true
+		}
+	}
+
+	return slices.Sorted(maps.Keys(categories)), nil
 }
 
 // DeleteOlderThan1Month deletes cached items which are older than 1 month.
-func (c *memCache) DeleteOlderThan1Month() error {
+func (c *memCache) DeleteOlderThan1Month(ctx context.Context) error {
 	v(c.verbose, "memCache - deleting cached items older than 1 month")
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	maps.DeleteFunc(c.items, func(_ string, v CachedItem) bool {
-		return v.CreatedAt.Before(time.Now().Add(-30 * 24 * time.Hour))
+	cutoff := time.Now().Add(-30 * 24 * time.Hour)
+	for guid, item := range c.items {
+		if item.CreatedAt.Before(cutoff) {
+			c.remove(guid)
+		}
+	}
+
+	return nil
+}
+
+// SaveMany saves given items to the cache under a single lock.
+func (c *memCache) SaveMany(ctx context.Context, items []ItemToCache) BatchResults {
+	v(c.verbose, "memCache - saving %d items to cache", len(items))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	results := make(BatchResults, 0, len(items))
+	for _, item := range items {
+		c.upsert(item.cachedItem())
+
+		results = append(results, BatchResult{GUID: item.Item.GUID})
+	}
+	c.evict()
+
+	return results
+}
+
+// MarkManyAsRead marks cached items with given `guids` as read under a single lock.
+func (c *memCache) MarkManyAsRead(ctx context.Context, guids []string) BatchResults {
+	v(c.verbose, "memCache - marking %d cached items as read", len(guids))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	results := make(BatchResults, 0, len(guids))
+	for _, guid := range guids {
+		results = append(results, BatchResult{GUID: guid, Err: c.markAsRead(guid)})
+	}
+
+	return results
+}
+
+// DeleteMany deletes cached items with given `guids` under a single lock.
+func (c *memCache) DeleteMany(ctx context.Context, guids []string) BatchResults {
+	v(c.verbose, "memCache - deleting %d cached items", len(guids))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	results := make(BatchResults, 0, len(guids))
+	for _, guid := range guids {
+		var err error
+		if _, exists := c.items[guid]; exists {
+			c.remove(guid)
+		} else {
+			err = fmt.Errorf("%w: '%s'", ErrNotFound, guid)
+		}
+
+		results = append(results, BatchResult{GUID: guid, Err: err})
+	}
+
+	return results
+}
+
+// ForEach calls `fn` for each cached item in the order of creation,
+// stopping at the first error.
+func (c *memCache) ForEach(ctx context.Context, fn func(item CachedItem) error) error {
+	v(c.verbose, "memCache - iterating cached items")
+
+	c.mu.RLock()
+	all := slices.Collect(maps.Values(c.items))
+	c.mu.RUnlock()
+
+	slices.SortFunc(all, func(a, b CachedItem) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.GUID, b.GUID))
+	})
+
+	for _, item := range all {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Import imports given cached items with `mode` under a single lock.
+func (c *memCache) Import(ctx context.Context, items []CachedItem, mode ImportMode) BatchResults {
+	v(c.verbose, "memCache - importing %d cached items with mode: %s", len(items), mode)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	results := make(BatchResults, 0, len(items))
+	for _, item := range items {
+		if existing, exists := c.items[item.GUID]; exists {
+			switch mode {
+			case ImportModeMerge:
+				item = mergeCachedItems(existing, item)
+			case ImportModeSkipExisting:
+				item = existing
+			}
+		}
+		c.put(item)
+
+		results = append(results, BatchResult{GUID: item.GUID})
+	}
+	c.evict()
+
+	return results
+}
+
+// SaveContent saves the original content of a cached item.
+func (c *memCache) SaveContent(ctx context.Context, content OriginalContent) error {
+	v(c.verbose, "memCache - saving original content of cached item with guid: %s", content.GUID)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.items[content.GUID]; !exists {
+		return fmt.Errorf("%w: '%s'", ErrNotFound, content.GUID)
+	}
+
+	if old, exists := c.contents[content.GUID]; exists {
+		c.bytes -= int64(len(old.Compressed))
+	}
+	c.contents[content.GUID] = content
+	c.bytes += int64(len(content.Compressed))
+	c.evict()
+
+	return nil
+}
+
+// FetchContent fetches the original content of the cached item with given `guid`.
+func (c *memCache) FetchContent(ctx context.Context, guid string) (*OriginalContent, error) {
+	v(c.verbose, "memCache - fetching original content of cached item with guid: %s", guid)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if content, exists := c.contents[guid]; exists {
+		return &content, nil
+	}
+	return nil, fmt.Errorf("%w: original content of '%s'", ErrNotFound, guid)
+}
+
+// UpdateSummary updates the title and summary of the cached item with given `guid`.
+func (c *memCache) UpdateSummary(ctx context.Context, guid, title, summary string) error {
+	v(c.verbose, "memCache - updating summary of cached item with guid: %s", guid)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, exists := c.items[guid]
+	if !exists {
+		return fmt.Errorf("%w: '%s'", ErrNotFound, guid)
+	}
+	item.Title, item.Summary = title, summary
+	item.UpdatedAt = time.Now()
+	c.put(item)
+	c.evict()
+
+	return nil
+}
+
+// SaveRevision saves a revision of a cached item's summary.
+func (c *memCache) SaveRevision(ctx context.Context, revision SummaryRevision) error {
+	v(c.verbose, "memCache - saving revision of cached item with guid: %s", revision.GUID)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.items[revision.GUID]; !exists {
+		return fmt.Errorf("%w: '%s'", ErrNotFound, revision.GUID)
+	}
+
+	c.revision++
+	revision.ID = c.revision
+	revision.CreatedAt = time.Now()
+	revision.UpdatedAt = revision.CreatedAt
+
+	c.revisions[revision.GUID] = append(c.revisions[revision.GUID], revision)
+	c.bytes += revisionsSize([]SummaryRevision{revision})
+	c.evict()
+
+	return nil
+}
+
+// ListRevisions lists the revisions of the cached item with given `guid`, newest first.
+func (c *memCache) ListRevisions(ctx context.Context, guid string) ([]SummaryRevision, error) {
+	v(c.verbose, "memCache - listing revisions of cached item with guid: %s", guid)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	revisions := slices.Clone(c.revisions[guid])
+	slices.Reverse(revisions)
+
+	return revisions, nil
+}
+
+// SaveFeed saves (or updates) given feed.
+func (c *memCache) SaveFeed(ctx context.Context, feed Feed) error {
+	v(c.verbose, "memCache - saving feed: %s", feed.URL)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if existing, exists := c.feeds[feed.URL]; exists {
+		feed.ID, feed.CreatedAt = existing.ID, existing.CreatedAt
+	} else {
+		c.feed++
+		feed.ID, feed.CreatedAt = c.feed, now
+	}
+	feed.UpdatedAt = now
+	c.feeds[feed.URL] = feed
+
+	return nil
+}
+
+// FetchFeed fetches the feed with given `url`.
+func (c *memCache) FetchFeed(ctx context.Context, url string) (*Feed, error) {
+	v(c.verbose, "memCache - fetching feed: %s", url)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if feed, exists := c.feeds[url]; exists {
+		return &feed, nil
+	}
+	return nil, fmt.Errorf("%w: feed '%s'", ErrNotFound, url)
+}
+
+// ListFeeds lists all feeds in the order of creation.
+func (c *memCache) ListFeeds(ctx context.Context) ([]Feed, error) {
+	v(c.verbose, "memCache - listing feeds")
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	feeds := slices.SortedFunc(maps.Values(c.feeds), func(a, b Feed) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return feeds, nil
+}
+
+// SaveDigest saves given digest.
+func (c *memCache) SaveDigest(ctx context.Context, digest *Digest) error {
+	v(c.verbose, "memCache - saving digest: %s", digest.Title)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.digest++
+	digest.ID = c.digest
+	digest.CreatedAt = time.Now()
+	digest.UpdatedAt = digest.CreatedAt
+
+	c.digests = append(c.digests, *digest)
+
+	return nil
+}
+
+// FetchDigest fetches the digest with given `id`.
+func (c *memCache) FetchDigest(ctx context.Context, id uint) (*Digest, error) {
+	v(c.verbose, "memCache - fetching digest: %d", id)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, digest := range c.digests {
+		if digest.ID == id {
+			return &digest, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: digest %d", ErrNotFound, id)
+}
+
+// ListDigests lists all digests, newest first.
+func (c *memCache) ListDigests(ctx context.Context) ([]Digest, error) {
+	v(c.verbose, "memCache - listing digests")
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	digests := slices.Clone(c.digests)
+	slices.Reverse(digests)
+
+	return digests, nil
+}
+
+// SaveDelivery saves (or updates) given delivery.
+func (c *memCache) SaveDelivery(ctx context.Context, delivery Delivery) error {
+	v(c.verbose, "memCache - saving delivery of cached item with guid: %s to sink: %s", delivery.GUID, delivery.Sink)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if old, exists := c.deliveries[delivery.GUID][delivery.Sink]; exists {
+		delivery.ID, delivery.CreatedAt = old.ID, old.CreatedAt
+	} else {
+		c.delivery++
+		delivery.ID, delivery.CreatedAt = c.delivery, now
+	}
+	delivery.UpdatedAt = now
+
+	if c.deliveries[delivery.GUID] == nil {
+		c.deliveries[delivery.GUID] = map[string]Delivery{}
+	}
+	c.deliveries[delivery.GUID][delivery.Sink] = delivery
+
+	return nil
+}
+
+// ListDeliveries lists the deliveries to the sink with given name.
+func (c *memCache) ListDeliveries(ctx context.Context, sink string) (deliveries []Delivery, err error) {
+	v(c.verbose, "memCache - listing deliveries to sink: %s", sink)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, sinks := range c.deliveries {
+		if delivery, exists := sinks[sink]; exists {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	slices.SortFunc(deliveries, func(a, b Delivery) int {
+		return cmp.Compare(a.ID, b.ID)
 	})
 
+	return deliveries, nil
+}
+
+// MoveFeed changes the url of the feed at `oldURL` to `newURL`, along with its cached items.
+func (c *memCache) MoveFeed(ctx context.Context, oldURL, newURL string) error {
+	v(c.verbose, "memCache - moving feed: %s => %s", oldURL, newURL)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	feed, exists := c.feeds[oldURL]
+	if !exists {
+		return fmt.Errorf("%w: feed '%s'", ErrNotFound, oldURL)
+	}
+	delete(c.feeds, oldURL)
+	feed.URL = newURL
+	feed.MovedTo, feed.MoveConfirmations = "", 0
+	feed.UpdatedAt = time.Now()
+	c.feeds[newURL] = feed
+
+	for guid, item := range c.items {
+		if item.FeedURL == oldURL {
+			item.FeedURL = newURL
+			c.bytes += int64(len(newURL) - len(oldURL))
+			c.items[guid] = item
+		}
+	}
+
+	return nil
+}
+
+// MemCacheStats is a struct for the statistics of memory cache
+type MemCacheStats struct {
+	Items int
+	Bytes int64 // approximate size of cached items (and their original contents and revisions)
+
+	MaxItems int   // 0 for no limit
+	MaxBytes int64 // 0 for no limit
+
+	Evictions     uint64 // number of evicted items
+	EvictedUnread uint64 // number of evicted items which were not marked as read
+	EvictedBytes  int64
+}
+
+// setLimits sets the limits of memory cache, evicting items if needed.
+func (c *memCache) setLimits(maxItems int, maxBytes int64) {
+	v(c.verbose, "memCache - setting limits: max items = %d, max bytes = %d", maxItems, maxBytes)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxItems = max(maxItems, 0)
+	c.maxBytes = max(maxBytes, 0)
+	c.evict()
+}
+
+// stats returns the statistics of memory cache.
+func (c *memCache) stats() MemCacheStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return MemCacheStats{
+		Items:         len(c.items),
+		Bytes:         c.bytes,
+		MaxItems:      c.maxItems,
+		MaxBytes:      c.maxBytes,
+		Evictions:     c.evictions,
+		EvictedUnread: c.evictedUnread,
+		EvictedBytes:  c.evictedBytes,
+	}
+}
+
+// put stores given item and marks it as the most recently used.
+//
+// NOTE: must be called with the write lock held.
+func (c *memCache) put(item CachedItem) {
+	if old, exists := c.items[item.GUID]; exists {
+		c.bytes -= cachedItemSize(old)
+	}
+	c.items[item.GUID] = item
+	c.bytes += cachedItemSize(item)
+
+	c.touch(item)
+}
+
+// upsert stores given item, keeping the creation time of the existing one
+// (and its feed url if given item has none).
+//
+// NOTE: must be called with the write lock held.
+func (c *memCache) upsert(item CachedItem) {
+	now := time.Now()
+	if existing, exists := c.items[item.GUID]; exists {
+		item.CreatedAt = existing.CreatedAt
+		if len(item.FeedURL) <= 0 {
+			item.FeedURL = existing.FeedURL
+		}
+	} else {
+		item.CreatedAt = now
+	}
+	item.UpdatedAt = now
+	c.put(item)
+}
+
+// markAsRead marks the item with given `guid` as read.
+//
+// NOTE: must be called with the write lock held.
+func (c *memCache) markAsRead(guid string) error {
+	item, exists := c.items[guid]
+	if !exists {
+		return fmt.Errorf("%w: '%s'", ErrNotFound, guid)
+	}
+	item.MarkedAsRead = true
+	c.put(item)
+
 	return nil
 }
 
+// touch marks given item as the most recently used one in its LRU list.
+//
+// NOTE: must be called with the write lock held.
+func (c *memCache) touch(item CachedItem) {
+	lru := c.lruUnread
+	if item.MarkedAsRead {
+		lru = c.lruRead
+	}
+
+	if elem, exists := c.elems[item.GUID]; exists {
+		c.lruRead.Remove(elem)
+		c.lruUnread.Remove(elem)
+	}
+	c.elems[item.GUID] = lru.PushFront(item.GUID)
+}
+
+// remove removes the item with given `guid`.
+//
+// NOTE: must be called with the write lock held.
+func (c *memCache) remove(guid string) {
+	if item, exists := c.items[guid]; exists {
+		c.bytes -= cachedItemSize(item)
+		delete(c.items, guid)
+	}
+	if content, exists := c.contents[guid]; exists {
+		c.bytes -= int64(len(content.Compressed))
+		delete(c.contents, guid)
+	}
+	if revisions, exists := c.revisions[guid]; exists {
+		c.bytes -= revisionsSize(revisions)
+		delete(c.revisions, guid)
+	}
+	delete(c.deliveries, guid)
+	if elem, exists := c.elems[guid]; exists {
+		c.lruRead.Remove(elem)
+		c.lruUnread.Remove(elem)
+		delete(c.elems, guid)
+	}
+}
+
+// evict evicts the least recently used items until the cache fits in its limits,
+// preferring items marked as read. (the most recently used item is always kept)
+//
+// NOTE: must be called with the write lock held.
+func (c *memCache) evict() {
+	for len(c.items) > 1 &&
+		((c.maxItems > 0 && len(c.items) > c.maxItems) || (c.maxBytes > 0 && c.bytes > c.maxBytes)) {
+		victim := c.lruRead.Back()
+		if victim == nil {
+			victim = c.lruUnread.Back()
+		}
+		guid := victim.Value.(string)
+		item := c.items[guid]
+
+		v(c.verbose, "memCache - evicting cached item with guid: %s", guid)
+
+		c.evictions++
+		if !item.MarkedAsRead {
+			c.evictedUnread++
+		}
+		c.evictedBytes += cachedItemSize(item) + int64(len(c.contents[guid].Compressed)) + revisionsSize(c.revisions[guid])
+
+		c.remove(guid)
+	}
+}
+
+// approximate size of given cached item in bytes
+func cachedItemSize(item CachedItem) int64 {
+	return int64(len(item.Title) +
+		len(item.OriginalTitle) +
+		len(item.Link) +
+		len(item.Comments) +
+		len(item.GUID) +
+		len(item.Author) +
+		len(item.PublishDate) +
+		len(item.UpdateDate) +
+		len(item.Description) +
+		len(item.FeedURL) +
+		len(item.ImageURL) +
+		len(item.Summary) +
+		mediaSize(item))
+}
+
+// approximate size of categories and enclosures of given cached item in bytes
+func mediaSize(item CachedItem) (size int) {
+	for _, category := range item.Categories {
+		size += len(category)
+	}
+	for _, enclosure := range item.Enclosures {
+		size += len(enclosure.URL) + len(enclosure.Length) + len(enclosure.Type)
+	}
+	return size
+}
+
 // SetVerbose sets the verbosity of cache.
 func (c *memCache) SetVerbose(v bool) {
 	c.verbose = v
@@ -113,6 +706,15 @@ func (c *memCache) SetVerbose(v bool) {
 // return a new memory cache
 func newMemCache() *memCache {
 	return &memCache{
-		items: map[string]CachedItem{},
+		items:     map[string]CachedItem{},
+		contents:  map[string]OriginalContent{},
+		revisions: map[string][]SummaryRevision{},
+		feeds:     map[string]Feed{},
+
+		deliveries: map[string]map[string]Delivery{},
+
+		lruRead:   list.New(),
+		lruUnread: list.New(),
+		elems:     map[string]*list.Element{},
 	}
 }
@@ -0,0 +1,287 @@
+package rf
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+////////////////
+//
+// (snapshot of memory cache)
+//
+
+// version of snapshot files
+//
+// NOTE: snapshots without a header (version 1) have cached items only.
+const snapshotVersion = 2
+
+// header (first line) of snapshot files
+type snapshotHeader struct {
+	Version int `json:"snapshot_version"`
+}
+
+// a record (line) of snapshot files, which has only one of its fields
+type snapshotRecord struct {
+	Item     *CachedItem      `json:",omitempty"`
+	Content  *OriginalContent `json:",omitempty"`
+	Revision *SummaryRevision `json:",omitempty"`
+	Feed     *Feed            `json:",omitempty"`
+	Delivery *Delivery        `json:",omitempty"`
+	Digest   *Digest          `json:",omitempty"`
+}
+
+// load the snapshot file of memory cache, if it exists
+func (c *memCache) loadSnapshot(path string) error {
+	v(c.verbose, "memCache - loading snapshot from: %s", path)
+
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil // nothing to load yet
+		}
+		return fmt.Errorf("failed to open snapshot '%s': %w", path, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLineBytes)
+
+	var header snapshotHeader
+	if scanner.Scan() && json.Unmarshal(scanner.Bytes(), &header) == nil && header.Version > 0 {
+		if header.Version > snapshotVersion {
+			return fmt.Errorf("failed to load snapshot '%s': not a supported version: %d", path, header.Version)
+		}
+		if err := c.restoreSnapshot(scanner); err != nil {
+			return fmt.Errorf("failed to load snapshot '%s': %w", path, err)
+		}
+		return nil
+	}
+
+	// snapshot of version 1 (JSON Lines of cached items)
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind snapshot '%s': %w", path, err)
+	}
+	results, err := importCachedItems(context.Background(), c, file, ImportModeOverwrite)
+	if err != nil {
+		return fmt.Errorf("failed to load snapshot '%s': %w", path, err)
+	}
+
+	v(c.verbose, "memCache - loaded %d items from snapshot", len(results))
+
+	return results.Err()
+}
+
+// restore records of a snapshot from `scanner` (after its header)
+func (c *memCache) restoreSnapshot(scanner *bufio.Scanner) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	line, items := 1, 0
+	for scanner.Scan() {
+		line++
+
+		bytes := scanner.Bytes()
+		if len(bytes) == 0 {
+			continue
+		}
+
+		var record snapshotRecord
+		if err := json.Unmarshal(bytes, &record); err != nil {
+			return fmt.Errorf("failed to decode record at line %d: %w", line, err)
+		}
+
+		switch {
+		case record.Item != nil:
+			if len(record.Item.GUID) == 0 {
+				return fmt.Errorf("cached item at line %d has no guid", line)
+			}
+			c.put(*record.Item)
+			items++
+		case record.Content != nil:
+			content := *record.Content
+			if old, exists := c.contents[content.GUID]; exists {
+				c.bytes -= int64(len(old.Compressed))
+			}
+			c.contents[content.GUID] = content
+			c.bytes += int64(len(content.Compressed))
+		case record.Revision != nil:
+			revision := *record.Revision
+			c.revisions[revision.GUID] = append(c.revisions[revision.GUID], revision)
+			c.bytes += revisionsSize([]SummaryRevision{revision})
+			c.revision = max(c.revision, revision.ID)
+		case record.Feed != nil:
+			feed := *record.Feed
+			c.feeds[feed.URL] = feed
+			c.feed = max(c.feed, feed.ID)
+		case record.Delivery != nil:
+			delivery := *record.Delivery
+			if c.deliveries[delivery.GUID] == nil {
+				c.deliveries[delivery.GUID] = map[string]Delivery{}
+			}
+			c.deliveries[delivery.GUID][delivery.Sink] = delivery
+			c.delivery = max(c.delivery, delivery.ID)
+		case record.Digest != nil:
+			c.digests = append(c.digests, *record.Digest)
+			c.digest = max(c.digest, record.Digest.ID)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read records after line %d: %w", line, err)
+	}
+	c.evict()
+
+	v(c.verbose, "memCache - loaded %d items from snapshot", items)
+
+	return nil
+}
+
+// collect records of the memory cache for a snapshot
+func (c *memCache) snapshotRecords() (records []snapshotRecord) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	items := slices.SortedFunc(maps.Values(c.items), func(a, b CachedItem) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.GUID, b.GUID))
+	})
+	for _, item := range items {
+		records = append(records, snapshotRecord{Item: &item})
+	}
+	for _, guid := range slices.Sorted(maps.Keys(c.contents)) {
+		content := c.contents[guid]
+		records = append(records, snapshotRecord{Content: &content})
+	}
+	for _, guid := range slices.Sorted(maps.Keys(c.revisions)) {
+		for _, revision := range c.revisions[guid] {
+			records = append(records, snapshotRecord{Revision: &revision})
+		}
+	}
+	feeds := slices.SortedFunc(maps.Values(c.feeds), func(a, b Feed) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	for _, feed := range feeds {
+		records = append(records, snapshotRecord{Feed: &feed})
+	}
+	for _, guid := range slices.Sorted(maps.Keys(c.deliveries)) {
+		for _, sink := range slices.Sorted(maps.Keys(c.deliveries[guid])) {
+			delivery := c.deliveries[guid][sink]
+			records = append(records, snapshotRecord{Delivery: &delivery})
+		}
+	}
+	for _, digest := range c.digests {
+		records = append(records, snapshotRecord{Digest: &digest})
+	}
+
+	return records
+}
+
+// save the snapshot of memory cache to `path` atomically (temp file + rename)
+func (c *memCache) saveSnapshot(path string) (err error) {
+	v(c.verbose, "memCache - saving snapshot to: %s", path)
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file for snapshot: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	bw := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(bw)
+	if err = encoder.Encode(snapshotHeader{Version: snapshotVersion}); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	for _, record := range c.snapshotRecords() {
+		if err = encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to write snapshot: %w", err)
+		}
+	}
+	if err = bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err = tmp.Sync(); err != nil {
+		return fmt.Errorf("failed to sync snapshot: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close snapshot: %w", err)
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to rename snapshot to '%s': %w", path, err)
+	}
+
+	return nil
+}
+
+// start saving snapshots to `path` periodically with `interval`
+//
+// NOTE: snapshots will also be saved on `Close`.
+func (c *memCache) startSnapshots(path string, interval time.Duration) {
+	c.snapshotPath = path
+	if interval <= 0 {
+		return
+	}
+
+	c.snapshotStop = make(chan struct{})
+	c.snapshotDone = make(chan struct{})
+
+	go func() {
+		defer close(c.snapshotDone)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := c.saveSnapshot(path); err != nil {
+					log.Printf("failed to save snapshot of memory cache: %s", err)
+				}
+			case <-c.snapshotStop:
+				return
+			}
+		}
+	}()
+}
+
+// Close stops periodic snapshots and saves the last one, if configured.
+func (c *memCache) Close() (err error) {
+	c.closeOnce.Do(func() {
+		if len(c.snapshotPath) <= 0 {
+			return
+		}
+
+		if c.snapshotStop != nil {
+			close(c.snapshotStop)
+			<-c.snapshotDone
+		}
+
+		err = c.saveSnapshot(c.snapshotPath)
+	})
+	return err
+}
+
+// return a new memory cache which is loaded from and saved to a snapshot file
+func newMemCacheWithSnapshot(path string, interval time.Duration) (*memCache, error) {
+	cache := newMemCache()
+	if err := cache.loadSnapshot(path); err != nil {
+		return nil, err
+	}
+	cache.startSnapshots(path, interval)
+
+	return cache, nil
+}
@@ -0,0 +1,161 @@
+package rf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// test saving and loading snapshots of memory cache
+func TestMemCacheSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.jsonl")
+
+	client, err := NewClientWithSnapshot([]string{"key"}, nil, path, 0)
+	if err != nil {
+		t.Fatalf("failed to create client with snapshot: %s", err)
+	}
+	_ = legacyCacheOf(client).Save(testFeedItem("snap-1", "Title 1"), "Translated 1", "Summary 1")
+	_ = legacyCacheOf(client).Save(testFeedItem("snap-2", "Title 2"), "Translated 2", "Summary 2")
+	_ = legacyCacheOf(client).MarkAsRead("snap-2")
+
+	if _, err := os.Stat(path); err == nil {
+		t.Error("expected no snapshot before Close when interval is 0")
+	}
+
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close failed: %s", err)
+	}
+	if err := client.Close(); err != nil {
+		t.Errorf("second Close should be a no-op, got: %s", err)
+	}
+
+	// no temp files should be left behind
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("expected only the snapshot file, got %d entries", len(entries))
+	}
+
+	// restart
+	restarted, err := NewClientWithSnapshot([]string{"key"}, nil, path, 0)
+	if err != nil {
+		t.Fatalf("failed to restart client with snapshot: %s", err)
+	}
+	defer func() { _ = restarted.Close() }()
+
+	if items := restarted.ListCachedItems(true); len(items) != 2 {
+		t.Errorf("expected 2 items after restart, got %d", len(items))
+	}
+	if cached := legacyCacheOf(restarted).Fetch("snap-2"); cached == nil || !cached.MarkedAsRead {
+		t.Errorf("expected read state to survive restart, got %+v", cached)
+	}
+}
+
+// test periodic snapshots of memory cache
+func TestMemCacheSnapshotInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.jsonl")
+
+	cache, err := newMemCacheWithSnapshot(path, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("failed to create memCache with snapshot: %s", err)
+	}
+	defer func() { _ = cache.Close() }()
+
+	_ = cache.Save(context.Background(), ItemToCache{Item: testFeedItem("periodic-1", "Title"), Title: "Title", Summary: "Summary"})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if loaded, err := newMemCacheWithSnapshot(path, 0); err == nil {
+			if exists, _ := loaded.Exists(context.Background(), "periodic-1"); exists {
+				return
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("expected periodic snapshot to be saved")
+}
+
+// test loading a corrupt snapshot
+func TestMemCacheSnapshotCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "corrupt.jsonl")
+	if err := os.WriteFile(path, []byte("not a snapshot\n"), 0o600); err != nil {
+		t.Fatalf("failed to write file: %s", err)
+	}
+
+	if _, err := NewClientWithSnapshot(nil, nil, path, 0); err == nil {
+		t.Error("expected error for a corrupt snapshot")
+	}
+}
+
+// test saving and loading contents, revisions, feeds, deliveries, and digests with snapshots
+func TestMemCacheSnapshotRecords(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "snapshot.jsonl")
+
+	cache, err := newMemCacheWithSnapshot(path, 0)
+	if err != nil {
+		t.Fatalf("failed to create memCache with snapshot: %s", err)
+	}
+	_ = cache.Save(ctx, ItemToCache{Item: testFeedItem("records-1", "Title"), Title: "Title", Summary: "Summary"})
+	content, _ := newOriginalContent("records-1", "text/html", []byte("<p>content</p>"), time.Now())
+	_ = cache.SaveContent(ctx, content)
+	_ = cache.SaveRevision(ctx, SummaryRevision{GUID: "records-1", Title: "Title", Summary: "Summary", UsedModel: "model"})
+	_ = cache.SaveFeed(ctx, Feed{URL: "https://example.com/feed", Title: "Feed"})
+	_ = cache.SaveDelivery(ctx, Delivery{Sink: "sink", GUID: "records-1", Attempts: 1, LastError: "failed"})
+	_ = cache.SaveDigest(ctx, &Digest{Period: DigestPeriodDaily, Title: "Digest", Content: "content", ItemGUIDs: []string{"records-1"}})
+	if err := cache.Close(); err != nil {
+		t.Fatalf("Close failed: %s", err)
+	}
+
+	loaded, err := newMemCacheWithSnapshot(path, 0)
+	if err != nil {
+		t.Fatalf("failed to load snapshot: %s", err)
+	}
+	if exists, _ := loaded.Exists(ctx, "records-1"); !exists {
+		t.Error("expected item to survive restart")
+	}
+	if content, err := loaded.FetchContent(ctx, "records-1"); err != nil || content.Size != len("<p>content</p>") {
+		t.Errorf("expected content to survive restart, got %+v (%v)", content, err)
+	}
+	if revisions, _ := loaded.ListRevisions(ctx, "records-1"); len(revisions) != 1 || revisions[0].UsedModel != "model" {
+		t.Errorf("expected revision to survive restart, got %+v", revisions)
+	}
+	if feed, err := loaded.FetchFeed(ctx, "https://example.com/feed"); err != nil || feed.Title != "Feed" {
+		t.Errorf("expected feed to survive restart, got %+v (%v)", feed, err)
+	}
+
+	if deliveries, _ := loaded.ListDeliveries(ctx, "sink"); len(deliveries) != 1 || deliveries[0].Attempts != 1 {
+		t.Errorf("expected delivery to survive restart, got %+v", deliveries)
+	}
+	if digests, _ := loaded.ListDigests(ctx); len(digests) != 1 || digests[0].Title != "Digest" || len(digests[0].ItemGUIDs) != 1 {
+		t.Errorf("expected digest to survive restart, got %+v", digests)
+	}
+
+	// ids should not collide with restored ones
+	_ = loaded.SaveFeed(ctx, Feed{URL: "https://example.com/other"})
+	if feeds, _ := loaded.ListFeeds(ctx); len(feeds) != 2 || feeds[0].ID == feeds[1].ID {
+		t.Errorf("expected 2 feeds with distinct ids, got %+v", feeds)
+	}
+	digest := Digest{Period: DigestPeriodDaily, Title: "Other"}
+	_ = loaded.SaveDigest(ctx, &digest)
+	if digest.ID != 2 {
+		t.Errorf("expected new digest id 2, got %d", digest.ID)
+	}
+}
+
+// test loading a snapshot of version 1 (cached items only)
+func TestMemCacheSnapshotVersion1(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.jsonl")
+	if err := os.WriteFile(path, []byte(`{"GUID":"legacy-1","Title":"Title"}`+"\n"), 0o600); err != nil {
+		t.Fatalf("failed to write file: %s", err)
+	}
+
+	cache, err := newMemCacheWithSnapshot(path, 0)
+	if err != nil {
+		t.Fatalf("failed to load snapshot of version 1: %s", err)
+	}
+	if exists, _ := cache.Exists(context.Background(), "legacy-1"); !exists {
+		t.Error("expected item from snapshot of version 1")
+	}
+}
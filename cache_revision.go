@@ -0,0 +1,191 @@
+package rf
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	gt "github.com/meinside/gemini-things-go"
+	ssg "github.com/meinside/simple-scrapper-go"
+)
+
+// SummaryRevision is a struct for a revision of a cached item's (translated) title and summary
+type SummaryRevision struct {
+	gorm.Model
+
+	GUID string `gorm:"index"`
+
+	Title   string
+	Summary string
+
+	UsedModel     string // model used for the summary (empty if unknown)
+	PromptVersion int
+	Language      string
+
+	Usage TokenUsage `gorm:"embedded;embeddedPrefix:usage_"`
+}
+
+// TokenUsage is a struct for the token usage of a summary
+type TokenUsage struct {
+	PromptTokens     int32
+	CandidatesTokens int32
+	TotalTokens      int32
+}
+
+// approximate size of given revisions in bytes
+func revisionsSize(revisions []SummaryRevision) (size int64) {
+	for _, revision := range revisions {
+		size += int64(len(revision.Title) + len(revision.Summary) + len(revision.UsedModel) + len(revision.Language))
+	}
+	return size
+}
+
+// ResummarizeOptions is a struct for the options of `ResummarizeItems`
+type ResummarizeOptions struct {
+	Models   []string // models to use instead of the client's (optional)
+	Language string   // language to use instead of the client's (optional)
+}
+
+// ResummarizeItems regenerates the summaries of cached items with given `guids`,
+// keeping the previous ones as revisions.
+//
+// Stored original contents are reused if there are any, otherwise they are fetched again.
+//
+// If a summary fails, the cached item is left untouched and the error is reported in its result.
+// If there was a retriable error(eg. model overloads), remaining items are reported with the same error.
+func (c *Client) ResummarizeItems(
+	ctx context.Context,
+	guids []string,
+	opts ResummarizeOptions,
+	urlScrapper ...*ssg.Scrapper,
+) BatchResults {
+	settings := c.summarySettings(opts.Models, opts.Language)
+
+	results := make(BatchResults, 0, len(guids))
+	for i, guid := range guids {
+		err := c.resummarize(ctx, settings, guid, urlScrapper...)
+		results = append(results, BatchResult{GUID: guid, Err: err})
+
+		if err != nil && gt.IsModelOverloaded(err) {
+			v(c.verbose, "skipping remaining items due to overloaded model")
+
+			for _, remaining := range guids[i+1:] {
+				results = append(results, BatchResult{GUID: remaining, Err: err})
+			}
+			break
+		}
+
+		// sleep for a while
+		if i < len(guids)-1 {
+			time.Sleep(time.Duration(c.summarizeIntervalSeconds) * time.Second)
+		}
+	}
+
+	return results
+}
+
+// resummarize the cached item with given `guid` with `settings`
+func (c *Client) resummarize(
+	ctx context.Context,
+	settings summarySettings,
+	guid string,
+	urlScrapper ...*ssg.Scrapper,
+) error {
+	item, err := c.cache.Fetch(ctx, guid)
+	if err != nil {
+		return err
+	}
+	title := cmp.Or(item.OriginalTitle, item.Title)
+
+	// context with timeout
+	itemCtx, cancel := context.WithTimeout(ctx, summarizeTimeoutSeconds*time.Second)
+	defer cancel()
+
+	var summarized summaryResult
+	stored, err := c.cache.FetchContent(ctx, guid)
+	if err == nil {
+		v(c.verbose, "resummarizing stored original content of '%s'", guid)
+
+		fetched, err := stored.Content()
+		if err != nil {
+			return err
+		}
+		summarized, err = c.summarizeFetched(itemCtx, settings, title, item.Link, fetched, stored.ContentType, stored.FetchedAt)
+		if err != nil {
+			return err
+		}
+		summarized.fetched = nil // (already stored)
+	} else if errors.Is(err, ErrNotFound) {
+		if summarized, err = c.summarizeWith(itemCtx, settings, title, item.Link, urlScrapper...); err != nil {
+			return err
+		}
+	} else {
+		return err
+	}
+
+	translatedTitle := strings.TrimSpace(summarized.translatedTitle)
+	summarizedContent := strings.TrimSpace(appendSummaryFooter(summarized.summarizedContent, summarized.usedModel))
+
+	if err := c.cache.UpdateSummary(ctx, guid, translatedTitle, summarizedContent); err != nil {
+		return err
+	}
+	if err := c.cache.SaveRevision(ctx, c.newSummaryRevision(guid, translatedTitle, summarizedContent, settings.language, summarized)); err != nil {
+		return err
+	}
+
+	// store the original content, (if enabled)
+	if c.storeOriginalContent && len(summarized.fetched) > 0 {
+		if err := c.saveOriginalContent(ctx, guid, summarized); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListRevisions lists the revisions of the cached item with given `guid`, newest first.
+func (c *Client) ListRevisions(ctx context.Context, guid string) ([]SummaryRevision, error) {
+	revisions, err := c.cache.ListRevisions(ctx, guid)
+	if err != nil {
+		return nil, err
+	}
+	for i := range revisions {
+		revisions[i].Summary = redactText(revisions[i].Summary, c.googleAIAPIKeys)
+	}
+	return revisions, nil
+}
+
+// RollbackToRevision restores the title and summary of the cached item with given `guid`
+// from its revision with `revisionID`.
+//
+// It returns an error wrapping `ErrNotFound` if there is no such revision.
+func (c *Client) RollbackToRevision(ctx context.Context, guid string, revisionID uint) error {
+	revisions, err := c.cache.ListRevisions(ctx, guid)
+	if err != nil {
+		return err
+	}
+	for _, revision := range revisions {
+		if revision.ID == revisionID {
+			return c.cache.UpdateSummary(ctx, guid, revision.Title, revision.Summary)
+		}
+	}
+	return fmt.Errorf("%w: revision %d of '%s'", ErrNotFound, revisionID, guid)
+}
+
+// build a revision of given summary result
+func (c *Client) newSummaryRevision(guid, title, summary, language string, summarized summaryResult) SummaryRevision {
+	return SummaryRevision{
+		GUID:          guid,
+		Title:         title,
+		Summary:       summary,
+		UsedModel:     summarized.usedModel,
+		PromptVersion: summarizePromptVersion,
+		Language:      language,
+		Usage:         summarized.usage,
+	}
+}
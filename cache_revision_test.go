@@ -0,0 +1,136 @@
+package rf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// test revisions of summaries in caches
+func TestCacheRevisions(t *testing.T) {
+	ctx := context.Background()
+
+	dbc, err := newDBCache(filepath.Join(t.TempDir(), "revision.db"))
+	if err != nil {
+		t.Fatalf("failed to create dbCache: %s", err)
+	}
+
+	for name, cache := range map[string]FeedsItemsCacheV2{
+		"mem": newMemCache(),
+		"db":  dbc,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := cache.UpdateSummary(ctx, "revision-1", "Title", "Summary"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected ErrNotFound, got %v", err)
+			}
+
+			_ = cache.Save(ctx, ItemToCache{Item: testFeedItem("revision-1", "Original Title"), Title: "First Title", Summary: "First Summary"})
+
+			for i, summary := range []string{"First Summary", "Second Summary"} {
+				if err := cache.SaveRevision(ctx, SummaryRevision{
+					GUID:          "revision-1",
+					Title:         "Title",
+					Summary:       summary,
+					UsedModel:     "model",
+					PromptVersion: summarizePromptVersion,
+					Language:      "English",
+					Usage:         TokenUsage{TotalTokens: int32(i + 1)},
+				}); err != nil {
+					t.Fatalf("SaveRevision failed: %s", err)
+				}
+			}
+
+			revisions, err := cache.ListRevisions(ctx, "revision-1")
+			if err != nil {
+				t.Fatalf("ListRevisions failed: %s", err)
+			}
+			if len(revisions) != 2 {
+				t.Fatalf("expected 2 revisions, got %d", len(revisions))
+			}
+			if revisions[0].Summary != "Second Summary" || revisions[0].Usage.TotalTokens != 2 {
+				t.Errorf("expected the newest revision first, got %+v", revisions[0])
+			}
+
+			if err := cache.UpdateSummary(ctx, "revision-1", "Updated Title", "Updated Summary"); err != nil {
+				t.Fatalf("UpdateSummary failed: %s", err)
+			}
+			if fetched, _ := cache.Fetch(ctx, "revision-1"); fetched == nil || fetched.Summary != "Updated Summary" || fetched.OriginalTitle != "Original Title" {
+				t.Errorf("unexpected cached item after update: %+v", fetched)
+			}
+
+			// deleted along with its item
+			if err := cache.DeleteMany(ctx, []string{"revision-1"}).Err(); err != nil {
+				t.Fatalf("DeleteMany failed: %s", err)
+			}
+			if revisions, _ := cache.ListRevisions(ctx, "revision-1"); len(revisions) != 0 {
+				t.Errorf("expected revisions to be deleted, got %d", len(revisions))
+			}
+		})
+	}
+}
+
+// test rolling back to a revision
+func TestRollbackToRevision(t *testing.T) {
+	ctx := context.Background()
+
+	client := NewClient([]string{"api-key"}, nil)
+
+	_ = client.cache.Save(ctx, ItemToCache{Item: testFeedItem("rollback-1", "Title"), Title: "Title", Summary: "Newer Summary"})
+	_ = client.cache.SaveRevision(ctx, SummaryRevision{GUID: "rollback-1", Title: "Older Title", Summary: "Older Summary with api-key"})
+	_ = client.cache.SaveRevision(ctx, SummaryRevision{GUID: "rollback-1", Title: "Title", Summary: "Newer Summary"})
+
+	revisions, err := client.ListRevisions(ctx, "rollback-1")
+	if err != nil {
+		t.Fatalf("ListRevisions failed: %s", err)
+	}
+	older := revisions[len(revisions)-1]
+	if older.Summary != "Older Summary with "+redacted {
+		t.Errorf("expected api key to be redacted, got %q", older.Summary)
+	}
+
+	if err := client.RollbackToRevision(ctx, "rollback-1", older.ID); err != nil {
+		t.Fatalf("RollbackToRevision failed: %s", err)
+	}
+	if fetched, _ := client.cache.Fetch(ctx, "rollback-1"); fetched == nil || fetched.Title != "Older Title" || fetched.Summary != "Older Summary with api-key" {
+		t.Errorf("unexpected cached item after rollback: %+v", fetched)
+	}
+
+	if err := client.RollbackToRevision(ctx, "rollback-1", 9999); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown revision, got %v", err)
+	}
+}
+
+// test overriding models and language for re-summarizing
+func TestSummarySettings(t *testing.T) {
+	client := NewClient([]string{"key1", "key2"}, nil)
+
+	settings := client.summarySettings(nil, "")
+	if settings.language != defaultDesiredLanguage || len(settings.combos) != 2 {
+		t.Errorf("expected the client's own settings without overrides, got %+v", settings)
+	}
+
+	settings = client.summarySettings([]string{"model-a", defaultGoogleAIModel}, "Korean")
+	if settings.language != "Korean" {
+		t.Errorf("expected language 'Korean', got %q", settings.language)
+	}
+	if len(settings.combos) != 4 {
+		t.Errorf("expected 4 combos, got %d", len(settings.combos))
+	}
+	if client.desiredLanguage != defaultDesiredLanguage || len(client.combos) != 2 {
+		t.Error("expected the client to be untouched")
+	}
+
+	// cooldowns are shared with the client's own combos
+	now := time.Now()
+	for _, combo := range client.combos {
+		client.markCooldown(combo, fmt.Errorf("quota exceeded"), now)
+	}
+	for range 4 {
+		if combo, _, ok := client.pickAvailableCombo(settings.combos, now); !ok || combo.model != "model-a" {
+			t.Errorf("expected combos in cooldown to be skipped, got %+v (%v)", combo, ok)
+		}
+	}
+}
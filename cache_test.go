@@ -1,7 +1,11 @@
 package rf
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -50,6 +54,35 @@ func TestNewCachedItem(t *testing.T) {
 		}
 	})
 
+	t.Run("media", func(t *testing.T) {
+		item := gofeed.Item{
+			GUID:          "guid-media",
+			UpdatedParsed: &now,
+			Image:         &gofeed.Image{URL: "https://example.com/thumbnail.jpg"},
+			Categories:    []string{"podcast", "tech"},
+			Enclosures: []*gofeed.Enclosure{
+				{URL: "https://example.com/episode.mp3", Length: "12345", Type: "audio/mpeg"},
+				nil,
+				{URL: ""}, // should be dropped
+			},
+		}
+
+		cached := newCachedItem(item, "Title", "Summary")
+
+		if cached.UpdateDate != now.Format(time.RFC3339) {
+			t.Errorf("expected UpdateDate, got %q", cached.UpdateDate)
+		}
+		if cached.ImageURL != "https://example.com/thumbnail.jpg" {
+			t.Errorf("expected ImageURL, got %q", cached.ImageURL)
+		}
+		if strings.Join(cached.Categories, ",") != "podcast,tech" {
+			t.Errorf("expected Categories, got %v", cached.Categories)
+		}
+		if len(cached.Enclosures) != 1 || cached.Enclosures[0] != (Enclosure{URL: "https://example.com/episode.mp3", Length: "12345", Type: "audio/mpeg"}) {
+			t.Errorf("expected 1 Enclosure, got %+v", cached.Enclosures)
+		}
+	})
+
 	t.Run("nil author", func(t *testing.T) {
 		item := gofeed.Item{
 			GUID:   "guid-nil-author",
@@ -112,6 +145,11 @@ func TestNewCachedItem(t *testing.T) {
 	})
 }
 
+// helper for accessing the cache of given client with the old interface
+func legacyCacheOf(client *Client) FeedsItemsCache {
+	return NewFeedsItemsCacheAdapter(client.cache)
+}
+
 // helper to create a test gofeed.Item
 func testFeedItem(guid, title string) gofeed.Item {
 	now := time.Now()
@@ -124,9 +162,21 @@ func testFeedItem(guid, title string) gofeed.Item {
 	}
 }
 
+// make all items of given memory cache older than 1 month
+func ageMemCacheItems(mc *memCache) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	for _, item := range mc.items {
+		item.CreatedAt = time.Now().Add(-31 * 24 * time.Hour)
+		mc.put(item)
+	}
+}
+
 // test memCache operations
 func TestMemCache(t *testing.T) {
-	cache := newMemCache()
+	mc := newMemCache()
+	cache := NewFeedsItemsCacheAdapter(mc)
 
 	t.Run("Exists returns false for missing item", func(t *testing.T) {
 		if cache.Exists("nonexistent") {
@@ -194,8 +244,8 @@ func TestMemCache(t *testing.T) {
 	})
 
 	t.Run("MarkAsRead on nonexistent item", func(t *testing.T) {
-		if err := cache.MarkAsRead("nonexistent"); err != nil {
-			t.Errorf("unexpected error: %s", err)
+		if err := cache.MarkAsRead("nonexistent"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
 		}
 	})
 
@@ -235,7 +285,7 @@ func TestMemCache(t *testing.T) {
 	})
 
 	t.Run("DeleteOlderThan1Month", func(t *testing.T) {
-		// all items have zero CreatedAt (from gorm.Model), which is before 1 month ago
+		ageMemCacheItems(mc)
 		if err := cache.DeleteOlderThan1Month(); err != nil {
 			t.Fatalf("DeleteOlderThan1Month failed: %s", err)
 		}
@@ -250,10 +300,11 @@ func TestMemCache(t *testing.T) {
 // test dbCache operations
 func TestDBCache(t *testing.T) {
 	dbPath := filepath.Join(t.TempDir(), "test_cache.db")
-	cache, err := newDBCache(dbPath)
+	dbc, err := newDBCache(dbPath)
 	if err != nil {
 		t.Fatalf("failed to create dbCache: %s", err)
 	}
+	cache := NewFeedsItemsCacheAdapter(dbc)
 
 	t.Run("Exists returns false for missing item", func(t *testing.T) {
 		if cache.Exists("nonexistent") {
@@ -391,10 +442,11 @@ func TestDBCacheAutoVacuumMode(t *testing.T) {
 // test that DeleteOlderThan1Month physically deletes old rows
 func TestDeleteOlderThan1MonthPhysical(t *testing.T) {
 	dbPath := filepath.Join(t.TempDir(), "purge.db")
-	cache, err := newDBCache(dbPath)
+	dbc, err := newDBCache(dbPath)
 	if err != nil {
 		t.Fatalf("failed to create dbCache: %s", err)
 	}
+	cache := NewFeedsItemsCacheAdapter(dbc)
 
 	// save an old item (35 days ago) and a recent item (now)
 	oldItem := testFeedItem("old-guid", "Old Title")
@@ -408,7 +460,7 @@ func TestDeleteOlderThan1MonthPhysical(t *testing.T) {
 
 	// backdate old-guid's created_at to 35 days ago
 	past := time.Now().Add(-35 * 24 * time.Hour)
-	if err := cache.db.Unscoped().Model(&CachedItem{}).
+	if err := dbc.db.Unscoped().Model(&CachedItem{}).
 		Where("guid = ?", "old-guid").
 		Update("created_at", past).Error; err != nil {
 		t.Fatalf("failed to backdate old item: %s", err)
@@ -421,7 +473,7 @@ func TestDeleteOlderThan1MonthPhysical(t *testing.T) {
 
 	// verify physical delete: old-guid must be gone even when counted via Unscoped
 	var total int64
-	if err := cache.db.Unscoped().Model(&CachedItem{}).Count(&total).Error; err != nil {
+	if err := dbc.db.Unscoped().Model(&CachedItem{}).Count(&total).Error; err != nil {
 		t.Fatalf("count failed: %s", err)
 	}
 	if total != 1 {
@@ -434,7 +486,7 @@ func TestDeleteOlderThan1MonthPhysical(t *testing.T) {
 	}
 	// old item is not reachable even via Unscoped
 	var oldCount int64
-	if err := cache.db.Unscoped().Model(&CachedItem{}).
+	if err := dbc.db.Unscoped().Model(&CachedItem{}).
 		Where("guid = ?", "old-guid").Count(&oldCount).Error; err != nil {
 		t.Fatalf("count old failed: %s", err)
 	}
@@ -442,3 +494,253 @@ func TestDeleteOlderThan1MonthPhysical(t *testing.T) {
 		t.Errorf("expected old item physically deleted, but %d found via Unscoped", oldCount)
 	}
 }
+
+// test batch operations of memCache
+func TestMemCacheBatch(t *testing.T) {
+	cache := NewFeedsItemsCacheAdapter(newMemCache())
+
+	results := cache.SaveMany([]ItemToCache{
+		{Item: testFeedItem("batch-1", "Title 1"), Title: "Translated 1", Summary: "Summary 1"},
+		{Item: testFeedItem("batch-2", "Title 2"), Title: "Translated 2", Summary: "Summary 2"},
+		{Item: testFeedItem("batch-3", "Title 3"), Title: "Translated 3", Summary: "Summary 3"},
+	})
+	if err := results.Err(); err != nil {
+		t.Fatalf("SaveMany failed: %s", err)
+	}
+	if len(results.Succeeded()) != 3 {
+		t.Errorf("expected 3 succeeded items, got %d", len(results.Succeeded()))
+	}
+
+	results = cache.MarkManyAsRead([]string{"batch-1", "batch-2"})
+	if err := results.Err(); err != nil {
+		t.Fatalf("MarkManyAsRead failed: %s", err)
+	}
+	if items := cache.List(false); len(items) != 1 || items[0].GUID != "batch-3" {
+		t.Errorf("expected only 'batch-3' to be unread, got %+v", items)
+	}
+
+	results = cache.DeleteMany([]string{"batch-1", "batch-3"})
+	if err := results.Err(); err != nil {
+		t.Fatalf("DeleteMany failed: %s", err)
+	}
+	if items := cache.List(true); len(items) != 1 || items[0].GUID != "batch-2" {
+		t.Errorf("expected only 'batch-2' to remain, got %+v", items)
+	}
+}
+
+// test batch operations of dbCache
+func TestDBCacheBatch(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "batch.db")
+	dbc, err := newDBCache(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create dbCache: %s", err)
+	}
+	cache := NewFeedsItemsCacheAdapter(dbc)
+
+	var items []ItemToCache
+	for i := range 100 {
+		guid := fmt.Sprintf("db-batch-%d", i)
+		items = append(items, ItemToCache{Item: testFeedItem(guid, guid), Title: guid, Summary: "summary"})
+	}
+	if err := cache.SaveMany(items).Err(); err != nil {
+		t.Fatalf("SaveMany failed: %s", err)
+	}
+	if listed := cache.List(false); len(listed) != 100 {
+		t.Errorf("expected 100 unread items, got %d", len(listed))
+	}
+
+	t.Run("MarkManyAsRead reports per-item results", func(t *testing.T) {
+		results := cache.MarkManyAsRead([]string{"db-batch-0", "nonexistent", "db-batch-1"})
+		if len(results) != 3 {
+			t.Fatalf("expected 3 results, got %d", len(results))
+		}
+		if results[0].Err != nil || results[2].Err != nil {
+			t.Errorf("expected existing items to succeed, got %+v", results)
+		}
+		if results[1].Err == nil {
+			t.Error("expected nonexistent item to fail")
+		}
+		if results.Err() == nil {
+			t.Error("expected joined error")
+		}
+
+		// other items in the same transaction are still committed
+		for _, guid := range []string{"db-batch-0", "db-batch-1"} {
+			if cached := cache.Fetch(guid); cached == nil || !cached.MarkedAsRead {
+				t.Errorf("expected '%s' to be marked as read", guid)
+			}
+		}
+	})
+
+	t.Run("DeleteMany", func(t *testing.T) {
+		results := cache.DeleteMany([]string{"db-batch-0", "db-batch-1", "nonexistent"})
+		if got := results.Succeeded(); len(got) != 2 {
+			t.Errorf("expected 2 succeeded items, got %v", got)
+		}
+
+		var total int64
+		if err := dbc.db.Unscoped().Model(&CachedItem{}).Count(&total).Error; err != nil {
+			t.Fatalf("count failed: %s", err)
+		}
+		if total != 98 {
+			t.Errorf("expected 98 rows remaining, got %d", total)
+		}
+	})
+}
+
+// test LRU eviction of memCache with limits
+func TestMemCacheLimits(t *testing.T) {
+	t.Run("max items prefers read items", func(t *testing.T) {
+		mc := newMemCache()
+		mc.setLimits(3, 0)
+		cache := NewFeedsItemsCacheAdapter(mc)
+
+		for _, guid := range []string{"lru-1", "lru-2", "lru-3"} {
+			_ = cache.Save(testFeedItem(guid, guid), guid, "summary")
+		}
+		_ = cache.MarkAsRead("lru-3") // most recently saved, but read
+
+		_ = cache.Save(testFeedItem("lru-4", "lru-4"), "lru-4", "summary")
+
+		if cache.Exists("lru-3") {
+			t.Error("expected read item to be evicted first")
+		}
+		for _, guid := range []string{"lru-1", "lru-2", "lru-4"} {
+			if !cache.Exists(guid) {
+				t.Errorf("expected unread item '%s' to remain", guid)
+			}
+		}
+
+		// lru-1 is used recently, so lru-2 is the least recently used one
+		_ = cache.Fetch("lru-1")
+		_ = cache.Save(testFeedItem("lru-5", "lru-5"), "lru-5", "summary")
+		if cache.Exists("lru-2") {
+			t.Error("expected least recently used item to be evicted")
+		}
+		if !cache.Exists("lru-1") {
+			t.Error("expected recently fetched item to remain")
+		}
+
+		stats := mc.stats()
+		if stats.Items != 3 || stats.Evictions != 2 || stats.EvictedUnread != 1 {
+			t.Errorf("unexpected stats: %+v", stats)
+		}
+	})
+
+	t.Run("max bytes", func(t *testing.T) {
+		mc := newMemCache()
+		cache := NewFeedsItemsCacheAdapter(mc)
+
+		big := strings.Repeat("x", 1000)
+		for i := range 10 {
+			guid := fmt.Sprintf("bytes-%d", i)
+			_ = cache.Save(testFeedItem(guid, guid), guid, big)
+		}
+		mc.setLimits(0, 3500)
+
+		stats := mc.stats()
+		if stats.Bytes > 3500 {
+			t.Errorf("expected bytes within limit, got %d", stats.Bytes)
+		}
+		if stats.Items != 3 {
+			t.Errorf("expected 3 items to remain, got %d", stats.Items)
+		}
+		if stats.EvictedBytes <= 0 {
+			t.Errorf("expected evicted bytes to be counted, got %d", stats.EvictedBytes)
+		}
+
+		// bytes are tracked on delete
+		ageMemCacheItems(mc)
+		_ = cache.DeleteOlderThan1Month()
+		if stats := mc.stats(); stats.Items != 0 || stats.Bytes != 0 {
+			t.Errorf("expected empty cache, got %+v", stats)
+		}
+	})
+
+	t.Run("client", func(t *testing.T) {
+		client := NewClient(nil, nil)
+		if err := client.SetMemCacheLimits(1, 0); err != nil {
+			t.Fatalf("SetMemCacheLimits failed: %s", err)
+		}
+		_ = legacyCacheOf(client).Save(testFeedItem("c-1", "c-1"), "c-1", "summary")
+		_ = legacyCacheOf(client).Save(testFeedItem("c-2", "c-2"), "c-2", "summary")
+
+		stats, ok := client.MemCacheStats()
+		if !ok || stats.Items != 1 || stats.Evictions != 1 {
+			t.Errorf("unexpected stats: %+v (ok = %v)", stats, ok)
+		}
+
+		dbClient, err := NewClientWithDB(nil, nil, filepath.Join(t.TempDir(), "limits.db"))
+		if err != nil {
+			t.Fatalf("failed to create client with DB: %s", err)
+		}
+		if err := dbClient.SetMemCacheLimits(1, 0); err == nil {
+			t.Error("expected error for DB cache")
+		}
+	})
+}
+
+// test typed errors of `FeedsItemsCacheV2`
+func TestCacheV2Errors(t *testing.T) {
+	ctx := context.Background()
+
+	dbc, err := newDBCache(filepath.Join(t.TempDir(), "v2.db"))
+	if err != nil {
+		t.Fatalf("failed to create dbCache: %s", err)
+	}
+
+	for name, cache := range map[string]FeedsItemsCacheV2{
+		"mem": newMemCache(),
+		"db":  dbc,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := cache.Fetch(ctx, "nonexistent"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected ErrNotFound, got %v", err)
+			}
+			if exists, err := cache.Exists(ctx, "nonexistent"); err != nil || exists {
+				t.Errorf("expected (false, nil), got (%v, %v)", exists, err)
+			}
+			if items, err := cache.List(ctx, true); err != nil || len(items) != 0 {
+				t.Errorf("expected an empty list, got (%v, %v)", items, err)
+			}
+
+			// feed urls are not overwritten with empty ones
+			_ = cache.Save(ctx, ItemToCache{Item: testFeedItem("with-feed", "Title"), Title: "Title", Summary: "Summary", FeedURL: "https://example.com/feed"})
+			_ = cache.Save(ctx, ItemToCache{Item: testFeedItem("with-feed", "Title"), Title: "Title", Summary: "Updated"})
+			if cached, err := cache.Fetch(ctx, "with-feed"); err != nil || cached.FeedURL != "https://example.com/feed" || cached.Summary != "Updated" {
+				t.Errorf("expected feed url to be kept, got %+v (%v)", cached, err)
+			}
+			_ = cache.DeleteMany(ctx, []string{"with-feed"})
+
+			// unknown guids
+			if err := cache.MarkAsRead(ctx, "nonexistent"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected ErrNotFound from MarkAsRead, got %v", err)
+			}
+			_ = cache.Save(ctx, ItemToCache{Item: testFeedItem("existent", "Title"), Title: "Title", Summary: "Summary"})
+			for op, results := range map[string]BatchResults{
+				"MarkManyAsRead": cache.MarkManyAsRead(ctx, []string{"existent", "nonexistent"}),
+				"DeleteMany":     cache.DeleteMany(ctx, []string{"existent", "nonexistent"}),
+			} {
+				if len(results) != 2 || results[0].Err != nil || !errors.Is(results[1].Err, ErrNotFound) {
+					t.Errorf("expected ErrNotFound only for unknown guid from %s, got %+v", op, results)
+				}
+			}
+		})
+	}
+
+	t.Run("unavailable db", func(t *testing.T) {
+		if err := dbc.Close(); err != nil {
+			t.Fatalf("failed to close db: %s", err)
+		}
+
+		if _, err := dbc.Exists(ctx, "any"); !errors.Is(err, ErrUnavailable) {
+			t.Errorf("expected ErrUnavailable from Exists, got %v", err)
+		}
+		if _, err := dbc.Fetch(ctx, "any"); !errors.Is(err, ErrUnavailable) {
+			t.Errorf("expected ErrUnavailable from Fetch, got %v", err)
+		}
+		if _, err := dbc.List(ctx, false); !errors.Is(err, ErrUnavailable) {
+			t.Errorf("expected ErrUnavailable from List, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package rf
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// number of bytes to be sniffed for detecting character encodings
+const sniffSize = 1024
+
+var (
+	reXMLDeclaration = regexp.MustCompile(`^\s*<\?xml[^>]*?\?>`)
+	reXMLEncoding    = regexp.MustCompile(`\s+encoding\s*=\s*["'][^"']*["']`)
+
+	bomUTF8 = []byte{0xEF, 0xBB, 0xBF}
+)
+
+// return a reader of given article content, transcoded to UTF-8
+//
+// The encoding is detected from the BOM, `charset` of `contentType`, and `<meta>` tags of HTML documents (in this order).
+// If it was not detected, the content is regarded as UTF-8.
+func articleReader(r io.Reader, contentType string) io.Reader {
+	br := bufio.NewReaderSize(r, sniffSize)
+
+	enc, name := detectEncoding(br, contentType)
+	if enc == nil && isHTMLContent(contentType) {
+		head, _ := br.Peek(sniffSize)
+
+		var certain bool
+		if enc, name, certain = charset.DetermineEncoding(head, contentType); !certain && name == "windows-1252" && utf8.Valid(trimIncompleteRune(head)) {
+			enc = nil // (not declared in `<meta>` tags, but the fallback of `DetermineEncoding`)
+		}
+	}
+	if enc == nil || name == "utf-8" {
+		skipUTF8BOM(br)
+		return br
+	}
+	return transform.NewReader(br, enc.NewDecoder())
+}
+
+// return a reader of given feed document, transcoded to UTF-8
+//
+// The encoding is detected from the BOM and `charset` of `contentType`.
+// If neither of them exist, the document is returned as-is,
+// (the encoding in the XML declaration will be handled by the feed parser)
+// otherwise the encoding in the XML declaration is removed for not being decoded twice.
+func feedReader(r io.Reader, contentType string) (io.Reader, error) {
+	br := bufio.NewReader(r)
+
+	enc, name := detectEncoding(br, contentType)
+	if enc == nil {
+		return br, nil
+	}
+
+	var decoded *bufio.Reader
+	if name == "utf-8" {
+		skipUTF8BOM(br)
+		decoded = br
+	} else {
+		// transcode, (BOM is removed by the decoder)
+		decoded = bufio.NewReader(transform.NewReader(br, enc.NewDecoder()))
+	}
+
+	// and remove the encoding in the XML declaration
+	head, err := decoded.Peek(512)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, fmt.Errorf("failed to decode '%s' document: %w", name, err)
+	}
+	if loc := reXMLDeclaration.FindIndex(head); loc != nil {
+		declaration := reXMLEncoding.ReplaceAll(head[:loc[1]], nil)
+		_, _ = decoded.Discard(loc[1])
+
+		return io.MultiReader(bytes.NewReader(declaration), decoded), nil
+	}
+	return decoded, nil
+}
+
+// detect the encoding of a document from its BOM and `contentType`
+//
+// (returns nil if it was not detected)
+func detectEncoding(br *bufio.Reader, contentType string) (enc encoding.Encoding, name string) {
+	head, _ := br.Peek(3)
+	switch {
+	case bytes.HasPrefix(head, bomUTF8):
+		return encoding.Nop, "utf-8"
+	case bytes.HasPrefix(head, []byte{0xFE, 0xFF}):
+		return unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM), "utf-16be"
+	case bytes.HasPrefix(head, []byte{0xFF, 0xFE}):
+		return unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM), "utf-16le"
+	}
+
+	if _, params, err := mime.ParseMediaType(contentType); err == nil {
+		if label, exists := params["charset"]; exists {
+			if enc, name = charset.Lookup(strings.TrimSpace(label)); enc != nil {
+				return enc, name
+			}
+		}
+	}
+	return nil, ""
+}
+
+// skip the UTF-8 BOM, if any
+func skipUTF8BOM(br *bufio.Reader) {
+	if head, _ := br.Peek(len(bomUTF8)); bytes.Equal(head, bomUTF8) {
+		_, _ = br.Discard(len(bomUTF8))
+	}
+}
+
+// check if given content type is of HTML documents
+func isHTMLContent(contentType string) bool {
+	return strings.HasPrefix(contentType, "text/html") ||
+		strings.HasPrefix(contentType, "application/xhtml")
+}
@@ -0,0 +1,127 @@
+package rf
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// encode given string with `enc`
+func mustEncode(t *testing.T, enc encoding.Encoding, s string) []byte {
+	encoded, err := enc.NewEncoder().Bytes([]byte(s))
+	if err != nil {
+		t.Fatalf("failed to encode: %s", err)
+	}
+	return encoded
+}
+
+// test reading non-UTF-8 feeds
+func TestFeedReader(t *testing.T) {
+	const title = "한국어 피드"
+	const feedFormat = `<?xml version="1.0" encoding="%s"?><rss version="2.0"><channel><title>` + title + `</title></channel></rss>`
+
+	for name, tc := range map[string]struct {
+		body        []byte
+		contentType string
+	}{
+		"header charset": {
+			body:        mustEncode(t, korean.EUCKR, strings.ReplaceAll(feedFormat, "%s", "EUC-KR")),
+			contentType: "application/rss+xml; charset=EUC-KR",
+		},
+		"header charset without XML declaration": {
+			body:        mustEncode(t, korean.EUCKR, strings.ReplaceAll(feedFormat, `<?xml version="1.0" encoding="%s"?>`, "")),
+			contentType: "application/rss+xml; charset=euc-kr",
+		},
+		"XML declaration only": {
+			body:        mustEncode(t, korean.EUCKR, strings.ReplaceAll(feedFormat, "%s", "EUC-KR")),
+			contentType: "application/rss+xml",
+		},
+		"UTF-8 BOM": {
+			body:        append(append([]byte{}, bomUTF8...), strings.ReplaceAll(feedFormat, "%s", "UTF-8")...),
+			contentType: "application/rss+xml",
+		},
+		"UTF-8 header charset with mismatched XML declaration": {
+			body:        []byte(strings.ReplaceAll(feedFormat, "%s", "EUC-KR")),
+			contentType: "application/rss+xml; charset=utf-8",
+		},
+		"UTF-8 BOM with mismatched XML declaration": {
+			body:        append(append([]byte{}, bomUTF8...), strings.ReplaceAll(feedFormat, "%s", "ISO-8859-1")...),
+			contentType: "application/rss+xml",
+		},
+		"UTF-16 BOM": {
+			body:        mustEncode(t, unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), strings.ReplaceAll(feedFormat, "%s", "UTF-16")),
+			contentType: "application/rss+xml",
+		},
+	} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", tc.contentType)
+			_, _ = w.Write(tc.body)
+		}))
+
+		client := NewClient([]string{"key"}, []string{server.URL})
+		if feeds, err := client.FetchFeeds(context.Background(), false, 7); err != nil {
+			t.Errorf("[%s] failed to fetch feeds: %s", name, err)
+		} else if len(feeds) != 1 || feeds[0].Title != title {
+			t.Errorf("[%s] expected title '%s', got %+v", name, title, feeds)
+		}
+
+		server.Close()
+	}
+}
+
+// test reading non-UTF-8 articles
+func TestArticleReader(t *testing.T) {
+	const text = "日本語の記事です"
+
+	for name, tc := range map[string]struct {
+		body        []byte
+		contentType string
+	}{
+		"header charset": {
+			body:        mustEncode(t, japanese.ShiftJIS, text),
+			contentType: "text/plain; charset=Shift_JIS",
+		},
+		"meta tag": {
+			body:        mustEncode(t, japanese.ShiftJIS, `<html><head><meta charset="Shift_JIS"></head><body>`+text+`</body></html>`),
+			contentType: "text/html",
+		},
+		"meta http-equiv": {
+			body:        mustEncode(t, japanese.EUCJP, `<html><head><meta http-equiv="Content-Type" content="text/html; charset=EUC-JP"></head><body>`+text+`</body></html>`),
+			contentType: "text/html",
+		},
+		"undeclared UTF-8": {
+			body:        []byte(`<html><body>` + text + `</body></html>`),
+			contentType: "text/html",
+		},
+		"UTF-8 BOM": {
+			body:        append(append([]byte{}, bomUTF8...), text...),
+			contentType: "text/plain",
+		},
+	} {
+		if read, err := io.ReadAll(articleReader(strings.NewReader(string(tc.body)), tc.contentType)); err != nil {
+			t.Errorf("[%s] failed to read: %s", name, err)
+		} else if !strings.Contains(string(read), text) || strings.HasPrefix(string(read), string(bomUTF8)) {
+			t.Errorf("[%s] expected '%s' in the transcoded content, got %q", name, text, read)
+		}
+	}
+
+	// fetched content
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write(mustEncode(t, korean.EUCKR, `<html><head><meta charset="euc-kr"></head><body>한국어 기사</body></html>`))
+	}))
+	defer server.Close()
+
+	content, _, err := fetchURLContent(context.Background(), defaultHTTPClient, server.URL, fakeUserAgent, ContentSizeLimits{}, false)
+	if err != nil || !strings.Contains(string(content), "한국어 기사") {
+		t.Errorf("expected transcoded content, got %q (%v)", content, err)
+	}
+}
@@ -2,12 +2,12 @@
 package rf
 
 import (
+	"cmp"
 	"context"
-	"encoding/xml"
 	"errors"
 	"fmt"
-	"html"
 	"io"
+	"log"
 	"net/http"
 	"slices"
 	"strings"
@@ -15,7 +15,6 @@ import (
 	"sync/atomic"
 	"time"
 
-	"github.com/gorilla/feeds"
 	"github.com/mmcdole/gofeed"
 
 	gt "github.com/meinside/gemini-things-go"
@@ -31,28 +30,61 @@ const (
 	maxRetryCount = 3
 
 	defaultCooldownSeconds = 60 // fallback cooldown when retryDelay is missing
+
+	defaultFeedBackoffBase = 5 * time.Minute // backoff after the first failure of a feed (doubled on each failure)
+	defaultFeedBackoffMax  = 24 * time.Hour
+	defaultDeadFeedDays    = 30 // feeds failing for this many days will be disabled
 )
 
 const (
 	ErrorPrefixSummaryFailedWithError = `Summary failed with error`
 
-	PublishContentType = `application/rss+xml`
+	PublishContentType     = `application/rss+xml`
+	PublishContentTypeAtom = `application/atom+xml`
+	PublishContentTypeJSON = `application/feed+json`
 )
 
 // Client struct
 type Client struct {
-	feedsURLs []string
-	cache     FeedsItemsCache
+	feedsURLs []string // (guarded by `stateMu`)
+	cache     FeedsItemsCacheV2
 
 	googleAIAPIKeys []string
 	googleAIModels  []string
 
 	desiredLanguage          string
 	summarizeIntervalSeconds int
+	storeOriginalContent     bool
 	verbose                  bool
 
+	feedBackoffBase   time.Duration
+	feedBackoffMax    time.Duration
+	deadFeedDays      uint
+	feedMovedCallback func(oldURL, newURL string)
+
+	httpClient  *http.Client // nil for `defaultHTTPClient`
+	userAgent   string
+	feedOptions map[string]FeedOptions // per-feed options of http requests (key: url, guarded by `stateMu`)
+
+	safeDial       *SafeDialPolicy
+	safeHTTPClient *http.Client // `httpClient` wrapped with `safeDial` (nil if not in safe-dial mode)
+
+	contentSizeLimits ContentSizeLimits
+
+	customPublishedFeeds []PublishedFeed
+
+	sinks     []Sink
+	deliverMu sync.Mutex // serializes deliveries to sinks
+
+	webSub          *WebSubOptions // nil if not subscribing to websub hubs (guarded by `stateMu`)
+	webSubHubURL    string         // websub hub to ping on new summaries (empty if not publishing)
+	webSubTopicURLs []string
+	webSubPushes    chan gofeed.Feed // queue of pushed feeds (passed to `WebSubOptions.OnPush` one by one)
+
+	stateMu sync.RWMutex // guards the states which can be changed while websub pushes are processed
+
 	combos        []keyModelCombo
-	cooldownUntil map[int]time.Time
+	cooldownUntil map[keyModelCombo]time.Time
 	cooldownMu    sync.Mutex
 
 	_numRequests atomic.Int64
@@ -68,16 +100,29 @@ type keyModelCombo struct {
 func NewClient(
 	googleAIAPIKeys []string,
 	feedsURLs []string,
+) *Client {
+	return newClient(googleAIAPIKeys, feedsURLs, newMemCache())
+}
+
+// return a new client with `cache` and default values
+func newClient(
+	googleAIAPIKeys []string,
+	feedsURLs []string,
+	cache FeedsItemsCacheV2,
 ) *Client {
 	c := &Client{
 		feedsURLs: feedsURLs,
-		cache:     newMemCache(),
+		cache:     cache,
 
 		googleAIAPIKeys: googleAIAPIKeys,
 		googleAIModels:  []string{defaultGoogleAIModel},
 
 		desiredLanguage:          defaultDesiredLanguage,
 		summarizeIntervalSeconds: defaultSummarizeIntervalSeconds,
+
+		feedBackoffBase: defaultFeedBackoffBase,
+		feedBackoffMax:  defaultFeedBackoffMax,
+		deadFeedDays:    defaultDeadFeedDays,
 	}
 	c.buildCombos()
 	return c
@@ -90,23 +135,65 @@ func NewClientWithDB(
 	dbFilepath string,
 ) (client *Client, err error) {
 	if dbCache, err := newDBCache(dbFilepath); err == nil {
-		c := &Client{
-			feedsURLs: feedsURLs,
-			cache:     dbCache,
-
-			googleAIAPIKeys: googleAIAPIKeys,
-			googleAIModels:  []string{defaultGoogleAIModel},
-
-			desiredLanguage:          defaultDesiredLanguage,
-			summarizeIntervalSeconds: defaultSummarizeIntervalSeconds,
-		}
-		c.buildCombos()
-		return c, nil
+		return newClient(googleAIAPIKeys, feedsURLs, dbCache), nil
 	} else {
 		return nil, fmt.Errorf("failed to create a client with DB: %w", err)
 	}
 }
 
+// NewClientWithSnapshot returns a new client with memory cache
+// which survives restarts with a snapshot file.
+//
+// The snapshot at `snapshotFilepath` is loaded (if it exists) on creation,
+// then saved atomically every `snapshotInterval` and on `Close`.
+// (if `snapshotInterval` is not positive, it is saved only on `Close`)
+//
+// Snapshots have cached items, their original contents, revisions and deliveries to sinks, feeds, and digests.
+func NewClientWithSnapshot(
+	googleAIAPIKeys []string,
+	feedsURLs []string,
+	snapshotFilepath string,
+	snapshotInterval time.Duration,
+) (client *Client, err error) {
+	if memCache, err := newMemCacheWithSnapshot(snapshotFilepath, snapshotInterval); err == nil {
+		return newClient(googleAIAPIKeys, feedsURLs, memCache), nil
+	} else {
+		return nil, fmt.Errorf("failed to create a client with snapshot: %w", err)
+	}
+}
+
+// Close closes the client's cache.
+//
+// (for memory cache with snapshot, the last snapshot is saved here)
+func (c *Client) Close() error {
+	return c.cache.Close()
+}
+
+// SetMemCacheLimits sets the limits of the client's memory cache.
+//
+// When the number of cached items exceeds `maxItems`, or their approximate size
+// exceeds `maxBytes`, the least recently used items (preferring the ones marked as read)
+// will be evicted. Zero means no limit.
+//
+// It returns an error if the client is not using memory cache.
+func (c *Client) SetMemCacheLimits(maxItems int, maxBytes int64) error {
+	if mc, ok := c.cache.(*memCache); ok {
+		mc.setLimits(maxItems, maxBytes)
+		return nil
+	}
+	return fmt.Errorf("not using memory cache")
+}
+
+// MemCacheStats returns the statistics of the client's memory cache.
+//
+// `ok` is false if the client is not using memory cache.
+func (c *Client) MemCacheStats() (stats MemCacheStats, ok bool) {
+	if mc, ok := c.cache.(*memCache); ok {
+		return mc.stats(), true
+	}
+	return MemCacheStats{}, false
+}
+
 // SetGoogleAIModels sets the client's Google AI models.
 func (c *Client) SetGoogleAIModels(models []string) {
 	c.googleAIModels = models
@@ -118,14 +205,42 @@ func (c *Client) buildCombos() {
 	c.cooldownMu.Lock()
 	defer c.cooldownMu.Unlock()
 
-	combos := make([]keyModelCombo, 0, len(c.googleAIAPIKeys)*len(c.googleAIModels))
-	for _, key := range c.googleAIAPIKeys {
-		for _, model := range c.googleAIModels {
+	c.combos = newKeyModelCombos(c.googleAIAPIKeys, c.googleAIModels)
+	c.cooldownUntil = map[keyModelCombo]time.Time{}
+}
+
+// return (key, model) combinations of given keys and models
+func newKeyModelCombos(keys, models []string) []keyModelCombo {
+	combos := make([]keyModelCombo, 0, len(keys)*len(models))
+	for _, key := range keys {
+		for _, model := range models {
 			combos = append(combos, keyModelCombo{apiKey: key, model: model})
 		}
 	}
-	c.combos = combos
-	c.cooldownUntil = map[int]time.Time{}
+	return combos
+}
+
+// summarySettings is a struct for the (key, model) combos and language of summaries
+type summarySettings struct {
+	combos   []keyModelCombo
+	language string
+}
+
+// return settings of summaries with given models and language (the client's own ones if empty)
+//
+// NOTE: cooldowns of combos are shared with the client's own ones.
+func (c *Client) summarySettings(models []string, language string) summarySettings {
+	c.cooldownMu.Lock()
+	defer c.cooldownMu.Unlock()
+
+	settings := summarySettings{
+		combos:   c.combos,
+		language: cmp.Or(language, c.desiredLanguage),
+	}
+	if len(models) > 0 {
+		settings.combos = newKeyModelCombos(c.googleAIAPIKeys, models)
+	}
+	return settings
 }
 
 // SetDesiredLanguage sets the client's desired language for summaries.
@@ -138,6 +253,32 @@ func (c *Client) SetSummarizeIntervalSeconds(seconds int) {
 	c.summarizeIntervalSeconds = seconds
 }
 
+// SetStoreOriginalContent sets whether to store the original contents of summarized items.
+//
+// When enabled, fetched contents (page texts or files) are stored compressed
+// alongside the summaries, so they can be re-summarized without fetching again.
+// (disabled by default, for the size of storage)
+func (c *Client) SetStoreOriginalContent(store bool) {
+	c.storeOriginalContent = store
+}
+
+// SetFeedBackoff sets the backoff of failing feeds.
+//
+// A feed is not fetched again for `base` after its first failure,
+// and the duration is doubled on each consecutive failure up to `max`.
+func (c *Client) SetFeedBackoff(base, max time.Duration) {
+	c.feedBackoffBase = base
+	c.feedBackoffMax = max
+}
+
+// SetDeadFeedDays sets the number of days after which failing feeds are disabled.
+//
+// Disabled feeds are not fetched until they are enabled again with `EnableFeed`.
+// Zero means feeds are never disabled.
+func (c *Client) SetDeadFeedDays(days uint) {
+	c.deadFeedDays = days
+}
+
 // SetVerbose sets the client's verbose mode.
 func (c *Client) SetVerbose(v bool) {
 	c.verbose = v
@@ -153,7 +294,11 @@ func (c *Client) FetchFeeds(
 	var feeds []gofeed.Feed
 	var errs []error
 
-	for _, url := range c.feedsURLs {
+	for _, url := range c.getFeedsURLs() {
+		if !c.shouldFetchFeed(ctx, url) {
+			continue
+		}
+
 		fetched, err := c.fetchSingleFeed(ctx, url, ignoreAlreadyCached, ignoreItemsPublishedBeforeDays)
 		if err != nil {
 			errs = append(errs, err)
@@ -169,59 +314,79 @@ func (c *Client) FetchFeeds(
 	return feeds, nil
 }
 
-// fetchSingleFeed fetches a single feed from the given URL with proper defer-based cleanup.
+// return the urls of feeds (which can be changed when they are moved)
+func (c *Client) getFeedsURLs() []string {
+	c.stateMu.RLock()
+	defer c.stateMu.RUnlock()
+
+	return c.feedsURLs
+}
+
+// fetchSingleFeed fetches a single feed from the given URL, records it, and filters its items.
 func (c *Client) fetchSingleFeed(
 	ctx context.Context,
 	url string,
 	ignoreAlreadyCached bool,
 	ignoreItemsPublishedBeforeDays uint,
 ) (*gofeed.Feed, error) {
-	v(c.verbose, "fetching feeds from url: %s", url)
-
-	client := &http.Client{
-		Timeout: time.Duration(fetchURLTimeoutSeconds) * time.Second,
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("User-Agent", fakeUserAgent)
-	req.Header.Set("Content-Type", "text/xml;charset=UTF-8")
+	start := time.Now()
+	result := c.fetchFeed(ctx, url)
+	result.latency = time.Since(start)
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch feeds from url: %w", err)
+	url = c.recordFeed(ctx, url, result)
+	if result.err != nil {
+		return nil, result.err
 	}
-	defer func() { _ = resp.Body.Close() }()
+	fetched := result.fetched
 
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("http error %d from url: '%s'", resp.StatusCode, url)
+	// remember where it came from
+	if fetched.Custom == nil {
+		fetched.Custom = map[string]string{}
 	}
+	fetched.Custom[customKeyFeedURL] = url
 
-	contentType := resp.Header.Get("Content-Type")
+	// subscribe to its websub hub, (if enabled)
+	c.subscribeWebSub(ctx, url)
 
-	bytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read '%s' document from '%s': %w", contentType, url, err)
+	if err := c.filterFetchedItems(ctx, url, fetched, ignoreAlreadyCached, ignoreItemsPublishedBeforeDays); err != nil {
+		return nil, err
 	}
 
-	fp := gofeed.NewParser()
-	fetched, err := fp.ParseString(string(bytes))
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse feeds from '%s': %w", url, err)
-	}
+	v(c.verbose, "returning %d item(s)", len(fetched.Items))
 
-	v(c.verbose, "fetched %d item(s)", len(fetched.Items))
+	return fetched, nil
+}
 
+// filter items of the `fetched` feed from `url` which are already cached or were published too long ago
+func (c *Client) filterFetchedItems(
+	ctx context.Context,
+	url string,
+	fetched *gofeed.Feed,
+	ignoreAlreadyCached bool,
+	ignoreItemsPublishedBeforeDays uint,
+) error {
 	if ignoreAlreadyCached {
+		var cacheErr error
 		fetched.Items = slices.DeleteFunc(fetched.Items, func(item *gofeed.Item) bool {
-			exists := c.cache.Exists(item.GUID)
+			if cacheErr != nil {
+				return false
+			}
+			exists, err := c.cache.Exists(ctx, item.GUID)
+			if err != nil {
+				cacheErr = err
+				return false
+			}
 			if exists {
 				v(c.verbose, "ignoring already cached item: '%s' (%s)", item.Title, item.GUID)
 			}
 			return exists
 		})
+
+		// NOTE: stop here if the cache is unavailable,
+		// (returning unfiltered items would lead to re-summarizing all of them)
+		if cacheErr != nil {
+			return fmt.Errorf("failed to filter already cached items of '%s': %w", url, cacheErr)
+		}
 	}
 
 	// delete if it was published too long ago
@@ -236,9 +401,71 @@ func (c *Client) fetchSingleFeed(
 		return before
 	})
 
-	v(c.verbose, "returning %d item(s)", len(fetched.Items))
+	return nil
+}
 
-	return fetched, nil
+// fetch and parse the feed at given `url` with proper defer-based cleanup
+func (c *Client) fetchFeed(ctx context.Context, url string) (result feedFetchResult) {
+	v(c.verbose, "fetching feeds from url: %s", url)
+
+	client := *c.getHTTPClient() // (copied for tracking redirects)
+	redirects := &feedRedirects{next: client.CheckRedirect}
+	client.CheckRedirect = redirects.check
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		result.err = fmt.Errorf("failed to create request: %w", err)
+		return result
+	}
+	req.Header.Set("Content-Type", "text/xml;charset=UTF-8")
+	c.applyFeedOptions(req, url)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		result.err = fmt.Errorf("failed to fetch feeds from url: %w", err)
+		return result
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	result.status = resp.StatusCode
+	if resp.StatusCode != 200 {
+		result.err = fmt.Errorf("http error %d from url: '%s'", resp.StatusCode, url)
+		return result
+	}
+	result.movedTo = redirects.permanentlyMovedTo()
+
+	// NOTE: oversized feeds are rejected
+	limit := c.contentSizeLimits.feed()
+	if resp.ContentLength > limit {
+		result.err = fmt.Errorf("%w: feed of %d bytes from '%s' exceeds %d bytes", ErrContentTooLarge, resp.ContentLength, url, limit)
+		return result
+	}
+
+	// NOTE: non-UTF-8 feeds are transcoded
+	body, err := feedReader(newLimitedReader(resp.Body, limit), resp.Header.Get("Content-Type"))
+	if err != nil {
+		result.err = fmt.Errorf("failed to read feeds from '%s': %w", url, err)
+		return result
+	}
+
+	// NOTE: hubs are not exposed by gofeed, so they are discovered from the head of the raw feed
+	head := &headWriter{limit: webSubDiscoveryBytes}
+
+	fp := gofeed.NewParser()
+	if result.fetched, err = fp.Parse(io.TeeReader(body, head)); err != nil {
+		if errors.Is(err, ErrContentTooLarge) {
+			result.err = fmt.Errorf("failed to read feeds from '%s': %w", url, err)
+		} else {
+			result.err = fmt.Errorf("failed to parse feeds from '%s': %w", url, err)
+		}
+		return result
+	}
+
+	result.hub, result.topic = discoverWebSub(head.buf, resp.Header, result.fetched, url)
+
+	v(c.verbose, "fetched %d item(s)", len(result.fetched.Items))
+
+	return result
 }
 
 // SummarizeAndCacheFeeds summarizes given feeds items and caches them.
@@ -249,12 +476,16 @@ func (c *Client) fetchSingleFeed(
 //
 // If there was a retriable error(eg. model overloads), it will return immediately.
 // (remaining feed items will be retried later)
+//
+// Summarized items are delivered to the sinks set with `SetSinks` afterwards,
+// and the websub hub set with `SetWebSubHub` is pinged if there were new summaries.
 func (c *Client) SummarizeAndCacheFeeds(
 	ctx context.Context,
 	feeds []gofeed.Feed,
 	urlScrapper ...*ssg.Scrapper,
 ) (err error) {
 	var errs []error
+	var summarizedCount int
 
 outer:
 	for _, f := range feeds {
@@ -266,7 +497,7 @@ outer:
 			)
 
 			// summarize,
-			usedModel, translatedTitle, summarizedContent, err := c.summarize(
+			summarized, err := c.summarize(
 				itemCtx,
 				item.Title,
 				item.Link,
@@ -274,6 +505,8 @@ outer:
 			)
 			cancel()
 
+			usedModel, translatedTitle, summarizedContent := summarized.usedModel, summarized.translatedTitle, summarized.summarizedContent
+
 			if err != nil {
 				// NOTE: skip remaining feed items if err is:
 				//   - http 503 ('The model is overloaded. Please try again later.')
@@ -292,12 +525,7 @@ outer:
 				errs = append(errs, fmt.Errorf("failed to summarize item '%s' (%s): %w", item.Title, item.Link, err))
 			} else {
 				// append the result of summary to the content
-				summarizedContent = fmt.Sprintf(
-					"%s\n\n(summarized with **%s**, %s)",
-					summarizedContent,
-					usedModel,
-					time.Now().Format("2006-01-02 15:04:05 (Mon) MST"),
-				)
+				summarizedContent = appendSummaryFooter(summarizedContent, usedModel)
 			}
 
 			// trim translated/summarized contents
@@ -305,8 +533,27 @@ outer:
 			summarizedContent = strings.TrimSpace(summarizedContent)
 
 			// cache, (or update)
-			if cacheErr := c.cache.Save(*item, translatedTitle, summarizedContent); cacheErr != nil {
+			if cacheErr := c.cache.Save(ctx, ItemToCache{
+				Item:    *item,
+				Title:   translatedTitle,
+				Summary: summarizedContent,
+				FeedURL: feedURLOf(f),
+			}); cacheErr != nil {
 				errs = append(errs, fmt.Errorf("failed to cache item '%s': %w", item.Title, cacheErr))
+			} else {
+				if err == nil {
+					summarizedCount++
+				}
+				if revisionErr := c.cache.SaveRevision(ctx, c.newSummaryRevision(item.GUID, translatedTitle, summarizedContent, c.desiredLanguage, summarized)); revisionErr != nil {
+					errs = append(errs, fmt.Errorf("failed to save revision of item '%s': %w", item.Title, revisionErr))
+				}
+
+				// store the original content, (if enabled)
+				if c.storeOriginalContent && len(summarized.fetched) > 0 {
+					if contentErr := c.saveOriginalContent(ctx, item.GUID, summarized); contentErr != nil {
+						errs = append(errs, fmt.Errorf("failed to store original content of item '%s': %w", item.Title, contentErr))
+					}
+				}
 			}
 
 			// and sleep for a while
@@ -316,6 +563,18 @@ outer:
 		}
 	}
 
+	// ping the websub hub, (if there were new summaries)
+	if summarizedCount > 0 && len(c.webSubHubURL) > 0 {
+		if pingErr := c.PingWebSubHub(ctx); pingErr != nil {
+			errs = append(errs, pingErr)
+		}
+	}
+
+	// deliver newly summarized items to sinks
+	if deliverErr := c.DeliverToSinks(ctx); deliverErr != nil {
+		errs = append(errs, deliverErr)
+	}
+
 	if len(errs) > 0 {
 		return errors.Join(errs...)
 	}
@@ -332,69 +591,140 @@ func failedSummary(usedModel string, err error) string {
 	return fmt.Sprintf("%s: %s", ErrorPrefixSummaryFailedWithError, gt.ErrToStr(err))
 }
 
+// append the result of summary (used model and time) to given summarized content
+func appendSummaryFooter(summarizedContent, usedModel string) string {
+	return fmt.Sprintf(
+		"%s\n\n(summarized with **%s**, %s)",
+		summarizedContent,
+		usedModel,
+		time.Now().Format("2006-01-02 15:04:05 (Mon) MST"),
+	)
+}
+
+// summaryResult is a struct for the result of a summary
+type summaryResult struct {
+	usedModel         string
+	translatedTitle   string
+	summarizedContent string
+	usage             TokenUsage
+
+	// fetched original content (empty if it was not fetched, eg. YouTube or URL context)
+	fetched            []byte
+	fetchedContentType string
+	fetchedAt          time.Time
+}
+
 // summarize the content of given `url`
 func (c *Client) summarize(
 	ctx context.Context,
 	title, url string,
 	urlScrapper ...*ssg.Scrapper,
-) (usedModel string, translatedTitle, summarizedContent string, err error) {
+) (summarized summaryResult, err error) {
+	return c.summarizeWith(ctx, c.summarySettings(nil, ""), title, url, urlScrapper...)
+}
+
+// summarize the content of given `url` with `settings`
+func (c *Client) summarizeWith(
+	ctx context.Context,
+	settings summarySettings,
+	title, url string,
+	urlScrapper ...*ssg.Scrapper,
+) (summarized summaryResult, err error) {
 	if isYouTubeURL(url) {
 		url = normalizeYouTubeURL(url)
 
 		v(c.verbose, "summarizing youtube url: %s", url)
 
-		usedModel, translatedTitle, summarizedContent, err = c.translateAndSummarizeYouTube(ctx, title, url)
+		summarized.usedModel, summarized.translatedTitle, summarized.summarizedContent, summarized.usage, err = c.translateAndSummarizeYouTube(ctx, settings, title, url)
 		if err == nil {
-			return usedModel, translatedTitle, summarizedContent, nil
+			return summarized, nil
 		}
 
 		v(c.verbose, "failed to generate summary from youtube url: '%s', error: %s", url, gt.ErrToStr(err))
-		return usedModel, title, failedSummary(usedModel, err), err
+		return summaryResult{
+			usedModel:         summarized.usedModel,
+			translatedTitle:   title,
+			summarizedContent: failedSummary(summarized.usedModel, err),
+		}, err
 	}
 
 	v(c.verbose, "summarizing content of url: %s", url)
 
 	// try fetching the content
 	fetched, contentType, fetchErr := c.fetch(ctx, maxRetryCount, url, urlScrapper...)
-	if fetchErr != nil {
+	if fetchErr != nil && !isRetriableFetchError(fetchErr) {
+		// NOTE: not to be summarized via URL context either (eg. blocked or too large)
+		v(c.verbose, "refused fetching url: '%s', error: %s", url, fetchErr)
+		return summaryResult{
+			translatedTitle:   title,
+			summarizedContent: failedSummary("", fetchErr),
+		}, fetchErr
+	} else if fetchErr != nil {
 		// fallback: summarize via Gemini URL context
-		usedModel, translatedTitle, summarizedContent, err = c.summarizeURL(ctx, title, url, c.desiredLanguage)
+		summarized.usedModel, summarized.translatedTitle, summarized.summarizedContent, summarized.usage, err = c.summarizeURL(ctx, settings.combos, title, url, settings.language)
 		if err == nil {
-			if len(summarizedContent) <= 0 {
-				summarizedContent = summarizedContentEmpty
+			if len(summarized.summarizedContent) <= 0 {
+				summarized.summarizedContent = summarizedContentEmpty
 			}
-			return usedModel, translatedTitle, summarizedContent, nil
+			return summarized, nil
 		}
 
 		v(c.verbose, "failed to generate summary with url: '%s', error: %s", url, gt.ErrToStr(err))
-		return usedModel, title, failedSummary(usedModel, err), err
+		return summaryResult{
+			usedModel:         summarized.usedModel,
+			translatedTitle:   title,
+			summarizedContent: failedSummary(summarized.usedModel, err),
+		}, err
+	}
+
+	return c.summarizeFetched(ctx, settings, title, url, fetched, contentType, time.Now())
+}
+
+// summarize already fetched content of given `url` based on its content type
+func (c *Client) summarizeFetched(
+	ctx context.Context,
+	settings summarySettings,
+	title, url string,
+	fetched []byte,
+	contentType string,
+	fetchedAt time.Time,
+) (summarized summaryResult, err error) {
+	summarized = summaryResult{
+		fetched:            fetched,
+		fetchedContentType: contentType,
+		fetchedAt:          fetchedAt,
 	}
 
-	// summarize fetched content based on type
 	switch {
 	case isTextFormattableContent(contentType):
-		prompt := fmt.Sprintf(summarizeContentPromptFormat, c.desiredLanguage, title, string(fetched))
-		usedModel, translatedTitle, summarizedContent, err = c.translateAndSummarize(ctx, prompt)
+		prompt := fmt.Sprintf(summarizeContentPromptFormat, settings.language, title, string(fetched))
+		summarized.usedModel, summarized.translatedTitle, summarized.summarizedContent, summarized.usage, err = c.translateAndSummarize(ctx, settings.combos, prompt)
 	case isFileContent(contentType):
-		prompt := fmt.Sprintf(summarizeContentFilePromptFormat, c.desiredLanguage, title)
-		usedModel, translatedTitle, summarizedContent, err = c.translateAndSummarize(ctx, prompt, fetched)
+		if limit := c.contentSizeLimits.forContentType(contentType); int64(len(fetched)) > limit {
+			err = fmt.Errorf("%w: file of %d bytes exceeds %d bytes", ErrContentTooLarge, len(fetched), limit)
+			break
+		}
+		prompt := fmt.Sprintf(summarizeContentFilePromptFormat, settings.language, title)
+		summarized.usedModel, summarized.translatedTitle, summarized.summarizedContent, summarized.usage, err = c.translateAndSummarize(ctx, settings.combos, prompt, fetched)
 	default:
 		err = fmt.Errorf("not a summarizable content type: %s", contentType)
 	}
 
 	if err != nil {
 		v(c.verbose, "failed to generate summary for '%s', error: %s", url, gt.ErrToStr(err))
-		return usedModel, title, failedSummary(usedModel, err), err
+		summarized.translatedTitle = title
+		summarized.summarizedContent = failedSummary(summarized.usedModel, err)
+		return summarized, err
 	}
 
-	if len(translatedTitle) <= 0 {
-		translatedTitle = title
+	if len(summarized.translatedTitle) <= 0 {
+		summarized.translatedTitle = title
 	}
-	if len(summarizedContent) <= 0 {
-		summarizedContent = summarizedContentEmpty
+	if len(summarized.summarizedContent) <= 0 {
+		summarized.summarizedContent = summarizedContentEmpty
 	}
 
-	return usedModel, translatedTitle, summarizedContent, nil
+	return summarized, nil
 }
 
 // fetch url content with or without url scrapper
@@ -404,40 +734,49 @@ func (c *Client) fetch(
 	url string,
 	urlScrapper ...*ssg.Scrapper,
 ) (scrapped []byte, contentType string, err error) {
-	contentType, _ = getContentType(ctx, url, c.verbose)
+	contentType, _ = getContentType(ctx, c.getHTTPClient(), url, c.getUserAgent(), c.verbose)
 
 	if len(urlScrapper) > 0 && strings.HasPrefix(contentType, "text/html") { // if scrapper is given, and content-type is HTML, use it
 		scrapper := urlScrapper[0]
 
+		// NOTE: scrappers do not request with the http client, so urls are checked here
 		var crawled map[string]string
-		crawled, err = scrapper.CrawlURLs([]string{url}, true)
+		if err = c.checkSafeDial(ctx, url); err == nil {
+			crawled, err = scrapper.CrawlURLs([]string{url}, true)
+		}
 
 		for _, v := range crawled {
 			// get the first (and the only one) value
-			scrapped = fmt.Appendf(nil, urlToTextFormat, url, contentType, v)
+			scrapped = fmt.Appendf(nil, urlToTextFormat, url, contentType, truncateText(v, c.contentSizeLimits.forContentType(contentType)))
 			break
 		}
 	} else { // otherwise, use `fetchURLContent` function
-		scrapped, contentType, err = fetchURLContent(ctx, url, c.verbose)
+		scrapped, contentType, err = fetchURLContent(ctx, c.getHTTPClient(), url, c.getUserAgent(), c.contentSizeLimits, c.verbose)
 	}
 
 	// retry if needed
-	if err != nil && remainingRetryCount > 0 {
+	if err != nil && remainingRetryCount > 0 && isRetriableFetchError(err) {
 		v(c.verbose, "retrying fetching from url '%s' (remaining count: %d)", url, remainingRetryCount)
 
 		return c.fetch(ctx, remainingRetryCount-1, url, urlScrapper...)
 	}
 
 	// if all retries failed with urlScrapper, try without it
-	if err != nil && remainingRetryCount == 0 && len(urlScrapper) > 0 {
+	if err != nil && remainingRetryCount == 0 && len(urlScrapper) > 0 && isRetriableFetchError(err) {
 		v(c.verbose, "fetching from url '%s' without url scrapper as a last try", url)
 
-		scrapped, contentType, err = fetchURLContent(ctx, url, c.verbose)
+		scrapped, contentType, err = fetchURLContent(ctx, c.getHTTPClient(), url, c.getUserAgent(), c.contentSizeLimits, c.verbose)
 	}
 
 	return scrapped, contentType, err
 }
 
+// check if fetching can be retried after given error
+// (blocked requests and oversized contents will fail again)
+func isRetriableFetchError(err error) bool {
+	return !isBlockedRequest(err) && !errors.Is(err, ErrContentTooLarge)
+}
+
 // ErrNoAvailableAPIKey is returned when every (key, model) combo is in cooldown.
 var ErrNoAvailableAPIKey = errors.New("no available api key/model (all in cooldown)")
 
@@ -470,46 +809,69 @@ func parseRetryDelay(details []map[string]any) time.Duration {
 	return fallback
 }
 
-// pickAvailableCombo returns the next combo (round-robin from the global
+// pickAvailableCombo returns the next one of `combos` (round-robin from the global
 // counter) whose cooldown has expired at `now`. ok is false if all combos
 // are still in cooldown.
-func (c *Client) pickAvailableCombo(now time.Time) (combo keyModelCombo, idx int, ok bool) {
+func (c *Client) pickAvailableCombo(combos []keyModelCombo, now time.Time) (combo keyModelCombo, idx int, ok bool) {
 	start := int(c._numRequests.Add(1) - 1)
 
 	c.cooldownMu.Lock()
 	defer c.cooldownMu.Unlock()
 
-	n := len(c.combos)
+	n := len(combos)
 	for i := 0; i < n; i++ {
 		candidate := (start + i) % n
-		until, inCooldown := c.cooldownUntil[candidate]
+		until, inCooldown := c.cooldownUntil[combos[candidate]]
 		if inCooldown && until.After(now) {
 			continue
 		}
-		return c.combos[candidate], candidate, true
+		return combos[candidate], candidate, true
 	}
 	return keyModelCombo{}, 0, false
 }
 
 // ListCachedItems lists cached items.
+//
+// NOTE: errors are logged and an empty list is returned on failure,
+// use `ListCachedItemsContext` for handling them.
 func (c *Client) ListCachedItems(includeItemsMarkedAsRead bool) []CachedItem {
-	return redactItems(c.cache.List(includeItemsMarkedAsRead), c.googleAIAPIKeys)
+	items, err := c.ListCachedItemsContext(context.Background(), includeItemsMarkedAsRead)
+	if err != nil {
+		log.Printf("failed to list cached items: %s", err)
+	}
+	return items
+}
+
+// ListCachedItemsContext lists cached items with given context.
+func (c *Client) ListCachedItemsContext(ctx context.Context, includeItemsMarkedAsRead bool) ([]CachedItem, error) {
+	items, err := c.cache.List(ctx, includeItemsMarkedAsRead)
+	if err != nil {
+		return []CachedItem{}, err
+	}
+	return redactItems(items, c.googleAIAPIKeys), nil
+}
+
+// ListCachedItemsWithFilter lists cached items which match given `filter`.
+func (c *Client) ListCachedItemsWithFilter(ctx context.Context, filter ListFilter) ([]CachedItem, error) {
+	items, err := c.cache.ListWithFilter(ctx, filter)
+	if err != nil {
+		return []CachedItem{}, err
+	}
+	return redactItems(items, c.googleAIAPIKeys), nil
 }
 
 // MarkCachedItemsAsRead marks given cached items as read.
 func (c *Client) MarkCachedItemsAsRead(items []CachedItem) error {
-	var errs []error
+	guids := make([]string, 0, len(items))
 	for _, item := range items {
-		if err := c.cache.MarkAsRead(item.GUID); err != nil {
-			errs = append(errs, err)
-		}
+		guids = append(guids, item.GUID)
 	}
-	return errors.Join(errs...)
+	return c.cache.MarkManyAsRead(context.Background(), guids).Err()
 }
 
 // DeleteOldCachedItems deletes old cached items.
 func (c *Client) DeleteOldCachedItems() error {
-	return c.cache.DeleteOlderThan1Month()
+	return c.cache.DeleteOlderThan1Month(context.Background())
 }
 
 // PublishXML returns XML bytes (application/rss+xml) of given cached items.
@@ -517,54 +879,6 @@ func (c *Client) PublishXML(
 	title, link, description, author, email string,
 	items []CachedItem,
 ) (bytes []byte, err error) {
-	feed := &feeds.Feed{
-		Title:       title,
-		Link:        &feeds.Link{Href: link},
-		Description: description,
-		Author:      &feeds.Author{Name: author, Email: email},
-		Created:     time.Now(),
-	}
-
-	// NOTE: drop items without summary (omit feed items that are not summarized yet)
-	items = slices.DeleteFunc(items, func(item CachedItem) bool {
-		return len(item.Summary) <= 0
-	})
-
-	var feedItems []*feeds.Item
-	for _, item := range items {
-		content := decorateHTML(item.Summary)
-
-		// NOTE: if the summary was not successful, it is a concatenated string of the error message and original content
-		if !isError(item.Summary) {
-			// if it was a successful summary, append comments or GUID of the original content
-			if len(item.Comments) > 0 {
-				escaped := html.EscapeString(item.Comments)
-				content += `<br><br>` + fmt.Sprintf(`Comments: <a href="%[1]s">%[1]s</a>`, escaped)
-			} else {
-				escaped := html.EscapeString(item.GUID)
-				content += `<br><br>` + fmt.Sprintf(`GUID: <a href="%[1]s">%[1]s</a>`, escaped)
-			}
-		}
-
-		feedItem := feeds.Item{
-			Id:    item.GUID,
-			Title: item.Title,
-			Link: &feeds.Link{
-				Href: item.Link,
-			},
-			Description: item.Description,
-			Content:     content,
-			Created:     item.CreatedAt,
-			Updated:     item.UpdatedAt,
-		}
-
-		feedItems = append(feedItems, &feedItem)
-	}
-	feed.Items = feedItems
-
-	rssFeed := (&feeds.Rss{
-		Feed: feed,
-	}).RssFeed()
-
-	return xml.MarshalIndent(rssFeed.FeedXml(), "", "  ")
+	bytes, _, err = c.Publish(PublishFormatRSS, title, link, description, author, email, items)
+	return bytes, err
 }
@@ -2,6 +2,7 @@ package rf
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -40,11 +41,12 @@ func TestSummarizeURLContent(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
 	defer cancel()
 
-	_, translatedTitle, summarizedContent, err := client.summarize(
+	summarized, err := client.summarize(
 		ctx,
 		`meinside/rss-feeds-go: A go utility package for handling RSS feeds.`,
 		`https://github.com/meinside/rss-feeds-go`,
 	)
+	translatedTitle, summarizedContent := summarized.translatedTitle, summarized.summarizedContent
 	if err != nil {
 		t.Errorf("failed to summarize url content: %s", err)
 	} else {
@@ -60,8 +62,9 @@ func TestSummarizeURL(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
 	defer cancel()
 
-	_, translatedTitle, summarizedContent, err := client.summarizeURL(
+	_, translatedTitle, summarizedContent, _, err := client.summarizeURL(
 		ctx,
+		client.combos,
 		`meinside/gemini-things-go: A Golang library for generating things with Gemini APIs `,
 		`https://github.com/meinside/gemini-things-go`,
 		`ko_KR`,
@@ -81,11 +84,12 @@ func TestSummarizeYouTube(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
 	defer cancel()
 
-	_, translatedTitle, summarizedContent, err := client.summarize(
+	summarized, err := client.summarize(
 		ctx,
 		`I2C test on Raspberry Pi with Adafruit 8x8 LED Matrix and Ruby`,
 		`https://www.youtube.com/watch?v=fV5rI_5fDI8`,
 	)
+	translatedTitle, summarizedContent := summarized.translatedTitle, summarized.summarizedContent
 	if err != nil {
 		t.Errorf("failed to summarize youtube url: %s", err)
 	} else {
@@ -101,11 +105,12 @@ func TestSummarizeWrongURL(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
 	defer cancel()
 
-	_, translatedTitle, summarizedContent, err := client.summarize(
+	summarized, err := client.summarize(
 		ctx,
 		`What is the answer to life, the universe, and everything?`,
 		`https://no-sucn-domain/that-will-lead/to/fetch-error`,
 	)
+	translatedTitle, summarizedContent := summarized.translatedTitle, summarized.summarizedContent
 	if err != nil {
 		t.Errorf("should have failed with the wrong url")
 	} else {
@@ -131,11 +136,12 @@ func TestSummarizeWrongAPIKey(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
 	defer cancel()
 
-	_, translatedTitle, summarizedContent, err := client.summarize(
+	summarized, err := client.summarize(
 		ctx,
 		`What is the answer to life, the universe, and everything?`,
 		`https://no-sucn-domain/that-will-lead/to/fetch-error`,
 	)
+	translatedTitle, summarizedContent := summarized.translatedTitle, summarized.summarizedContent
 	if err != nil {
 		if translatedTitle != `What is the answer to life, the universe, and everything?` {
 			t.Errorf("should have kept the title, but got '%s'", translatedTitle)
@@ -211,7 +217,7 @@ func TestPickAvailableComboCoversAllPairs(t *testing.T) {
 
 	seen := map[keyModelCombo]bool{}
 	for i := 0; i < 6; i++ {
-		combo, _, ok := client.pickAvailableCombo(now)
+		combo, _, ok := client.pickAvailableCombo(client.combos, now)
 		if !ok {
 			t.Fatalf("expected ok on iteration %d", i)
 		}
@@ -232,8 +238,8 @@ func TestListAndMarkCachedItems(t *testing.T) {
 	// save items directly to cache
 	item1 := testFeedItem("guid-list-1", "Title 1")
 	item2 := testFeedItem("guid-list-2", "Title 2")
-	_ = client.cache.Save(item1, "Title 1", "Summary with secret-key inside")
-	_ = client.cache.Save(item2, "Title 2", "Clean summary")
+	_ = legacyCacheOf(client).Save(item1, "Title 1", "Summary with secret-key inside")
+	_ = legacyCacheOf(client).Save(item2, "Title 2", "Clean summary")
 
 	// list should redact API keys
 	items := client.ListCachedItems(false)
@@ -269,9 +275,9 @@ func TestDeleteOldCachedItems(t *testing.T) {
 	client := NewClient([]string{"key"}, nil)
 
 	item := testFeedItem("guid-old", "Old Title")
-	_ = client.cache.Save(item, "Old Title", "Old Summary")
+	_ = legacyCacheOf(client).Save(item, "Old Title", "Old Summary")
 
-	// memCache items have zero CreatedAt, so they are "old"
+	ageMemCacheItems(client.cache.(*memCache))
 	if err := client.DeleteOldCachedItems(); err != nil {
 		t.Fatalf("DeleteOldCachedItems failed: %s", err)
 	}
@@ -441,7 +447,7 @@ func TestFetchFeedsIgnoreCached(t *testing.T) {
 	client := NewClient([]string{"key"}, []string{server.URL})
 
 	// pre-cache one item
-	_ = client.cache.Save(testFeedItem("guid-cached", "Cached Article"), "Cached", "Summary")
+	_ = legacyCacheOf(client).Save(testFeedItem("guid-cached", "Cached Article"), "Cached", "Summary")
 
 	ctx := context.Background()
 	feeds, err := client.FetchFeeds(ctx, true, 7)
@@ -523,3 +529,48 @@ func TestFetchFeedsOldItemsFiltered(t *testing.T) {
 		t.Errorf("expected 'guid-recent', got %q", feeds[0].Items[0].GUID)
 	}
 }
+
+// test `FetchFeeds` with an unavailable cache (should not return unfiltered items)
+func TestFetchFeedsCacheUnavailable(t *testing.T) {
+	rssFeed := `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Test Feed</title>
+    <item>
+      <title>Article</title>
+      <link>https://example.com/article</link>
+      <guid>guid-unavailable</guid>
+      <pubDate>` + time.Now().Format(time.RFC1123Z) + `</pubDate>
+    </item>
+  </channel>
+</rss>`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprint(w, rssFeed)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithDB([]string{"key"}, []string{server.URL}, fmt.Sprintf("%s/unavailable.db", t.TempDir()))
+	if err != nil {
+		t.Fatalf("failed to create client with DB: %s", err)
+	}
+	_ = client.Close() // make the cache unavailable
+
+	feeds, err := client.FetchFeeds(context.Background(), true, 7)
+	if !errors.Is(err, ErrUnavailable) {
+		t.Errorf("expected ErrUnavailable, got %v", err)
+	}
+	if len(feeds) != 0 {
+		t.Errorf("expected no feeds, got %d", len(feeds))
+	}
+
+	// without filtering, the cache is not needed
+	if feeds, err = client.FetchFeeds(context.Background(), false, 7); err != nil || len(feeds) != 1 {
+		t.Errorf("expected 1 feed, got %d (%v)", len(feeds), err)
+	}
+
+	if _, err := client.ListCachedItemsContext(context.Background(), false); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("expected ErrUnavailable from ListCachedItemsContext, got %v", err)
+	}
+}
@@ -26,7 +26,7 @@ func TestBuildCombos(t *testing.T) {
 	}
 
 	// re-calling SetGoogleAIModels resets cooldownUntil
-	c.cooldownUntil[0] = timeNowForTest()
+	c.cooldownUntil[c.combos[0]] = timeNowForTest()
 	c.SetGoogleAIModels([]string{"m1"})
 	if len(c.cooldownUntil) != 0 {
 		t.Errorf("cooldownUntil not reset, len=%d", len(c.cooldownUntil))
@@ -46,7 +46,7 @@ func TestPickAvailableComboRoundRobin(t *testing.T) {
 
 	got := map[string]bool{}
 	for i := 0; i < 2; i++ {
-		combo, _, ok := c.pickAvailableCombo(now)
+		combo, _, ok := c.pickAvailableCombo(c.combos, now)
 		if !ok {
 			t.Fatalf("expected ok on iteration %d", i)
 		}
@@ -62,10 +62,10 @@ func TestPickAvailableComboSkipsCooldown(t *testing.T) {
 	c.SetGoogleAIModels([]string{"m1"}) // idx0=(k1,m1), idx1=(k2,m1)
 
 	now := time.Unix(1_000_000, 0)
-	c.cooldownUntil[0] = now.Add(30 * time.Second) // put k1 combo in cooldown
+	c.cooldownUntil[c.combos[0]] = now.Add(30 * time.Second) // put k1 combo in cooldown
 
 	for i := 0; i < 3; i++ {
-		combo, idx, ok := c.pickAvailableCombo(now)
+		combo, idx, ok := c.pickAvailableCombo(c.combos, now)
 		if !ok {
 			t.Fatalf("expected ok, iteration %d", i)
 		}
@@ -80,15 +80,15 @@ func TestPickAvailableComboAllCooldown(t *testing.T) {
 	c.SetGoogleAIModels([]string{"m1"}) // single combo
 
 	now := time.Unix(1_000_000, 0)
-	c.cooldownUntil[0] = now.Add(30 * time.Second)
+	c.cooldownUntil[c.combos[0]] = now.Add(30 * time.Second)
 
-	if _, _, ok := c.pickAvailableCombo(now); ok {
+	if _, _, ok := c.pickAvailableCombo(c.combos, now); ok {
 		t.Errorf("expected ok=false when all combos in cooldown")
 	}
 
 	// available again after cooldown expires
 	later := now.Add(31 * time.Second)
-	if _, _, ok := c.pickAvailableCombo(later); !ok {
+	if _, _, ok := c.pickAvailableCombo(c.combos, later); !ok {
 		t.Errorf("expected ok=true after cooldown expired")
 	}
 }
@@ -155,7 +155,7 @@ func TestWithFailoverRetriesOnQuota(t *testing.T) {
 	nowFn := func() time.Time { return now }
 
 	calls := 0
-	usedModel, err := c.withFailover(nowFn, func(gtc *gt.Client, model string) error {
+	usedModel, err := c.withFailover(nowFn, c.combos, func(gtc *gt.Client, model string) error {
 		calls++
 		if calls < 3 {
 			return quotaErrForTest()
@@ -184,7 +184,7 @@ func TestWithFailoverAllQuota(t *testing.T) {
 	now := time.Unix(1_000_000, 0)
 	nowFn := func() time.Time { return now }
 
-	_, err := c.withFailover(nowFn, func(gtc *gt.Client, model string) error {
+	_, err := c.withFailover(nowFn, c.combos, func(gtc *gt.Client, model string) error {
 		return quotaErrForTest()
 	})
 	if !errors.Is(err, ErrNoAvailableAPIKey) {
@@ -201,7 +201,7 @@ func TestWithFailoverNonQuotaErrorReturnsImmediately(t *testing.T) {
 
 	sentinel := errors.New("boom")
 	calls := 0
-	_, err := c.withFailover(nowFn, func(gtc *gt.Client, model string) error {
+	_, err := c.withFailover(nowFn, c.combos, func(gtc *gt.Client, model string) error {
 		calls++
 		return sentinel
 	})
@@ -0,0 +1,257 @@
+package rf
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultDigestMaxItems = 100
+
+	maxDigestItemSummarySize = 2000 // summaries of items are truncated to this size (in bytes) in the prompt of digests
+)
+
+// DigestPeriod is a type for the periods of digests
+type DigestPeriod string
+
+// DigestPeriod constants
+const (
+	DigestPeriodDaily  DigestPeriod = "daily"
+	DigestPeriodWeekly DigestPeriod = "weekly"
+)
+
+// duration of the period
+func (p DigestPeriod) duration() time.Duration {
+	if p == DigestPeriodWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// Digest is a struct for a digest of cached items in a period
+type Digest struct {
+	gorm.Model
+
+	Period DigestPeriod
+	Since  time.Time `gorm:"index"`
+	Until  time.Time
+
+	Title     string
+	Content   string   // in markdown
+	ItemGUIDs []string `gorm:"serializer:json"` // guids of the items in this digest
+
+	UsedModel     string
+	PromptVersion int
+	Language      string
+
+	Usage TokenUsage `gorm:"embedded;embeddedPrefix:usage_"`
+}
+
+// GUID returns a unique id of the digest, which is stable for the same period.
+func (d Digest) GUID() string {
+	return fmt.Sprintf("digest-%s-%s", d.Period, d.Until.UTC().Format("20060102150405"))
+}
+
+// Markdown returns the digest rendered as markdown.
+func (d Digest) Markdown() string {
+	return fmt.Sprintf("# %s\n\n%s\n", d.Title, d.Content)
+}
+
+// HTML returns the digest rendered as HTML.
+func (d Digest) HTML() string {
+	return decorateHTML(d.Markdown())
+}
+
+// DigestOptions is a struct for the options of `GenerateDigest`
+type DigestOptions struct {
+	Period   DigestPeriod // (default: daily)
+	Until    time.Time    // end of the period (default: now)
+	Title    string       // (default: generated from the period)
+	MaxItems int          // maximum number of the newest items in the digest (default: 100)
+
+	Filter ListFilter // filter of items, eg. from a feed or in a category (items marked as read are also included, and its period is ignored)
+}
+
+// digestGroup is a group of related items in a digest
+type digestGroup struct {
+	name  string
+	items []CachedItem
+}
+
+// GenerateDigest generates a digest of the summarized items cached in a period, and stores it.
+//
+// Related items are grouped (by their categories or source feeds) as hints for the sections of the digest.
+//
+// It returns an error wrapping `ErrNotFound` if there is no summarized item in the period.
+func (c *Client) GenerateDigest(ctx context.Context, opts DigestOptions) (*Digest, error) {
+	period := cmp.Or(opts.Period, DigestPeriodDaily)
+	since, until, items, err := c.itemsForDigest(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// group related items
+	feedTitles := map[string]string{}
+	if feeds, err := c.cache.ListFeeds(ctx); err == nil {
+		for _, feed := range feeds {
+			feedTitles[feed.URL] = feed.Title
+		}
+	}
+	groups := groupItemsForDigest(items, feedTitles)
+
+	// generate
+	usedModel, content, usage, err := c.generateDigest(ctx, digestPrompt(c.desiredLanguage, since, until, groups))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate digest with model '%s': %w", usedModel, err)
+	}
+
+	digest := Digest{
+		Period:        period,
+		Since:         since,
+		Until:         until,
+		Title:         cmp.Or(opts.Title, digestTitle(period, since, until)),
+		Content:       content,
+		UsedModel:     usedModel,
+		PromptVersion: digestPromptVersion,
+		Language:      c.desiredLanguage,
+		Usage:         usage,
+	}
+	for _, group := range groups {
+		for _, item := range group.items {
+			digest.ItemGUIDs = append(digest.ItemGUIDs, item.GUID)
+		}
+	}
+
+	if err := c.cache.SaveDigest(ctx, &digest); err != nil {
+		return nil, err
+	}
+	return &digest, nil
+}
+
+// itemsForDigest returns the period of a digest with given `opts`,
+// and the newest summarized items cached in it.
+func (c *Client) itemsForDigest(ctx context.Context, opts DigestOptions) (since, until time.Time, items []CachedItem, err error) {
+	period := cmp.Or(opts.Period, DigestPeriodDaily)
+	until = opts.Until
+	if until.IsZero() {
+		until = time.Now()
+	}
+	since = until.Add(-period.duration())
+
+	filter := opts.Filter
+	filter.IncludeItemsMarkedAsRead = true
+	filter.Since, filter.Until = since, until
+	if items, err = c.cache.ListWithFilter(ctx, filter); err != nil {
+		return since, until, nil, err
+	}
+	items = slices.DeleteFunc(items, func(item CachedItem) bool {
+		return len(item.Summary) <= 0 || isError(item.Summary)
+	})
+	if len(items) <= 0 {
+		return since, until, nil, fmt.Errorf("%w: no summarized items from %s to %s", ErrNotFound, since.Format(time.RFC3339), until.Format(time.RFC3339))
+	}
+	slices.SortStableFunc(items, func(a, b CachedItem) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	if maxItems := cmp.Or(opts.MaxItems, defaultDigestMaxItems); len(items) > maxItems {
+		items = items[:maxItems]
+	}
+
+	return since, until, items, nil
+}
+
+// ListDigests lists the stored digests, newest first.
+func (c *Client) ListDigests(ctx context.Context) ([]Digest, error) {
+	return c.cache.ListDigests(ctx)
+}
+
+// FetchDigest fetches the stored digest with given `id`.
+func (c *Client) FetchDigest(ctx context.Context, id uint) (*Digest, error) {
+	return c.cache.FetchDigest(ctx, id)
+}
+
+// PublishDigests returns bytes of given digests as feed items with `opts`, with its content type.
+func (c *Client) PublishDigests(digests []Digest, opts PublishOptions) (bytes []byte, contentType string, err error) {
+	var items []CachedItem
+	for _, digest := range digests {
+		item := CachedItem{
+			Title:   digest.Title,
+			Link:    opts.Link,
+			GUID:    digest.GUID(),
+			Summary: digest.Content,
+		}
+		item.CreatedAt, item.UpdatedAt = digest.CreatedAt, digest.UpdatedAt
+
+		items = append(items, item)
+	}
+
+	opts.OmitFooter = true
+
+	return c.PublishWithOptions(items, opts)
+}
+
+// group given items by their first categories, or by their source feeds
+func groupItemsForDigest(items []CachedItem, feedTitles map[string]string) (groups []digestGroup) {
+	indices := map[string]int{}
+	for _, item := range items {
+		var name string
+		if len(item.Categories) > 0 {
+			name = item.Categories[0]
+		} else if len(item.FeedURL) > 0 {
+			name = cmp.Or(feedTitles[item.FeedURL], item.FeedURL)
+		} else {
+			name = "Others"
+		}
+
+		if idx, exists := indices[name]; exists {
+			groups[idx].items = append(groups[idx].items, item)
+		} else {
+			indices[name] = len(groups)
+			groups = append(groups, digestGroup{name: name, items: []CachedItem{item}})
+		}
+	}
+
+	// larger groups first
+	slices.SortStableFunc(groups, func(a, b digestGroup) int {
+		return cmp.Compare(len(b.items), len(a.items))
+	})
+
+	return groups
+}
+
+// generate a prompt for the digest of given groups
+func digestPrompt(language string, since, until time.Time, groups []digestGroup) string {
+	var sb strings.Builder
+	for _, group := range groups {
+		fmt.Fprintf(&sb, "<group name=%q>\n", group.name)
+		for _, item := range group.items {
+			fmt.Fprintf(&sb, "<item>\n<item:title>%s</item:title>\n<item:link>%s</item:link>\n<item:summary>%s</item:summary>\n</item>\n",
+				item.Title,
+				cmp.Or(item.Link, item.GUID),
+				truncateText(item.Summary, maxDigestItemSummarySize),
+			)
+		}
+		sb.WriteString("</group>\n")
+	}
+
+	return fmt.Sprintf(digestPromptFormat,
+		language,
+		since.Format(time.RFC3339),
+		until.Format(time.RFC3339),
+		sb.String(),
+	)
+}
+
+// generate a title of the digest in given period
+func digestTitle(period DigestPeriod, since, until time.Time) string {
+	if period == DigestPeriodWeekly {
+		return fmt.Sprintf("Weekly digest (%s ~ %s)", since.Format("2006-01-02"), until.Format("2006-01-02"))
+	}
+	return fmt.Sprintf("Daily digest (%s)", until.Format("2006-01-02"))
+}
@@ -0,0 +1,162 @@
+package rf
+
+import (
+	"context"
+	"errors"
+	"log"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// test grouping items and generating prompts for digests
+func TestDigestPrompt(t *testing.T) {
+	items := []CachedItem{
+		{Title: "Go 1.30 released", Link: "https://example.com/go", Summary: "summary of go", Categories: []string{"Go", "news"}},
+		{Title: "Rust news", Link: "https://example.com/rust", Summary: "summary of rust", FeedURL: "https://example.com/rust.xml"},
+		{Title: "Go generics", Link: "https://example.com/generics", Summary: strings.Repeat("a", maxDigestItemSummarySize+1), Categories: []string{"Go"}},
+		{Title: "Misc", GUID: "guid-misc", Summary: "summary of misc"},
+	}
+
+	groups := groupItemsForDigest(items, map[string]string{"https://example.com/rust.xml": "Rust Blog"})
+	var names []string
+	for _, group := range groups {
+		names = append(names, group.name)
+	}
+	if strings.Join(names, ",") != "Go,Rust Blog,Others" || len(groups[0].items) != 2 {
+		t.Errorf("unexpected groups: %v", names)
+	}
+
+	until := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	prompt := digestPrompt("Korean", until.Add(-24*time.Hour), until, groups)
+	for _, expected := range []string{
+		"in Korean language",
+		`<group name="Rust Blog">`,
+		"<item:link>https://example.com/go</item:link>",
+		"<item:link>guid-misc</item:link>",
+		"[... truncated:",
+	} {
+		if !strings.Contains(prompt, expected) {
+			t.Errorf("expected '%s' in prompt, got %s", expected, prompt)
+		}
+	}
+
+	if title := digestTitle(DigestPeriodWeekly, until.Add(-7*24*time.Hour), until); title != "Weekly digest (2026-10-11 ~ 2026-10-18)" {
+		t.Errorf("unexpected title: %s", title)
+	}
+}
+
+// test storing and publishing digests
+func TestDigests(t *testing.T) {
+	ctx := context.Background()
+
+	dbc, err := newDBCache(filepath.Join(t.TempDir(), "digests.db"))
+	if err != nil {
+		t.Fatalf("failed to create dbCache: %s", err)
+	}
+	defer func() { _ = dbc.Close() }()
+
+	for _, cache := range []FeedsItemsCacheV2{newMemCache(), dbc} {
+		client := NewClient([]string{"key"}, nil)
+		client.cache = cache
+
+		// no items in the period
+		if err := cache.Save(ctx, ItemToCache{Item: gofeed.Item{GUID: "guid-digest-1"}, Title: "Item 1", Summary: "summary 1"}); err != nil {
+			t.Fatalf("failed to save item: %s", err)
+		}
+		if _, err := client.GenerateDigest(ctx, DigestOptions{Until: time.Now().Add(-48 * time.Hour)}); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound for a period without items, got %v", err)
+		}
+
+		// items in the period
+		saved, err := cache.Fetch(ctx, "guid-digest-1")
+		if err != nil || saved.CreatedAt.IsZero() {
+			t.Fatalf("expected creation time to be set, got %+v (%v)", saved, err)
+		}
+		if err := cache.Save(ctx, ItemToCache{Item: gofeed.Item{GUID: "guid-digest-1"}, Title: "Item 1", Summary: "summary 1 again"}); err != nil {
+			t.Fatalf("failed to save item again: %s", err)
+		}
+		if resaved, err := cache.Fetch(ctx, "guid-digest-1"); err != nil || !resaved.CreatedAt.Equal(saved.CreatedAt) {
+			t.Errorf("expected creation time to be kept, got %+v (%v)", resaved, err)
+		}
+		if err := cache.DeleteOlderThan1Month(ctx); err != nil {
+			t.Fatalf("failed to delete old items: %s", err)
+		}
+		if _, _, items, err := client.itemsForDigest(ctx, DigestOptions{}); err != nil || len(items) != 1 || items[0].GUID != "guid-digest-1" {
+			t.Errorf("unexpected items for digest: %+v (%v)", items, err)
+		}
+
+		until := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+		for _, digest := range []*Digest{
+			{Period: DigestPeriodDaily, Until: until.Add(-24 * time.Hour), Title: "Daily digest 1", Content: "first"},
+			{Period: DigestPeriodDaily, Until: until, Title: "Daily digest 2", Content: "## Go\n\n- [Go 1.30](https://example.com/go)", ItemGUIDs: []string{"guid-digest-1"}},
+		} {
+			if err := cache.SaveDigest(ctx, digest); err != nil {
+				t.Fatalf("failed to save digest: %s", err)
+			}
+			if digest.ID == 0 || digest.CreatedAt.IsZero() {
+				t.Errorf("expected id and timestamps to be set, got %+v", digest)
+			}
+		}
+
+		digests, err := client.ListDigests(ctx)
+		if err != nil || len(digests) != 2 || digests[0].Title != "Daily digest 2" {
+			t.Fatalf("unexpected digests: %+v (%v)", digests, err)
+		}
+		digest, err := client.FetchDigest(ctx, digests[0].ID)
+		if err != nil || len(digest.ItemGUIDs) != 1 {
+			t.Fatalf("unexpected digest: %+v (%v)", digest, err)
+		}
+		if _, err := client.FetchDigest(ctx, 999); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound for unknown digest, got %v", err)
+		}
+
+		// rendered
+		if markdown := digest.Markdown(); !strings.HasPrefix(markdown, "# Daily digest 2\n\n## Go") {
+			t.Errorf("unexpected markdown: %s", markdown)
+		}
+		if html := digest.HTML(); !strings.Contains(html, `<a href="https://example.com/go">Go 1.30</a>`) {
+			t.Errorf("unexpected html: %s", html)
+		}
+
+		// published
+		bytes, _, err := client.PublishDigests(digests, PublishOptions{Format: PublishFormatAtom, Title: "Digests", Link: "https://example.com"})
+		if err != nil {
+			t.Fatalf("failed to publish digests: %s", err)
+		}
+		parsed, err := gofeed.NewParser().ParseString(string(bytes))
+		if err != nil || len(parsed.Items) != 2 {
+			t.Fatalf("unexpected published digests: %s (%v)", bytes, err)
+		}
+		if parsed.Items[0].GUID != "digest-daily-20261018000000" || strings.Contains(parsed.Items[0].Content, "GUID:") {
+			t.Errorf("unexpected published digest: %+v", parsed.Items[0])
+		}
+	}
+}
+
+// test generating a digest with the model
+func TestGenerateDigest(t *testing.T) {
+	client := newTestClientWithAPI(t)
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 120*time.Second)
+	defer cancel()
+
+	for _, item := range []ItemToCache{
+		{Item: gofeed.Item{GUID: "guid-gen-1", Links: []string{"https://go.dev/blog"}, Categories: []string{"Go"}}, Title: "Go blog", Summary: "News about the Go programming language."},
+		{Item: gofeed.Item{GUID: "guid-gen-2", Links: []string{"https://github.com/meinside/rss-feeds-go"}}, Title: "rss-feeds-go", Summary: "A go utility package for handling RSS feeds."},
+	} {
+		if err := client.cache.Save(ctx, item); err != nil {
+			t.Fatalf("failed to save item: %s", err)
+		}
+	}
+
+	digest, err := client.GenerateDigest(ctx, DigestOptions{Period: DigestPeriodDaily})
+	if err != nil {
+		t.Errorf("failed to generate digest: %s", err)
+	} else {
+		log.Printf(">>> digest: %s", digest.Markdown())
+	}
+}
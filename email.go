@@ -0,0 +1,350 @@
+package rf
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"slices"
+	"strings"
+	"time"
+)
+
+////////////////
+//
+// (delivery of summarized items and digests as emails)
+//
+
+const (
+	defaultEmailMaxItems = 50
+
+	emailSinkPrefix = "email:" // prefix of the sink names of email recipients, for tracking deliveries
+)
+
+// EmailMarkMode is a type for how items are marked after they are emailed
+type EmailMarkMode string
+
+// EmailMarkMode constants
+const (
+	EmailMarkAsDelivered EmailMarkMode = "delivered" // tracked as delivered to each recipient, so they are not emailed to the same recipient again
+	EmailMarkAsRead      EmailMarkMode = "read"      // marked as read (for all recipients, only after all of them are emailed successfully)
+)
+
+// EmailRecipient is a struct for a recipient of emails
+type EmailRecipient struct {
+	Address string
+	Name    string // (optional)
+
+	Filter ListFilter                 // filter of items (only unread ones, unless `IncludeItemsMarkedAsRead` is set)
+	Match  func(item CachedItem) bool // additional filter of items (optional)
+}
+
+// EmailOptions is a struct for the options of emails
+type EmailOptions struct {
+	From     string // address of the sender
+	FromName string // (optional)
+	Subject  string // (default: generated)
+
+	MarkMode EmailMarkMode // (default: delivered)
+	MaxItems int           // maximum number of the newest items in an email (default: 50)
+}
+
+// emailItem is an item in the HTML body of emails
+type emailItem struct {
+	Title    string
+	Link     string
+	Comments string
+	Meta     string
+	Summary  template.HTML
+}
+
+// emailHTML is the template of HTML bodies of emails
+var emailHTML = template.Must(template.New("email").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"></head>
+<body style="font-family: sans-serif; line-height: 1.6; color: #222;">
+<h1>{{.Title}}</h1>
+{{with .Body}}<div>{{.}}</div>{{end}}
+{{range .Items}}
+<div style="margin-bottom: 2em;">
+  <h2>{{if .Link}}<a href="{{.Link}}">{{.Title}}</a>{{else}}{{.Title}}{{end}}</h2>
+  {{with .Meta}}<p style="color: #666; font-size: 0.9em;">{{.}}</p>{{end}}
+  <div>{{.Summary}}</div>
+  {{with .Comments}}<p><a href="{{.}}">Comments</a></p>{{end}}
+</div>
+<hr>
+{{end}}
+</body>
+</html>
+`))
+
+// EmailSummaries emails the summarized items to each recipient, filtered with their filters.
+//
+// Emailed items are marked with `opts.MarkMode`, and recipients without any items to email are skipped.
+//
+// With `EmailMarkAsRead`, no items are marked as read if emailing any recipient fails,
+// so they will be emailed again (to all recipients) next time.
+func (c *Client) EmailSummaries(ctx context.Context, server SMTPServer, recipients []EmailRecipient, opts EmailOptions) error {
+	markMode := cmp.Or(opts.MarkMode, EmailMarkAsDelivered)
+
+	var errs []error
+	var read []string
+	failed := false
+	for _, recipient := range recipients {
+		items, err := c.itemsToEmail(ctx, recipient, markMode, cmp.Or(opts.MaxItems, defaultEmailMaxItems))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to list items for '%s': %w", recipient.Address, err))
+			failed = true
+			continue
+		}
+		if len(items) <= 0 {
+			v(c.verbose, "no items to email to '%s'", recipient.Address)
+			continue
+		}
+
+		subject := cmp.Or(opts.Subject, fmt.Sprintf("%d new summaries (%s)", len(items), time.Now().Format("2006-01-02")))
+		text, html, err := renderEmailOfItems(subject, items)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to render email for '%s': %w", recipient.Address, err))
+			failed = true
+			continue
+		}
+		if err := c.email(ctx, server, recipient, opts, subject, text, html); err != nil {
+			errs = append(errs, err)
+			failed = true
+			continue
+		}
+
+		// mark emailed items
+		now := time.Now()
+		for _, item := range items {
+			if markMode == EmailMarkAsRead {
+				read = append(read, item.GUID)
+			} else if err := c.cache.SaveDelivery(ctx, Delivery{
+				Sink:        emailSinkPrefix + strings.ToLower(recipient.Address),
+				GUID:        item.GUID,
+				DeliveredAt: &now,
+				Attempts:    1,
+			}); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if len(read) > 0 && !failed {
+		slices.Sort(read)
+		for _, result := range c.cache.MarkManyAsRead(ctx, slices.Compact(read)) {
+			if result.Err != nil && !errors.Is(result.Err, ErrNotFound) { // (items could be deleted or evicted after emailed)
+				errs = append(errs, result.Err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// EmailDigest emails given digest to each recipient, then marks the items in it with `opts.MarkMode`.
+//
+// Filters of recipients are not applied to digests, and with `EmailMarkAsRead`,
+// no items are marked as read if emailing any recipient fails.
+func (c *Client) EmailDigest(ctx context.Context, server SMTPServer, recipients []EmailRecipient, digest Digest, opts EmailOptions) error {
+	markMode := cmp.Or(opts.MarkMode, EmailMarkAsDelivered)
+
+	subject := cmp.Or(opts.Subject, digest.Title)
+	text := redactText(digest.Markdown(), c.googleAIAPIKeys)
+	var html bytes.Buffer
+	if err := emailHTML.Execute(&html, map[string]any{
+		"Title": digest.Title,
+		"Body":  template.HTML(decorateHTML(redactText(digest.Content, c.googleAIAPIKeys))),
+	}); err != nil {
+		return fmt.Errorf("failed to render email of digest: %w", err)
+	}
+
+	var errs []error
+	failed := false
+	now := time.Now()
+	for _, recipient := range recipients {
+		if err := c.email(ctx, server, recipient, opts, subject, text, html.String()); err != nil {
+			errs = append(errs, err)
+			failed = true
+			continue
+		}
+
+		if markMode == EmailMarkAsDelivered {
+			for _, guid := range digest.ItemGUIDs {
+				if err := c.cache.SaveDelivery(ctx, Delivery{
+					Sink:        emailSinkPrefix + strings.ToLower(recipient.Address),
+					GUID:        guid,
+					DeliveredAt: &now,
+					Attempts:    1,
+				}); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+	if len(recipients) > 0 && !failed && markMode == EmailMarkAsRead && len(digest.ItemGUIDs) > 0 {
+		for _, result := range c.cache.MarkManyAsRead(ctx, digest.ItemGUIDs) {
+			if result.Err != nil && !errors.Is(result.Err, ErrNotFound) { // (items could be deleted after the digest)
+				errs = append(errs, result.Err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// list the items to email to `recipient`, newest first
+func (c *Client) itemsToEmail(ctx context.Context, recipient EmailRecipient, markMode EmailMarkMode, maxItems int) ([]CachedItem, error) {
+	items, err := c.cache.ListWithFilter(ctx, recipient.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	delivered := map[string]bool{}
+	if markMode == EmailMarkAsDelivered {
+		deliveries, err := c.cache.ListDeliveries(ctx, emailSinkPrefix+strings.ToLower(recipient.Address))
+		if err != nil {
+			return nil, err
+		}
+		for _, delivery := range deliveries {
+			delivered[delivery.GUID] = delivery.Delivered()
+		}
+	}
+
+	items = slices.DeleteFunc(items, func(item CachedItem) bool {
+		return len(item.Summary) <= 0 ||
+			isError(item.Summary) ||
+			delivered[item.GUID] ||
+			(recipient.Match != nil && !recipient.Match(item))
+	})
+	slices.SortStableFunc(items, func(a, b CachedItem) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	if len(items) > maxItems {
+		items = items[:maxItems]
+	}
+
+	return redactItems(items, c.googleAIAPIKeys), nil
+}
+
+// render the plain text and HTML bodies of an email of given items
+func renderEmailOfItems(title string, items []CachedItem) (text, html string, err error) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s\n\n", title)
+
+	var htmlItems []emailItem
+	for _, item := range items {
+		meta := strings.Join(slices.DeleteFunc([]string{item.Author, item.PublishDate}, func(s string) bool {
+			return len(s) <= 0
+		}), " | ")
+
+		fmt.Fprintf(&sb, "## %s\n\n", item.Title)
+		if len(item.Link) > 0 {
+			fmt.Fprintf(&sb, "%s\n", item.Link)
+		}
+		if len(meta) > 0 {
+			fmt.Fprintf(&sb, "%s\n", meta)
+		}
+		fmt.Fprintf(&sb, "\n%s\n\n", item.Summary)
+		if len(item.Comments) > 0 {
+			fmt.Fprintf(&sb, "Comments: %s\n\n", item.Comments)
+		}
+		sb.WriteString("----\n\n")
+
+		htmlItems = append(htmlItems, emailItem{
+			Title:    item.Title,
+			Link:     item.Link,
+			Comments: item.Comments,
+			Meta:     meta,
+			Summary:  template.HTML(decorateHTML(item.Summary)),
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := emailHTML.Execute(&buf, map[string]any{
+		"Title": title,
+		"Items": htmlItems,
+	}); err != nil {
+		return "", "", fmt.Errorf("failed to render email: %w", err)
+	}
+
+	return sb.String(), buf.String(), nil
+}
+
+// build and send an email to `recipient`
+func (c *Client) email(ctx context.Context, server SMTPServer, recipient EmailRecipient, opts EmailOptions, subject, text, html string) error {
+	from := mail.Address{Name: opts.FromName, Address: opts.From}
+	to := mail.Address{Name: recipient.Name, Address: recipient.Address}
+
+	msg, err := buildEmail(from, to, subject, text, html)
+	if err != nil {
+		return err
+	}
+
+	v(c.verbose, "emailing '%s' to '%s'", subject, recipient.Address)
+
+	if err := server.send(ctx, from.Address, []string{to.Address}, msg); err != nil {
+		return fmt.Errorf("failed to email '%s': %w", recipient.Address, err)
+	}
+	return nil
+}
+
+// build a multipart (plain text and HTML) email message
+func buildEmail(from, to mail.Address, subject, text, html string) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, part := range []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create part of email: %w", err)
+		}
+		qw := quotedprintable.NewWriter(w)
+		if _, err := qw.Write([]byte(part.content)); err != nil {
+			return nil, fmt.Errorf("failed to write part of email: %w", err)
+		}
+		if err := qw.Close(); err != nil {
+			return nil, fmt.Errorf("failed to write part of email: %w", err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build email: %w", err)
+	}
+
+	domain := "localhost"
+	if idx := strings.LastIndex(from.Address, "@"); idx >= 0 {
+		domain = from.Address[idx+1:]
+	}
+
+	var msg bytes.Buffer
+	for _, header := range [][2]string{
+		{"From", from.String()},
+		{"To", to.String()},
+		{"Subject", mime.QEncoding.Encode("utf-8", subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), guidHash(to.Address), domain)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", mw.Boundary())},
+	} {
+		fmt.Fprintf(&msg, "%s: %s\r\n", header[0], header[1])
+	}
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+
+	return msg.Bytes(), nil
+}
@@ -0,0 +1,362 @@
+package rf
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http/httptest"
+	"net/mail"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeSMTPServer is a minimal SMTP server for tests, which stores received messages
+type fakeSMTPServer struct {
+	listener  net.Listener
+	tlsConfig *tls.Config
+	startTLS  bool // whether STARTTLS is supported
+
+	mu       sync.Mutex
+	messages []fakeSMTPMessage
+	rejected string // recipient address which is rejected (optional)
+}
+
+// fakeSMTPMessage is a message received by `fakeSMTPServer`
+type fakeSMTPMessage struct {
+	from string
+	to   []string
+	data string
+	tls  bool
+	auth string
+}
+
+// start a fake SMTP server, with implicit TLS if `implicitTLS` is set
+func newFakeSMTPServer(t *testing.T, implicitTLS, startTLS bool) (server *fakeSMTPServer, clientTLSConfig *tls.Config) {
+	// (borrow the certificate of httptest)
+	ts := httptest.NewUnstartedServer(nil)
+	ts.StartTLS()
+	cert := ts.TLS.Certificates[0]
+	pool := x509.NewCertPool()
+	pool.AddCert(ts.Certificate())
+	ts.Close()
+
+	server = &fakeSMTPServer{
+		tlsConfig: &tls.Config{Certificates: []tls.Certificate{cert}},
+		startTLS:  startTLS,
+	}
+
+	var err error
+	if implicitTLS {
+		server.listener, err = tls.Listen("tcp", "127.0.0.1:0", server.tlsConfig)
+	} else {
+		server.listener, err = net.Listen("tcp", "127.0.0.1:0")
+	}
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err)
+	}
+	t.Cleanup(func() { _ = server.listener.Close() })
+
+	go func() {
+		for {
+			conn, err := server.listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn, implicitTLS)
+		}
+	}()
+
+	return server, &tls.Config{RootCAs: pool}
+}
+
+// port of the server
+func (s *fakeSMTPServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+// received messages
+func (s *fakeSMTPServer) received() []fakeSMTPMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]fakeSMTPMessage{}, s.messages...)
+}
+
+// reject given recipient address (or none if empty)
+func (s *fakeSMTPServer) reject(address string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rejected = address
+}
+
+// serve a connection
+func (s *fakeSMTPServer) serve(conn net.Conn, isTLS bool) {
+	defer func() { _ = conn.Close() }()
+
+	r, w := bufio.NewReader(conn), conn
+	reply := func(line string) { _, _ = io.WriteString(w, line+"\r\n") }
+
+	var msg fakeSMTPMessage
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch cmd {
+		case "EHLO", "HELO":
+			reply("250-localhost")
+			if s.startTLS && !isTLS {
+				reply("250-STARTTLS")
+			}
+			reply("250 AUTH PLAIN")
+		case "STARTTLS":
+			reply("220 ready to start tls")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, isTLS = tlsConn, true
+			r, w = bufio.NewReader(tlsConn), tlsConn
+		case "AUTH":
+			msg.auth = line
+			reply("235 authenticated")
+		case "MAIL":
+			msg = fakeSMTPMessage{from: line, tls: isTLS, auth: msg.auth}
+			reply("250 ok")
+		case "RCPT":
+			s.mu.Lock()
+			rejected := len(s.rejected) > 0 && strings.Contains(line, s.rejected)
+			s.mu.Unlock()
+			if rejected {
+				reply("550 mailbox unavailable")
+				continue
+			}
+			msg.to = append(msg.to, line)
+			reply("250 ok")
+		case "DATA":
+			reply("354 go ahead")
+			var sb strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				sb.WriteString(strings.TrimPrefix(line, "."))
+			}
+			msg.data = sb.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+// parse the plain text and HTML parts of given email
+func parseTestEmail(t *testing.T, data string) (subject, text, html string) {
+	msg, err := mail.ReadMessage(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to parse email: %s", err)
+	}
+	subject, _ = new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("unexpected content type of email: %s (%v)", mediaType, err)
+	}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart() // (quoted-printable is decoded automatically)
+		if err != nil {
+			break
+		}
+		bytes, _ := io.ReadAll(part)
+		switch {
+		case strings.HasPrefix(part.Header.Get("Content-Type"), "text/plain"):
+			text = string(bytes)
+		case strings.HasPrefix(part.Header.Get("Content-Type"), "text/html"):
+			html = string(bytes)
+		}
+	}
+	return subject, text, html
+}
+
+// test emailing summaries with STARTTLS
+func TestEmailSummaries(t *testing.T) {
+	ctx := context.Background()
+
+	dbc, err := newDBCache(filepath.Join(t.TempDir(), "email.db"))
+	if err != nil {
+		t.Fatalf("failed to create dbCache: %s", err)
+	}
+	defer func() { _ = dbc.Close() }()
+
+	smtpServer, tlsConfig := newFakeSMTPServer(t, false, true)
+	server := SMTPServer{
+		Host:      "127.0.0.1",
+		Port:      smtpServer.port(),
+		Username:  "user",
+		Password:  "password",
+		TLSConfig: tlsConfig,
+	}
+
+	client := NewClient([]string{"key"}, nil)
+	client.cache = dbc
+	for _, item := range []ItemToCache{
+		{Item: testFeedItem("email-1", "Go"), Title: "Go 한글", Summary: "Summary of **go**", FeedURL: "https://example.com/go.xml"},
+		{Item: testFeedItem("email-2", "Rust"), Title: "Rust", Summary: "Summary of rust", FeedURL: "https://example.com/rust.xml"},
+		{Item: testFeedItem("email-3", "Unsummarized"), Title: "Unsummarized"},
+	} {
+		if err := dbc.Save(ctx, item); err != nil {
+			t.Fatalf("failed to save item: %s", err)
+		}
+	}
+
+	recipients := []EmailRecipient{
+		{Address: "manager@example.com", Name: "Manager"},
+		{Address: "gopher@example.com", Filter: ListFilter{FeedURL: "https://example.com/go.xml"}},
+	}
+	opts := EmailOptions{From: "rss@example.com", FromName: "RSS", Subject: "Morning summaries"}
+	if err := client.EmailSummaries(ctx, server, recipients, opts); err != nil {
+		t.Fatalf("failed to email summaries: %s", err)
+	}
+
+	messages := smtpServer.received()
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 emails, got %d", len(messages))
+	}
+	if !messages[0].tls || !strings.Contains(messages[0].auth, "PLAIN") || !strings.Contains(messages[0].to[0], "manager@example.com") {
+		t.Errorf("unexpected email: %+v", messages[0])
+	}
+	subject, text, html := parseTestEmail(t, messages[0].data)
+	if subject != "Morning summaries" {
+		t.Errorf("unexpected subject: %s", subject)
+	}
+	for _, expected := range []string{"## Go 한글", "Summary of **go**", "## Rust"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("expected '%s' in plain text, got %s", expected, text)
+		}
+	}
+	for _, expected := range []string{`<a href="https://example.com/email-1">Go 한글</a>`, "<strong>go</strong>"} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("expected '%s' in html, got %s", expected, html)
+		}
+	}
+	if strings.Contains(text, "Unsummarized") {
+		t.Errorf("expected no unsummarized items, got %s", text)
+	}
+	if _, text, _ := parseTestEmail(t, messages[1].data); strings.Contains(text, "Rust") {
+		t.Errorf("expected items filtered for the recipient, got %s", text)
+	}
+
+	// not emailed twice to the same recipient
+	if err := dbc.Save(ctx, ItemToCache{Item: testFeedItem("email-4", "Zig"), Title: "Zig", Summary: "Summary of zig"}); err != nil {
+		t.Fatalf("failed to save item: %s", err)
+	}
+	if err := client.EmailSummaries(ctx, server, recipients, opts); err != nil {
+		t.Fatalf("failed to email summaries: %s", err)
+	}
+	if messages = smtpServer.received(); len(messages) != 3 {
+		t.Fatalf("expected only 1 more email, got %d", len(messages)-2)
+	}
+	if _, text, _ := parseTestEmail(t, messages[2].data); !strings.Contains(text, "Zig") || strings.Contains(text, "Rust") {
+		t.Errorf("expected only the new item, got %s", text)
+	}
+
+	// not marked as read when emailing any recipient fails
+	opts.MarkMode = EmailMarkAsRead
+	smtpServer.reject("rejected@example.com")
+	if err := client.EmailSummaries(ctx, server, []EmailRecipient{{Address: "reader@example.com"}, {Address: "rejected@example.com"}}, opts); err == nil || !strings.Contains(err.Error(), "rejected@example.com") {
+		t.Errorf("expected error for rejected recipient, got %v", err)
+	}
+	if items, _ := dbc.ListWithFilter(ctx, ListFilter{}); len(items) != 4 {
+		t.Errorf("expected items not to be marked as read, got %+v", items)
+	}
+	smtpServer.reject("")
+
+	// marked as read
+	if err := client.EmailSummaries(ctx, server, []EmailRecipient{{Address: "reader@example.com"}}, opts); err != nil {
+		t.Fatalf("failed to email summaries: %s", err)
+	}
+	if items, _ := dbc.ListWithFilter(ctx, ListFilter{}); len(items) != 1 || items[0].GUID != "email-3" {
+		t.Errorf("expected emailed items to be marked as read, got %+v", items)
+	}
+
+	// STARTTLS not supported
+	noTLSServer, _ := newFakeSMTPServer(t, false, false)
+	if err := client.EmailSummaries(ctx, SMTPServer{Host: "127.0.0.1", Port: noTLSServer.port()}, []EmailRecipient{{Address: "new@example.com", Filter: ListFilter{IncludeItemsMarkedAsRead: true}}}, opts); err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Errorf("expected error for missing STARTTLS, got %v", err)
+	}
+}
+
+// test emailing a digest with implicit TLS
+func TestEmailDigest(t *testing.T) {
+	ctx := context.Background()
+
+	smtpServer, tlsConfig := newFakeSMTPServer(t, true, false)
+	server := SMTPServer{
+		Host:      "127.0.0.1",
+		Port:      smtpServer.port(),
+		Security:  SMTPSecurityTLS,
+		TLSConfig: tlsConfig,
+	}
+
+	client := NewClient([]string{"key"}, nil)
+	_ = client.cache.Save(ctx, ItemToCache{Item: testFeedItem("digest-email-1", "Go"), Title: "Go", Summary: "summary"})
+
+	digest := Digest{
+		Period:    DigestPeriodDaily,
+		Title:     "Daily digest",
+		Content:   "## Go\n\n- [Go 1.30](https://example.com/go)",
+		ItemGUIDs: []string{"digest-email-1", "deleted-item"},
+	}
+	if err := client.EmailDigest(ctx, server, []EmailRecipient{{Address: "manager@example.com"}}, digest, EmailOptions{From: "rss@example.com", MarkMode: EmailMarkAsRead}); err != nil {
+		t.Fatalf("failed to email digest: %s", err)
+	}
+
+	messages := smtpServer.received()
+	if len(messages) != 1 || !messages[0].tls {
+		t.Fatalf("unexpected emails: %+v", messages)
+	}
+	subject, text, html := parseTestEmail(t, messages[0].data)
+	if subject != "Daily digest" || !strings.HasPrefix(text, "# Daily digest") || !strings.Contains(html, `<a href="https://example.com/go">Go 1.30</a>`) {
+		t.Errorf("unexpected email of digest: %s / %s / %s", subject, text, html)
+	}
+	if items, _ := client.cache.List(ctx, false); len(items) != 0 {
+		t.Errorf("expected items of digest to be marked as read, got %+v", items)
+	}
+}
+
+// test building multipart emails with encoded headers and bodies
+func TestBuildEmail(t *testing.T) {
+	long := strings.Repeat("가", 100)
+	msg, err := buildEmail(mail.Address{Address: "a@example.com"}, mail.Address{Name: "받는 사람", Address: "b@example.com"}, "제목", long, "<p>"+long+"</p>")
+	if err != nil {
+		t.Fatalf("failed to build email: %s", err)
+	}
+	if !strings.Contains(string(msg), "=?utf-8?q?") {
+		t.Errorf("expected encoded headers, got %s", msg)
+	}
+	subject, text, _ := parseTestEmail(t, string(msg))
+	if subject != "제목" || text != long {
+		t.Errorf("unexpected email: %s / %s", subject, text)
+	}
+}
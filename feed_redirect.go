@@ -0,0 +1,131 @@
+package rf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+)
+
+const (
+	maxFeedRedirects = 10
+
+	feedMoveConfirmations = 2 // number of consecutive fetches needed for confirming a move of feed
+)
+
+// ErrRedirectLoop is returned when a feed's redirects are looping.
+var ErrRedirectLoop = errors.New("redirect loop")
+
+// feedRedirects is a struct for tracking redirects while fetching a feed
+type feedRedirects struct {
+	statuses []int // status codes of redirect responses
+	finalURL string
+
+	next func(req *http.Request, via []*http.Request) error // `CheckRedirect` of the http client (optional)
+}
+
+// check given redirect, tracking its status and detecting loops
+//
+// (used as `http.Client.CheckRedirect`)
+func (r *feedRedirects) check(req *http.Request, via []*http.Request) error {
+	for _, prev := range via {
+		if prev.URL.String() == req.URL.String() {
+			return fmt.Errorf("%w: '%s'", ErrRedirectLoop, req.URL)
+		}
+	}
+	if len(via) >= maxFeedRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxFeedRedirects)
+	}
+
+	if req.Response != nil {
+		r.statuses = append(r.statuses, req.Response.StatusCode)
+	}
+	r.finalURL = req.URL.String()
+
+	if r.next != nil {
+		return r.next(req, via)
+	}
+	return nil
+}
+
+// return the final url if all redirects were permanent (301 or 308)
+func (r *feedRedirects) permanentlyMovedTo() string {
+	if len(r.statuses) <= 0 {
+		return ""
+	}
+	for _, status := range r.statuses {
+		if status != http.StatusMovedPermanently && status != http.StatusPermanentRedirect {
+			return ""
+		}
+	}
+	return r.finalURL
+}
+
+// SetFeedMovedCallback sets the callback which is called when a feed's url is changed
+// after its move was confirmed. (permanent redirects or changes of `<atom:link rel="self">`)
+func (c *Client) SetFeedMovedCallback(fn func(oldURL, newURL string)) {
+	c.feedMovedCallback = fn
+}
+
+// check if the feed has moved with given fetch result, updating `feed` for confirming it
+//
+// It returns the new url if the move was confirmed, or an empty string.
+func (c *Client) checkFeedMove(feed *Feed, result feedFetchResult) (movedTo string) {
+	var self string
+	if result.fetched != nil {
+		self = result.fetched.FeedLink
+	}
+
+	var candidate string
+	if result.movedTo != "" && result.movedTo != feed.URL {
+		candidate = result.movedTo
+	} else if self != "" && self != feed.URL &&
+		((feed.SelfLink != "" && feed.SelfLink != self) || feed.MovedTo == self) {
+		candidate = self
+	}
+	feed.SelfLink = self
+
+	if candidate == "" {
+		feed.MovedTo, feed.MoveConfirmations = "", 0
+		return ""
+	}
+	if candidate == feed.MovedTo {
+		feed.MoveConfirmations++
+	} else {
+		feed.MovedTo, feed.MoveConfirmations = candidate, 1
+	}
+
+	v(c.verbose, "feed '%s' seems to be moved to '%s' (%d/%d)", feed.URL, candidate, feed.MoveConfirmations, feedMoveConfirmations)
+
+	if feed.MoveConfirmations >= feedMoveConfirmations {
+		return candidate
+	}
+	return ""
+}
+
+// move the feed at `oldURL` to `newURL`, keeping its cached items
+func (c *Client) moveFeed(ctx context.Context, oldURL, newURL string) error {
+	log.Printf("moving feed '%s' to '%s'", oldURL, newURL)
+
+	if err := c.cache.MoveFeed(ctx, oldURL, newURL); err != nil {
+		return err
+	}
+
+	c.stateMu.Lock()
+	if i := slices.Index(c.feedsURLs, oldURL); i >= 0 {
+		feedsURLs := slices.Clone(c.feedsURLs) // (not to modify the caller's slice, or the ones being iterated)
+		feedsURLs[i] = newURL
+		c.feedsURLs = feedsURLs
+	}
+	c.stateMu.Unlock()
+	if opts, exists := c.getFeedOptions(oldURL); exists {
+		c.SetFeedOptions(newURL, opts)
+	}
+	if c.feedMovedCallback != nil {
+		c.feedMovedCallback(oldURL, newURL)
+	}
+
+	return nil
+}
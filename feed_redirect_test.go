@@ -0,0 +1,136 @@
+package rf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// rss feed with given self link for testing
+func testRSSFeed(selfLink string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">
+  <channel>
+    <title>Moving Feed</title>
+    <atom:link href="` + selfLink + `" rel="self" type="application/rss+xml"/>
+    <item>
+      <title>Article</title>
+      <link>https://example.com/article</link>
+      <guid>guid-moving</guid>
+    </item>
+  </channel>
+</rss>`
+}
+
+// test moving feeds with permanent redirects
+func TestFeedPermanentRedirect(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/temporary", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusFound)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testRSSFeed(""))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ctx := context.Background()
+	oldURL, newURL := server.URL+"/old", server.URL+"/new"
+
+	client := NewClient([]string{"key"}, []string{oldURL, server.URL + "/temporary"})
+	var moved [][2]string
+	client.SetFeedMovedCallback(func(oldURL, newURL string) {
+		moved = append(moved, [2]string{oldURL, newURL})
+	})
+
+	// not confirmed yet
+	if _, err := client.FetchFeeds(ctx, false, 7); err != nil {
+		t.Fatalf("FetchFeeds failed: %s", err)
+	}
+	if len(moved) != 0 {
+		t.Fatalf("expected no moves yet, got %v", moved)
+	}
+	_ = client.cache.Save(ctx, ItemToCache{Item: testFeedItem("guid-moving", "Article"), Title: "Article", Summary: "Summary", FeedURL: oldURL})
+
+	// confirmed
+	feeds, err := client.FetchFeeds(ctx, false, 7)
+	if err != nil {
+		t.Fatalf("FetchFeeds failed: %s", err)
+	}
+	if len(moved) != 1 || moved[0] != [2]string{oldURL, newURL} {
+		t.Fatalf("expected a move from '%s' to '%s', got %v", oldURL, newURL, moved)
+	}
+	if feedURLOf(feeds[0]) != newURL || client.feedsURLs[0] != newURL {
+		t.Errorf("expected feed url to be changed to '%s'", newURL)
+	}
+	if _, err := client.cache.FetchFeed(ctx, oldURL); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected old feed to be gone, got %v", err)
+	}
+
+	// items keep their identity
+	items, _ := client.ListCachedItemsWithFilter(ctx, ListFilter{FeedURL: newURL})
+	if len(items) != 1 || items[0].GUID != "guid-moving" {
+		t.Errorf("expected cached item to be moved along, got %+v", items)
+	}
+
+	// temporary redirects are not moves
+	_, _ = client.FetchFeeds(ctx, false, 7)
+	if len(moved) != 1 {
+		t.Errorf("expected no moves with temporary redirects, got %v", moved)
+	}
+}
+
+// test detecting redirect loops
+func TestFeedRedirectLoop(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/b", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/a", http.StatusMovedPermanently)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewClient([]string{"key"}, []string{server.URL + "/a"})
+	if _, err := client.FetchFeeds(context.Background(), false, 7); !errors.Is(err, ErrRedirectLoop) {
+		t.Errorf("expected ErrRedirectLoop, got %v", err)
+	}
+}
+
+// test moving feeds with changed self links
+func TestFeedSelfLinkChange(t *testing.T) {
+	var selfLink atomic.Value
+	selfLink.Store("https://example.com/feed")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testRSSFeed(selfLink.Load().(string)))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := NewClient([]string{"key"}, []string{server.URL})
+
+	// unchanged (mismatched) self links are not moves
+	for range 3 {
+		_, _ = client.FetchFeeds(ctx, false, 7)
+	}
+	if client.feedsURLs[0] != server.URL {
+		t.Fatalf("expected no moves, got '%s'", client.feedsURLs[0])
+	}
+
+	selfLink.Store("https://example.com/moved-feed")
+	for range feedMoveConfirmations {
+		_, _ = client.FetchFeeds(ctx, false, 7)
+	}
+	if client.feedsURLs[0] != "https://example.com/moved-feed" {
+		t.Errorf("expected feed to be moved with changed self link, got '%s'", client.feedsURLs[0])
+	}
+}
@@ -52,17 +52,31 @@ referring to the summarized content:
 
 	summarizedContentEmpty = `Summarized content was empty.`
 
+	summarizePromptVersion = 1 // NOTE: increase this when the prompts above are changed (recorded in revisions)
+
+	digestPromptFormat = `Write a digest of the following summarized items in %[1]s language, for the period from %[2]s to %[3]s.
+
+Respond in Markdown, following these rules:
+- Start with a short overview of the period in a few sentences.
+- Write a section with a '## ' heading for each group of related items in <group></group> tags. (groups are just hints, so they can be merged or split)
+- In each section, describe the items briefly, linking each of them with its title and link like: [title](link).
+- Do not omit any item, and do not make up any link.
+
+%[4]s`
+
+	digestPromptVersion = 1 // NOTE: increase this when the prompt above is changed (recorded in digests)
+
 	requestTimeoutSeconds              = 30
 	generationTimeoutSeconds           = 3 * 60 // timeout seconds for generation (summary + translation)
 	generationTimeoutSecondsForYoutube = 5 * 60 // timeout seconds for summary of youtube video
 )
 
-// markCooldown records a cooldown expiry for the given combo index based on
+// markCooldown records a cooldown expiry for the given combo based on
 // the quota error's RetryInfo (falling back to the default).
-func (c *Client) markCooldown(idx int, err error, now time.Time) {
+func (c *Client) markCooldown(combo keyModelCombo, err error, now time.Time) {
 	c.cooldownMu.Lock()
 	defer c.cooldownMu.Unlock()
-	c.cooldownUntil[idx] = now.Add(cooldownDuration(err))
+	c.cooldownUntil[combo] = now.Add(cooldownDuration(err))
 }
 
 // newGeminiClientForCombo builds a gemini-things client for a specific combo
@@ -79,17 +93,18 @@ func (c *Client) newGeminiClientForCombo(combo keyModelCombo) (gtc *gt.Client, e
 	return gtc, nil
 }
 
-// withFailover picks an available combo, runs `run`, and on a quota (429)
+// withFailover picks an available one of `combos`, runs `run`, and on a quota (429)
 // error marks that combo's cooldown and retries with the next available
 // combo. Non-429 errors are returned immediately. Returns ErrNoAvailableAPIKey
 // if every combo is exhausted or in cooldown.
 func (c *Client) withFailover(
 	now func() time.Time,
+	combos []keyModelCombo,
 	run func(gtc *gt.Client, model string) error,
 ) (usedModel string, err error) {
-	attempts := len(c.combos)
+	attempts := len(combos)
 	for range attempts {
-		combo, idx, ok := c.pickAvailableCombo(now())
+		combo, _, ok := c.pickAvailableCombo(combos, now())
 		if !ok {
 			return usedModel, ErrNoAvailableAPIKey
 		}
@@ -107,7 +122,7 @@ func (c *Client) withFailover(
 			return usedModel, nil
 		}
 		if gt.IsQuotaExceeded(runErr) {
-			c.markCooldown(idx, runErr, now())
+			c.markCooldown(combo, runErr, now())
 			continue
 		}
 		return usedModel, runErr
@@ -125,14 +140,16 @@ func closeGeminiClient(gtc *gt.Client) {
 // translate and summarize given things
 func (c *Client) translateAndSummarize(
 	ctx context.Context,
+	combos []keyModelCombo,
 	prompt string,
 	files ...[]byte,
-) (usedModel, translatedTitle, summarizedContent string, err error) {
+) (usedModel, translatedTitle, summarizedContent string, usage TokenUsage, err error) {
 	buffer := strings.Builder{}
 
-	usedModel, err = c.withFailover(time.Now, func(gtc *gt.Client, model string) error {
+	usedModel, err = c.withFailover(time.Now, combos, func(gtc *gt.Client, model string) error {
 		buffer.Reset()
 		translatedTitle = ""
+		usage = TokenUsage{}
 		setCustomFileConverters(gtc)
 
 		// prompt & files
@@ -157,6 +174,7 @@ func (c *Client) translateAndSummarize(
 		if gerr != nil {
 			return gerr
 		}
+		usage = tokenUsageOf(result)
 
 		for _, cand := range result.Candidates {
 			if cand.Content != nil {
@@ -191,20 +209,22 @@ func (c *Client) translateAndSummarize(
 		return nil
 	})
 
-	return usedModel, translatedTitle, buffer.String(), err
+	return usedModel, translatedTitle, buffer.String(), usage, err
 }
 
 // summarize given url
 func (c *Client) summarizeURL(
 	ctx context.Context,
+	combos []keyModelCombo,
 	title string,
 	url string,
 	desiredLanguage string,
-) (usedModel, untouchedTitle string, summarizedContent string, err error) {
+) (usedModel, untouchedTitle string, summarizedContent string, usage TokenUsage, err error) {
 	outBuffer := new(strings.Builder)
 
-	usedModel, err = c.withFailover(time.Now, func(gtc *gt.Client, model string) error {
+	usedModel, err = c.withFailover(time.Now, combos, func(gtc *gt.Client, model string) error {
 		outBuffer.Reset()
+		usage = TokenUsage{}
 
 		// prompts
 		prompts := []gt.Prompt{
@@ -227,6 +247,7 @@ func (c *Client) summarizeURL(
 		if gerr != nil {
 			return gerr
 		}
+		usage = tokenUsageOf(result)
 
 		if len(result.Candidates) > 0 {
 			candidate := result.Candidates[0]
@@ -251,22 +272,24 @@ func (c *Client) summarizeURL(
 		return nil
 	})
 
-	return usedModel, title, outBuffer.String(), err
+	return usedModel, title, outBuffer.String(), usage, err
 }
 
 // translate and summarize given youtube url
 func (c *Client) translateAndSummarizeYouTube(
 	ctx context.Context,
+	settings summarySettings,
 	title string,
 	url string,
-) (usedModel, translatedTitle, summarizedContent string, err error) {
-	usedModel, err = c.withFailover(time.Now, func(gtc *gt.Client, model string) error {
+) (usedModel, translatedTitle, summarizedContent string, usage TokenUsage, err error) {
+	usedModel, err = c.withFailover(time.Now, settings.combos, func(gtc *gt.Client, model string) error {
 		translatedTitle, summarizedContent = "", ""
+		usage = TokenUsage{}
 		setCustomFileConverters(gtc)
 
 		// prompts
 		prompts := []gt.Prompt{
-			gt.PromptFromText(fmt.Sprintf(summarizeYouTubePromptFormat, c.desiredLanguage, title)),
+			gt.PromptFromText(fmt.Sprintf(summarizeYouTubePromptFormat, settings.language, title)),
 			gt.PromptFromURI(url, `video/mp4`),
 		}
 
@@ -282,6 +305,7 @@ func (c *Client) translateAndSummarizeYouTube(
 		if gerr != nil {
 			return gerr
 		}
+		usage = tokenUsageOf(result)
 
 		if len(result.Candidates) > 0 {
 			candidate := result.Candidates[0]
@@ -304,7 +328,71 @@ func (c *Client) translateAndSummarizeYouTube(
 		return nil
 	})
 
-	return usedModel, translatedTitle, summarizedContent, err
+	return usedModel, translatedTitle, summarizedContent, usage, err
+}
+
+// generate a digest with given prompt
+func (c *Client) generateDigest(
+	ctx context.Context,
+	prompt string,
+) (usedModel, digest string, usage TokenUsage, err error) {
+	outBuffer := new(strings.Builder)
+
+	usedModel, err = c.withFailover(time.Now, c.summarySettings(nil, "").combos, func(gtc *gt.Client, model string) error {
+		outBuffer.Reset()
+		usage = TokenUsage{}
+
+		// context with timeout (prompts => contents)
+		ctxContents, cancelContents := context.WithTimeout(ctx, requestTimeoutSeconds*time.Second)
+		defer cancelContents()
+
+		contents, cerr := gtc.PromptsToContents(ctxContents, []gt.Prompt{gt.PromptFromText(prompt)}, nil)
+		if cerr != nil {
+			return fmt.Errorf("failed to convert prompts to contents: %w", cerr)
+		}
+
+		result, gerr := gtc.Generate(ctx, contents, genOptions(generationTimeoutSeconds*time.Second, maxRetryCount))
+		if gerr != nil {
+			return gerr
+		}
+		usage = tokenUsageOf(result)
+
+		if len(result.Candidates) > 0 {
+			candidate := result.Candidates[0]
+
+			if content := candidate.Content; content != nil {
+				for _, part := range content.Parts {
+					if len(part.Text) > 0 {
+						outBuffer.WriteString(part.Text)
+					}
+				}
+			} else {
+				if candidate.FinishReason != genai.FinishReasonUnspecified {
+					return fmt.Errorf("generation was terminated due to: %s", candidate.FinishReason)
+				}
+				return fmt.Errorf("returned content of candidate is nil: %s", Prettify(candidate))
+			}
+		}
+
+		if outBuffer.Len() <= 0 {
+			return fmt.Errorf("generated digest was empty")
+		}
+		return nil
+	})
+
+	return usedModel, outBuffer.String(), usage, err
+}
+
+// token usage of given generation result
+func tokenUsageOf(result *genai.GenerateContentResponse) TokenUsage {
+	if result == nil || result.UsageMetadata == nil {
+		return TokenUsage{}
+	}
+	return TokenUsage{
+		PromptTokens:     result.UsageMetadata.PromptTokenCount,
+		CandidatesTokens: result.UsageMetadata.CandidatesTokenCount,
+		TotalTokens:      result.UsageMetadata.TotalTokenCount,
+	}
 }
 
 // extractTranslatedTitleAndSummarizedContent extracts translated title and summarized content from a function call
@@ -347,6 +435,21 @@ const (
 	fnParamDescSummarizedContent            = `Summarized content.`
 )
 
+// options for generation (without tools)
+func genOptions(
+	timeout time.Duration,
+	retryCount int,
+) *genai.GenerateContentConfig {
+	return &genai.GenerateContentConfig{
+		HTTPOptions: &genai.HTTPOptions{
+			Timeout: &timeout,
+			RetryOptions: &genai.HTTPRetryOptions{
+				Attempts: new(int32(retryCount + 1)),
+			},
+		},
+	}
+}
+
 // options for generation (with url context)
 func genOptionsWithURLContext(
 	timeout time.Duration,
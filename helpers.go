@@ -3,16 +3,18 @@ package rf
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"html"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"regexp"
 	"slices"
 	"strings"
-	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/tailscale/hujson"
@@ -53,17 +55,14 @@ func v(verbose bool, format string, v ...any) {
 }
 
 // get content type from given url with HTTP HEAD
-func getContentType(ctx context.Context, url string, verbose bool) (contentType string, err error) {
-	client := &http.Client{
-		Timeout: time.Duration(fetchURLTimeoutSeconds) * time.Second,
-	}
-
+func getContentType(ctx context.Context, client *http.Client, url, userAgent string, verbose bool) (contentType string, err error) {
 	v(verbose, "fetching head from url: %s", url)
 
 	req, err := http.NewRequestWithContext(ctx, "HEAD", url, nil)
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
+	req.Header.Set(`User-Agent`, userAgent)
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -75,18 +74,14 @@ func getContentType(ctx context.Context, url string, verbose bool) (contentType
 }
 
 // fetch the content from given url and convert it for prompting.
-func fetchURLContent(ctx context.Context, url string, verbose bool) (content []byte, contentType string, err error) {
-	client := &http.Client{
-		Timeout: time.Duration(fetchURLTimeoutSeconds) * time.Second,
-	}
-
+func fetchURLContent(ctx context.Context, client *http.Client, url, userAgent string, limits ContentSizeLimits, verbose bool) (content []byte, contentType string, err error) {
 	v(verbose, "fetching contents from url: %s", url)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, contentType, fmt.Errorf("failed to create request: %w", err)
 	}
-	req.Header.Set(`User-Agent`, fakeUserAgent)
+	req.Header.Set(`User-Agent`, userAgent)
 	req.Header.Set(`Accept`, fakeAccept)
 	req.Header.Set(`Cache-Control`, `no-cache`)
 	req.Header.Set(`Sec-Fetch-Dest`, `document`)
@@ -106,45 +101,57 @@ func fetchURLContent(ctx context.Context, url string, verbose bool) (content []b
 
 	if resp.StatusCode == 200 {
 		if isTextFormattableContent(contentType) { // then format as text prompt
+			// NOTE: oversized text contents are truncated with a marker
+			limit := limits.forContentType(contentType)
+
+			var body []byte
+			var truncated bool
+			if body, truncated, err = readTruncated(articleReader(resp.Body, contentType), limit); err != nil { // (transcoded to UTF-8)
+				content = fmt.Appendf(nil, urlToTextFormat, url, contentType, "Failed to read this document.")
+				return content, contentType, fmt.Errorf("failed to read '%s' document from '%s': %w", contentType, url, err)
+			}
+			if truncated {
+				v(verbose, "truncating content from url: %s (exceeded %d bytes)", url, limit)
+			}
+
+			var text string
 			if strings.HasPrefix(contentType, "text/html") ||
 				strings.HasPrefix(contentType, "application/xhtml") ||
 				strings.HasPrefix(contentType, "application/xml") {
 				var doc *goquery.Document
-				if doc, err = goquery.NewDocumentFromReader(resp.Body); err == nil {
+				if doc, err = goquery.NewDocumentFromReader(bytes.NewReader(body)); err == nil {
 					// NOTE: removing unwanted things here
 					_ = doc.Find("script").Remove()                   // javascripts
 					_ = doc.Find("link[rel=\"stylesheet\"]").Remove() // css links
 					_ = doc.Find("style").Remove()                    // embeded css tyles
 
-					content = fmt.Appendf(nil, urlToTextFormat, url, contentType, removeConsecutiveEmptyLines(doc.Text()))
+					text = removeConsecutiveEmptyLines(doc.Text())
 				} else {
 					content = fmt.Appendf(nil, urlToTextFormat, url, contentType, "Failed to read this HTML document.")
-					err = fmt.Errorf("failed to read '%s' document from '%s': %w", contentType, url, err)
+					return content, contentType, fmt.Errorf("failed to read '%s' document from '%s': %w", contentType, url, err)
 				}
 			} else if strings.HasPrefix(contentType, "text/") {
-				var bytes []byte
-				if bytes, err = io.ReadAll(resp.Body); err == nil {
-					// NOTE: removing redundant empty lines
-					content = fmt.Appendf(nil, urlToTextFormat, url, contentType, removeConsecutiveEmptyLines(string(bytes)))
-				} else {
-					content = fmt.Appendf(nil, urlToTextFormat, url, contentType, "Failed to read this document.")
-					err = fmt.Errorf("failed to read '%s' document from '%s': %w", contentType, url, err)
-				}
+				// NOTE: removing redundant empty lines
+				text = removeConsecutiveEmptyLines(string(body))
 			} else if strings.HasPrefix(contentType, "application/json") {
-				var bytes []byte
-				if bytes, err = io.ReadAll(resp.Body); err == nil {
-					content = fmt.Appendf(nil, urlToTextFormat, url, contentType, string(bytes))
-				} else {
-					content = fmt.Appendf(nil, urlToTextFormat, url, contentType, "Failed to read this document.")
-					err = fmt.Errorf("failed to read '%s' document from '%s': %w", contentType, url, err)
-				}
+				text = string(body)
 			} else {
 				content = fmt.Appendf(nil, urlToTextFormat, url, contentType, fmt.Sprintf("Content type '%s' not supported.", contentType))
-				err = fmt.Errorf("content type '%s' not supported for url: '%s'", contentType, url)
+				return content, contentType, fmt.Errorf("content type '%s' not supported for url: '%s'", contentType, url)
+			}
+			if truncated {
+				text += fmt.Sprintf(truncatedMarkerFormat, limit)
 			}
+
+			content = fmt.Appendf(nil, urlToTextFormat, url, contentType, text)
 		} else if isFileContent(contentType) {
-			if content, err = io.ReadAll(resp.Body); err != nil { // then read bytes as a file
-				err = fmt.Errorf("failed to read bytes from url '%s': %w", url, err)
+			// NOTE: oversized files are rejected (not to be uploaded to the model)
+			limit := limits.forContentType(contentType)
+			if resp.ContentLength > limit {
+				return nil, contentType, fmt.Errorf("%w: file of %d bytes from url '%s' exceeds %d bytes", ErrContentTooLarge, resp.ContentLength, url, limit)
+			}
+			if content, err = io.ReadAll(newLimitedReader(resp.Body, limit)); err != nil { // then read bytes as a file
+				return nil, contentType, fmt.Errorf("failed to read bytes from url '%s': %w", url, err)
 			}
 		} else {
 			content = fmt.Appendf(nil, urlToTextFormat, url, contentType, fmt.Sprintf("Content type '%s' not supported.", contentType))
@@ -215,6 +222,12 @@ func redactItems(items []CachedItem, baddies []string) []CachedItem {
 	return redacted
 }
 
+// short hex-encoded hash of given guid (eg. for filenames)
+func guidHash(guid string) string {
+	hash := sha256.Sum256([]byte(guid))
+	return hex.EncodeToString(hash[:8])
+}
+
 // check if given body string contains error prefix
 func isError(body string) bool {
 	return strings.Contains(body, ErrorPrefixSummaryFailedWithError)
@@ -241,6 +254,12 @@ func decorateHTML(body string) string {
 	return body
 }
 
+// check if given string is an absolute http(s) URL
+func isHTTPURL(str string) bool {
+	parsed, err := url.Parse(str)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && len(parsed.Host) > 0
+}
+
 // check if given URL is a YouTube video
 func isYouTubeURL(url string) bool {
 	return slices.ContainsFunc([]string{
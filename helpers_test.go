@@ -14,7 +14,7 @@ func TestGetContentType(t *testing.T) {
 		"https://github.com/meinside": "text/html",
 		"https://raw.githubusercontent.com/meinside/meinside/main/res/profile/sloth.jpg": "image/jpeg",
 	} {
-		typ, err := getContentType(ctx, url, false)
+		typ, err := getContentType(ctx, defaultHTTPClient, url, fakeUserAgent, false)
 		if err != nil {
 			t.Errorf("failed to get content type of '%s': %s", url, err)
 		}
@@ -0,0 +1,109 @@
+package rf
+
+import (
+	"cmp"
+	"maps"
+	"net/http"
+	"time"
+)
+
+// default http client for outbound requests (shared for reusing connections)
+var defaultHTTPClient = &http.Client{
+	Timeout: time.Duration(fetchURLTimeoutSeconds) * time.Second,
+}
+
+// FeedOptions is a struct for the options of http requests to a feed
+//
+// NOTE: they are applied only to the requests of the feed itself, not to its items' urls.
+type FeedOptions struct {
+	Username string // for basic auth (optional)
+	Password string
+
+	Headers   map[string]string // additional headers (optional)
+	Cookies   []*http.Cookie    // (optional)
+	UserAgent string            // user agent which overrides the client's (optional)
+}
+
+// SetHTTPClient sets the http client for all outbound requests. (eg. with a proxy or a cookie jar)
+//
+// If `client` is nil, the default one (with a timeout of `fetchURLTimeoutSeconds` seconds) is used.
+func (c *Client) SetHTTPClient(client *http.Client) {
+	c.httpClient = client
+	c.updateSafeHTTPClient()
+}
+
+// SetHTTPTransport sets the transport of the http client for all outbound requests.
+func (c *Client) SetHTTPTransport(transport http.RoundTripper) {
+	client := *c.baseHTTPClient()
+	client.Transport = transport
+
+	c.httpClient = &client
+	c.updateSafeHTTPClient()
+}
+
+// SetUserAgent sets the user agent of outbound requests.
+//
+// If `userAgent` is empty, a browser-like one is used.
+func (c *Client) SetUserAgent(userAgent string) {
+	c.userAgent = userAgent
+}
+
+// SetFeedOptions sets the options of http requests to the feed at `url`.
+func (c *Client) SetFeedOptions(url string, opts FeedOptions) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	feedOptions := maps.Clone(c.feedOptions)
+	if feedOptions == nil {
+		feedOptions = map[string]FeedOptions{}
+	}
+	feedOptions[url] = opts
+
+	c.feedOptions = feedOptions
+}
+
+// return the options of the feed at `url`
+func (c *Client) getFeedOptions(url string) (opts FeedOptions, exists bool) {
+	c.stateMu.RLock()
+	defer c.stateMu.RUnlock()
+
+	opts, exists = c.feedOptions[url]
+	return opts, exists
+}
+
+// return the http client for outbound requests
+func (c *Client) getHTTPClient() *http.Client {
+	if c.safeHTTPClient != nil {
+		return c.safeHTTPClient
+	}
+	return c.baseHTTPClient()
+}
+
+// return the http client set by the caller (or the default one)
+func (c *Client) baseHTTPClient() *http.Client {
+	if c.httpClient != nil {
+		return c.httpClient
+	}
+	return defaultHTTPClient
+}
+
+// return the user agent for outbound requests
+func (c *Client) getUserAgent() string {
+	return cmp.Or(c.userAgent, fakeUserAgent)
+}
+
+// apply the options of the feed at `url` to given request
+func (c *Client) applyFeedOptions(req *http.Request, url string) {
+	opts, _ := c.getFeedOptions(url)
+
+	req.Header.Set("User-Agent", cmp.Or(opts.UserAgent, c.getUserAgent()))
+	if opts.Username != "" || opts.Password != "" {
+		req.SetBasicAuth(opts.Username, opts.Password)
+	}
+	for k, v := range opts.Headers {
+		req.Header.Set(k, v)
+	}
+	for _, cookie := range opts.Cookies {
+		req.AddCookie(cookie)
+	}
+}
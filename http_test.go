@@ -0,0 +1,96 @@
+package rf
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// round tripper which counts requests
+type countingTransport struct {
+	count atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.count.Add(1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// test per-feed options of http requests
+func TestFeedOptions(t *testing.T) {
+	rssFeed := `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Private Feed</title>
+  </channel>
+</rss>`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		cookie, _ := r.Cookie("session")
+		if !ok || username != "user" || password != "pass" ||
+			r.Header.Get("X-Token") != "token" ||
+			r.UserAgent() != "feed-agent" ||
+			cookie == nil || cookie.Value != "abc" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, rssFeed)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := NewClient([]string{"key"}, []string{server.URL})
+
+	if _, err := client.FetchFeeds(ctx, false, 7); err == nil {
+		t.Fatal("expected an error without feed options")
+	}
+	_ = client.EnableFeed(ctx, server.URL) // (reset backoff)
+
+	client.SetFeedOptions(server.URL, FeedOptions{
+		Username:  "user",
+		Password:  "pass",
+		Headers:   map[string]string{"X-Token": "token"},
+		Cookies:   []*http.Cookie{{Name: "session", Value: "abc"}},
+		UserAgent: "feed-agent",
+	})
+	if feeds, err := client.FetchFeeds(ctx, false, 7); err != nil || len(feeds) != 1 {
+		t.Errorf("expected 1 feed with feed options, got %d (%v)", len(feeds), err)
+	}
+}
+
+// test injected http transport and user agent
+func TestHTTPTransport(t *testing.T) {
+	var userAgents sync.Map // method => user agent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents.Store(r.Method, r.UserAgent())
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, "some text")
+	}))
+	defer server.Close()
+
+	transport := &countingTransport{}
+
+	client := NewClient([]string{"key"}, nil)
+	client.SetHTTPTransport(transport)
+	client.SetUserAgent("custom-agent")
+
+	if _, _, err := client.fetch(context.Background(), 0, server.URL); err != nil {
+		t.Fatalf("fetch failed: %s", err)
+	}
+	if transport.count.Load() != 2 { // HEAD + GET
+		t.Errorf("expected 2 requests through the transport, got %d", transport.count.Load())
+	}
+	for _, method := range []string{http.MethodHead, http.MethodGet} {
+		if userAgent, _ := userAgents.Load(method); userAgent != "custom-agent" {
+			t.Errorf("expected user agent 'custom-agent' for %s, got '%v'", method, userAgent)
+		}
+	}
+	if defaultHTTPClient.Transport != nil {
+		t.Error("expected the default http client to be untouched")
+	}
+}
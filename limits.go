@@ -0,0 +1,113 @@
+package rf
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"unicode/utf8"
+)
+
+const (
+	defaultMaxFeedSize = 10 * 1024 * 1024 // 10 MiB
+	defaultMaxTextSize = 1 * 1024 * 1024  // 1 MiB
+	defaultMaxFileSize = 20 * 1024 * 1024 // 20 MiB
+
+	truncatedMarkerFormat = "\n\n[... truncated: the content exceeded %d bytes ...]"
+)
+
+// ErrContentTooLarge is returned when a fetched content exceeds its maximum size.
+var ErrContentTooLarge = errors.New("content too large")
+
+// ContentSizeLimits is a struct for the maximum sizes of fetched contents in bytes
+//
+// Zero values mean the default ones.
+type ContentSizeLimits struct {
+	Feed int64 // feed documents (rejected when exceeded)
+	Text int64 // text contents, eg. HTML or plain text (truncated with a marker when exceeded)
+	File int64 // file contents, eg. PDF (rejected when exceeded, before being uploaded to the model)
+
+	ByContentType map[string]int64 // limits for specific media types (eg. "application/pdf") which override `Text` or `File`
+}
+
+// maximum size of feed documents
+func (l ContentSizeLimits) feed() int64 {
+	return cmp.Or(l.Feed, defaultMaxFeedSize)
+}
+
+// maximum size of contents with given content type
+func (l ContentSizeLimits) forContentType(contentType string) int64 {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if limit, exists := l.ByContentType[mediaType]; exists && limit > 0 {
+			return limit
+		}
+	}
+	if isFileContent(contentType) {
+		return cmp.Or(l.File, defaultMaxFileSize)
+	}
+	return cmp.Or(l.Text, defaultMaxTextSize)
+}
+
+// SetContentSizeLimits sets the maximum sizes of fetched feeds and contents.
+func (c *Client) SetContentSizeLimits(limits ContentSizeLimits) {
+	c.contentSizeLimits = limits
+}
+
+// limitedReader is a reader which fails with `ErrContentTooLarge` after reading more than `remaining` bytes
+type limitedReader struct {
+	r         io.Reader
+	limit     int64
+	remaining int64
+}
+
+// return a reader which fails after reading more than `limit` bytes from `r`
+func newLimitedReader(r io.Reader, limit int64) *limitedReader {
+	return &limitedReader{r: r, limit: limit, remaining: limit}
+}
+
+// Read reads from the underlying reader.
+func (l *limitedReader) Read(p []byte) (n int, err error) {
+	if l.remaining < 0 {
+		return 0, fmt.Errorf("%w: exceeded %d bytes", ErrContentTooLarge, l.limit)
+	}
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1] // (one more byte for detecting excess)
+	}
+	n, err = l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n, fmt.Errorf("%w: exceeded %d bytes", ErrContentTooLarge, l.limit)
+	}
+	return n, err
+}
+
+// read at most `limit` bytes from `r`, reporting whether the rest was truncated
+func readTruncated(r io.Reader, limit int64) (data []byte, truncated bool, err error) {
+	if data, err = io.ReadAll(io.LimitReader(r, limit+1)); err != nil {
+		return nil, false, err
+	}
+	if int64(len(data)) <= limit {
+		return data, false, nil
+	}
+	return trimIncompleteRune(data[:limit]), true, nil
+}
+
+// truncate given text to `limit` bytes with a marker, if it exceeds the limit
+func truncateText(text string, limit int64) string {
+	if int64(len(text)) <= limit {
+		return text
+	}
+	return string(trimIncompleteRune([]byte(text[:limit]))) + fmt.Sprintf(truncatedMarkerFormat, limit)
+}
+
+// trim the last incomplete utf-8 character cut in the middle, if any
+func trimIncompleteRune(data []byte) []byte {
+	for i := 0; i < utf8.UTFMax-1 && len(data) > 0; i++ {
+		if r, size := utf8.DecodeLastRune(data); r != utf8.RuneError || size != 1 {
+			break
+		}
+		data = data[:len(data)-1]
+	}
+	return data
+}
@@ -0,0 +1,116 @@
+package rf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// test reading with limits
+func TestLimitedReader(t *testing.T) {
+	if data, err := io.ReadAll(newLimitedReader(strings.NewReader("12345"), 5)); err != nil || string(data) != "12345" {
+		t.Errorf("expected content within limit to be read, got %q (%v)", data, err)
+	}
+	if _, err := io.ReadAll(newLimitedReader(strings.NewReader("123456"), 5)); !errors.Is(err, ErrContentTooLarge) {
+		t.Errorf("expected ErrContentTooLarge, got %v", err)
+	}
+
+	data, truncated, err := readTruncated(strings.NewReader("가나다"), 4) // (3 bytes per character)
+	if err != nil || !truncated || string(data) != "가" {
+		t.Errorf("expected truncation at character boundary, got %q (%v, %v)", data, truncated, err)
+	}
+
+	if text := truncateText("abcdef", 3); text != "abc"+fmt.Sprintf(truncatedMarkerFormat, 3) {
+		t.Errorf("unexpected truncated text: %q", text)
+	}
+}
+
+// test limits for content types
+func TestContentSizeLimits(t *testing.T) {
+	limits := ContentSizeLimits{
+		Text:          100,
+		ByContentType: map[string]int64{"application/json": 10},
+	}
+
+	for contentType, expected := range map[string]int64{
+		"text/html; charset=utf-8": 100,
+		"application/json":         10,
+		"application/pdf":          defaultMaxFileSize,
+	} {
+		if limit := limits.forContentType(contentType); limit != expected {
+			t.Errorf("expected limit %d for '%s', got %d", expected, contentType, limit)
+		}
+	}
+	if limits.feed() != defaultMaxFeedSize {
+		t.Errorf("expected default feed limit, got %d", limits.feed())
+	}
+}
+
+// test size limits of fetched contents and feeds
+func TestFetchSizeLimits(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/text", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, strings.Repeat("a", 100))
+	})
+	mux.HandleFunc("/file", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		fmt.Fprint(w, strings.Repeat("a", 100))
+	})
+	mux.HandleFunc("/feed", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?><rss version="2.0"><channel><title>Huge</title>`)
+		w.(http.Flusher).Flush() // (without content length)
+		fmt.Fprint(w, strings.Repeat("<item><title>item</title></item>", 100))
+		fmt.Fprint(w, `</channel></rss>`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ctx := context.Background()
+	limits := ContentSizeLimits{Feed: 1000, Text: 10, File: 10}
+
+	content, _, err := fetchURLContent(ctx, defaultHTTPClient, server.URL+"/text", fakeUserAgent, limits, false)
+	if err != nil || !strings.Contains(string(content), fmt.Sprintf(truncatedMarkerFormat, 10)) {
+		t.Errorf("expected truncated text content, got %q (%v)", content, err)
+	}
+
+	if _, _, err := fetchURLContent(ctx, defaultHTTPClient, server.URL+"/file", fakeUserAgent, limits, false); !errors.Is(err, ErrContentTooLarge) {
+		t.Errorf("expected ErrContentTooLarge for oversized file, got %v", err)
+	}
+
+	client := NewClient([]string{"key"}, []string{server.URL + "/feed"})
+	client.SetContentSizeLimits(limits)
+	if _, err := client.FetchFeeds(ctx, false, 7); !errors.Is(err, ErrContentTooLarge) || !strings.Contains(err.Error(), "failed to read feeds") {
+		t.Errorf("expected ErrContentTooLarge for oversized feed, got %v", err)
+	}
+
+	// oversized files are neither fetched again nor summarized via URL context
+	var requested atomic.Int32
+	countingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		if r.Method == http.MethodGet {
+			requested.Add(1)
+		}
+		fmt.Fprint(w, strings.Repeat("a", 100))
+	}))
+	defer countingServer.Close()
+	summarized, err := client.summarize(ctx, "title", countingServer.URL)
+	if !errors.Is(err, ErrContentTooLarge) || summarized.usedModel != "" {
+		t.Errorf("expected ErrContentTooLarge without a model, got %v (%s)", err, summarized.usedModel)
+	}
+	if count := requested.Load(); count != 1 {
+		t.Errorf("expected oversized file to be requested once, got %d", count)
+	}
+
+	// oversized files are not uploaded
+	if _, err := client.summarizeFetched(ctx, client.summarySettings(nil, ""), "title", server.URL+"/file", make([]byte, 100), "application/pdf", time.Now()); !errors.Is(err, ErrContentTooLarge) {
+		t.Errorf("expected ErrContentTooLarge before summarizing oversized file, got %v", err)
+	}
+}
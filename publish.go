@@ -0,0 +1,277 @@
+package rf
+
+import (
+	"cmp"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gorilla/feeds"
+)
+
+// PublishFormat is a type for the formats of published feeds
+type PublishFormat string
+
+// PublishFormat constants
+const (
+	PublishFormatRSS  PublishFormat = "rss"  // RSS 2.0
+	PublishFormatAtom PublishFormat = "atom" // Atom 1.0
+	PublishFormatJSON PublishFormat = "json" // JSON Feed 1.1
+)
+
+// ParsePublishFormat parses given string (eg. "rss", "atom", or "json") as a publish format.
+func ParsePublishFormat(str string) (PublishFormat, error) {
+	format := PublishFormat(strings.ToLower(strings.TrimSpace(str)))
+	switch format {
+	case PublishFormatRSS, PublishFormatAtom, PublishFormatJSON:
+		return format, nil
+	}
+	return "", fmt.Errorf("not a supported publish format: '%s'", str)
+}
+
+// ContentType returns the content type of the feeds published in this format.
+func (f PublishFormat) ContentType() string {
+	switch f {
+	case PublishFormatAtom:
+		return PublishContentTypeAtom
+	case PublishFormatJSON:
+		return PublishContentTypeJSON
+	default:
+		return PublishContentType
+	}
+}
+
+// PublishSortOrder is a type for the orders of published items
+type PublishSortOrder int
+
+// PublishSortOrder constants
+const (
+	PublishSortAsGiven     PublishSortOrder = iota // keep the order of given items
+	PublishSortNewestFirst                         // newest cached items first
+	PublishSortOldestFirst                         // oldest cached items first
+)
+
+// default template of footers appended to successful summaries
+//
+// NOTE: GUIDs which are not http(s) urls (eg. "tag:..." or "urn:uuid:...") are printed as plain texts
+const defaultPublishFooterTemplate = `{{if .Comments}}Comments: <a href="{{.Comments}}">{{.Comments}}</a>{{else if isHTTPURL .GUID}}GUID: <a href="{{.GUID}}">{{.GUID}}</a>{{else}}GUID: {{.GUID}}{{end}}`
+
+// functions available in footer templates
+var publishFooterFuncs = template.FuncMap{
+	"isHTTPURL": isHTTPURL, // whether given string is an absolute http(s) url
+}
+
+// PublishOptions is a struct for the options of published feeds
+type PublishOptions struct {
+	Format PublishFormat // (default: rss)
+
+	Title       string
+	Link        string
+	Description string
+	Author      string
+	Email       string
+	Language    string        // eg. "en-US" (optional)
+	ImageURL    string        // url of the feed's image (optional)
+	TTL         time.Duration // how long the feed can be cached before refreshed (optional, rss only)
+	HubURL      string        // url of the websub hub advertised in the feed (optional, see `SetWebSubHub`)
+	SelfURL     string        // url where the feed itself is served, advertised as its websub topic (optional)
+
+	Limit     int // maximum number of items (0 = unlimited)
+	SortOrder PublishSortOrder
+
+	IncludeUnsummarized    bool // include items which are not summarized yet (with their descriptions as contents)
+	IncludeFailedSummaries bool // include items whose summaries failed (with their error messages and original contents)
+	IncludeDescription     bool // include the original descriptions of items
+
+	FooterTemplate string // html/template of footers appended to successful summaries, executed with a `PublishFooter` (default: comments or GUID link), with an `isHTTPURL` function
+	OmitFooter     bool
+}
+
+// PublishFooter is a struct for the values given to the footer templates
+//
+// Values are escaped by `html/template` in the context they are used.
+type PublishFooter struct {
+	Title    string
+	Link     string
+	Comments string
+	GUID     string
+	FeedURL  string
+}
+
+// Publish returns bytes of given cached items in `format`, with its content type.
+func (c *Client) Publish(
+	format PublishFormat,
+	title, link, description, author, email string,
+	items []CachedItem,
+) (bytes []byte, contentType string, err error) {
+	return c.PublishWithOptions(items, PublishOptions{
+		Format:                 format,
+		Title:                  title,
+		Link:                   link,
+		Description:            description,
+		Author:                 author,
+		Email:                  email,
+		IncludeFailedSummaries: true,
+		IncludeDescription:     true,
+	})
+}
+
+// PublishWithOptions returns bytes of given cached items with `opts`, with its content type.
+func (c *Client) PublishWithOptions(
+	items []CachedItem,
+	opts PublishOptions,
+) (bytes []byte, contentType string, err error) {
+	format := cmp.Or(opts.Format, PublishFormatRSS)
+
+	feedItems, err := buildFeedItems(items, opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	feed := &feeds.Feed{
+		Title:       opts.Title,
+		Link:        &feeds.Link{Href: opts.Link},
+		Description: opts.Description,
+		Author:      &feeds.Author{Name: opts.Author, Email: opts.Email},
+		Created:     time.Now(),
+	}
+	for _, item := range feedItems {
+		feed.Items = append(feed.Items, item.Item)
+	}
+	feed.Updated = latestUpdate(feedItems)
+	if opts.ImageURL != "" {
+		feed.Image = &feeds.Image{Url: opts.ImageURL, Title: opts.Title, Link: opts.Link}
+	}
+
+	switch format {
+	case PublishFormatRSS:
+		rssFeed := (&feeds.Rss{Feed: feed}).RssFeed()
+		rssFeed.Language = opts.Language
+		rssFeed.Ttl = int(opts.TTL.Minutes())
+		bytes, err = xml.MarshalIndent(newRSSWithMedia(rssFeed, feedItems, opts.HubURL, opts.SelfURL), "", "  ")
+	case PublishFormatAtom:
+		atomFeed := (&feeds.Atom{Feed: feed}).AtomFeed()
+		atomFeed.Logo = opts.ImageURL
+		bytes, err = xml.MarshalIndent(newAtomWithMedia(atomFeed, feedItems, opts.Language, opts.HubURL, opts.SelfURL), "", "  ")
+	case PublishFormatJSON:
+		jsonFeed := (&feeds.JSON{Feed: feed}).JSONFeed()
+		jsonFeed.Language = opts.Language
+		jsonFeed.Icon = opts.ImageURL
+		addJSONMedia(jsonFeed, feedItems)
+		jsonFeed.FeedUrl = opts.SelfURL
+		if len(opts.HubURL) > 0 {
+			jsonFeed.Hubs = []*feeds.JSONHub{{Type: "WebSub", Url: opts.HubURL}}
+		}
+		bytes, err = json.MarshalIndent(jsonFeed, "", "  ")
+	default:
+		return nil, "", fmt.Errorf("not a supported publish format: '%s'", format)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to publish feeds in '%s': %w", format, err)
+	}
+	return bytes, format.ContentType(), nil
+}
+
+// build feed items (shared across formats) from given cached items
+func buildFeedItems(items []CachedItem, opts PublishOptions) (feedItems []publishedItem, err error) {
+	var footer *template.Template
+	if !opts.OmitFooter {
+		if footer, err = template.New("footer").Funcs(publishFooterFuncs).Parse(cmp.Or(opts.FooterTemplate, defaultPublishFooterTemplate)); err != nil {
+			return nil, fmt.Errorf("failed to parse footer template: %w", err)
+		}
+	}
+
+	items = slices.DeleteFunc(slices.Clone(items), func(item CachedItem) bool {
+		if len(item.Summary) <= 0 { // NOTE: not summarized yet
+			return !opts.IncludeUnsummarized
+		}
+		if isError(item.Summary) {
+			return !opts.IncludeFailedSummaries
+		}
+		return false
+	})
+
+	switch opts.SortOrder {
+	case PublishSortNewestFirst:
+		slices.SortStableFunc(items, func(a, b CachedItem) int {
+			return b.CreatedAt.Compare(a.CreatedAt)
+		})
+	case PublishSortOldestFirst:
+		slices.SortStableFunc(items, func(a, b CachedItem) int {
+			return a.CreatedAt.Compare(b.CreatedAt)
+		})
+	}
+
+	if opts.Limit > 0 && len(items) > opts.Limit {
+		items = items[:opts.Limit]
+	}
+
+	for _, item := range items {
+		var content string
+		if len(item.Summary) <= 0 {
+			content = item.Description
+		} else {
+			content = decorateHTML(item.Summary)
+		}
+
+		// NOTE: if the summary was not successful, it is a concatenated string of the error message and original content
+		if footer != nil && len(item.Summary) > 0 && !isError(item.Summary) {
+			// if it was a successful summary, append the footer (eg. comments or GUID of the original content)
+			var rendered strings.Builder
+			if err := footer.Execute(&rendered, PublishFooter{
+				Title:    item.Title,
+				Link:     item.Link,
+				Comments: item.Comments,
+				GUID:     item.GUID,
+				FeedURL:  item.FeedURL,
+			}); err != nil {
+				return nil, fmt.Errorf("failed to execute footer template: %w", err)
+			}
+			if rendered.Len() > 0 {
+				content += `<br><br>` + rendered.String()
+			}
+		}
+
+		feedItem := &feeds.Item{
+			Id:    item.GUID,
+			Title: item.Title,
+			Link: &feeds.Link{
+				Href: item.Link,
+			},
+			Content: content,
+			Created: item.CreatedAt,
+			Updated: item.UpdatedAt,
+		}
+		if opts.IncludeDescription {
+			feedItem.Description = item.Description
+		}
+		if updated, err := time.Parse(time.RFC3339, item.UpdateDate); err == nil && updated.After(feedItem.Updated) {
+			feedItem.Updated = updated // (updated after summarized)
+		}
+
+		feedItems = append(feedItems, publishedItem{
+			Item:       feedItem,
+			imageURL:   item.ImageURL,
+			categories: item.Categories,
+			enclosures: item.Enclosures,
+		})
+	}
+	return feedItems, nil
+}
+
+// return the latest update time of given feed items (or now, if there is none)
+func latestUpdate(feedItems []publishedItem) (latest time.Time) {
+	for _, item := range feedItems {
+		if updated := item.Updated; updated.After(latest) {
+			latest = updated
+		}
+	}
+	if latest.IsZero() {
+		latest = time.Now()
+	}
+	return latest
+}
@@ -0,0 +1,252 @@
+package rf
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+////////////////
+//
+// (feeds published separately, eg. per source feed or category)
+//
+
+// PublishContentTypeOPML is the content type of the published index
+const PublishContentTypeOPML = `text/x-opml`
+
+// PublishedFeedKind is a type for the kinds of published feeds
+type PublishedFeedKind string
+
+// PublishedFeedKind constants
+const (
+	PublishedFeedKindSource   PublishedFeedKind = "source"   // items from a source feed
+	PublishedFeedKindCategory PublishedFeedKind = "category" // items in a category
+	PublishedFeedKindCustom   PublishedFeedKind = "custom"   // items matching a custom filter
+)
+
+// PublishedFeed is a struct for a feed published separately
+type PublishedFeed struct {
+	Name        string // unique name (eg. for urls)
+	Kind        PublishedFeedKind
+	Title       string
+	Description string
+
+	Filter ListFilter
+	Match  func(item CachedItem) bool // additional filter of items (optional)
+}
+
+// SetCustomPublishedFeeds sets the feeds published with custom filters.
+//
+// They are listed before (and take precedence over) the ones generated per source feed and category.
+func (c *Client) SetCustomPublishedFeeds(feeds []PublishedFeed) error {
+	feeds = slices.Clone(feeds)
+
+	names := map[string]bool{}
+	for i, feed := range feeds {
+		if len(feed.Name) <= 0 {
+			return fmt.Errorf("name of published feed at %d is empty", i)
+		}
+		if names[feed.Name] {
+			return fmt.Errorf("duplicated name of published feeds: '%s'", feed.Name)
+		}
+		names[feed.Name] = true
+
+		feeds[i].Kind = PublishedFeedKindCustom
+	}
+
+	c.customPublishedFeeds = feeds
+
+	return nil
+}
+
+// ListPublishedFeeds returns the feeds which can be published separately:
+// custom ones, ones per source feed, and ones per category of cached items.
+func (c *Client) ListPublishedFeeds(ctx context.Context) (published []PublishedFeed, err error) {
+	names := map[string]bool{}
+	add := func(feed PublishedFeed) {
+		if names[feed.Name] {
+			return
+		}
+		names[feed.Name] = true
+
+		published = append(published, feed)
+	}
+
+	// custom ones
+	for _, feed := range c.customPublishedFeeds {
+		feed.Title = cmp.Or(feed.Title, feed.Name)
+		add(feed)
+	}
+
+	// per source feed
+	titles := map[string]string{}
+	feeds, err := c.cache.ListFeeds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, feed := range feeds {
+		titles[feed.URL] = feed.Title
+	}
+	for _, feedURL := range c.getFeedsURLs() {
+		title := cmp.Or(titles[feedURL], feedURL)
+		add(PublishedFeed{
+			Name:        stableName("feed-", feedURL),
+			Kind:        PublishedFeedKindSource,
+			Title:       title,
+			Description: fmt.Sprintf("Summarized items from '%s'", title),
+			Filter:      ListFilter{IncludeItemsMarkedAsRead: true, FeedURL: feedURL},
+		})
+	}
+
+	// per category
+	categories, err := c.cache.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, category := range categories {
+		add(PublishedFeed{
+			Name:        stableName("category-", category),
+			Kind:        PublishedFeedKindCategory,
+			Title:       category,
+			Description: fmt.Sprintf("Summarized items in category '%s'", category),
+			Filter:      ListFilter{IncludeItemsMarkedAsRead: true, Category: category},
+		})
+	}
+
+	return published, nil
+}
+
+// PublishFeed returns bytes of the published feed with `name`, with its content type.
+//
+// Title and description of `opts` are overridden by the ones of the published feed.
+func (c *Client) PublishFeed(ctx context.Context, name string, opts PublishOptions) (bytes []byte, contentType string, err error) {
+	published, err := c.ListPublishedFeeds(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	idx := slices.IndexFunc(published, func(feed PublishedFeed) bool {
+		return feed.Name == name
+	})
+	if idx < 0 {
+		return nil, "", fmt.Errorf("%w: no published feed named '%s'", ErrNotFound, name)
+	}
+	feed := published[idx]
+
+	items, err := c.ListCachedItemsWithFilter(ctx, feed.Filter)
+	if err != nil {
+		return nil, "", err
+	}
+	if feed.Match != nil {
+		items = slices.DeleteFunc(items, func(item CachedItem) bool {
+			return !feed.Match(item)
+		})
+	}
+
+	opts.Title = feed.Title
+	opts.Description = feed.Description
+
+	return c.PublishWithOptions(items, opts)
+}
+
+// opml is an OPML 2.0 document
+type opml struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    struct {
+		Title       string `xml:"title"`
+		DateCreated string `xml:"dateCreated"`
+	} `xml:"head"`
+	Body struct {
+		Outlines []*opmlOutline `xml:"outline"`
+	} `xml:"body"`
+}
+
+// opmlOutline is an outline of OPML 2.0
+type opmlOutline struct {
+	Text        string         `xml:"text,attr"`
+	Type        string         `xml:"type,attr,omitempty"`
+	Description string         `xml:"description,attr,omitempty"`
+	XMLURL      string         `xml:"xmlUrl,attr,omitempty"`
+	Outlines    []*opmlOutline `xml:"outline"`
+}
+
+// PublishIndex returns OPML bytes (text/x-opml) of the published feeds, grouped by their kinds.
+//
+// Each feed's url will be `baseURL` joined with its name. (eg. "https://example.com/feeds/category-tech")
+func (c *Client) PublishIndex(ctx context.Context, title, baseURL string) (bytes []byte, err error) {
+	published, err := c.ListPublishedFeeds(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := opml{Version: "2.0"}
+	doc.Head.Title = title
+	doc.Head.DateCreated = time.Now().Format(time.RFC1123Z)
+
+	groups := map[PublishedFeedKind]*opmlOutline{}
+	for _, feed := range published {
+		feedURL, err := url.JoinPath(baseURL, feed.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build url of published feed '%s': %w", feed.Name, err)
+		}
+
+		group, exists := groups[feed.Kind]
+		if !exists {
+			group = &opmlOutline{Text: string(feed.Kind)}
+			groups[feed.Kind] = group
+			doc.Body.Outlines = append(doc.Body.Outlines, group)
+		}
+		group.Outlines = append(group.Outlines, &opmlOutline{
+			Text:        feed.Title,
+			Type:        "rss",
+			Description: feed.Description,
+			XMLURL:      feedURL,
+		})
+	}
+
+	if bytes, err = xml.MarshalIndent(doc, "", "  "); err != nil {
+		return nil, fmt.Errorf("failed to publish index: %w", err)
+	}
+	return append([]byte(xml.Header), bytes...), nil
+}
+
+// convert given string to a lowercased one with letters, digits, and dashes only
+func slugify(str string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(str) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+			dash = false
+		} else if !dash && sb.Len() > 0 {
+			sb.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "-")
+}
+
+// return `prefix` with the slug of `str`, which depends only on `str` itself
+//
+// If the slug is not identical to `str` (eg. "C++" or "C#" => "c"), a short hash of `str` is appended,
+// so that names don't collide or change when other strings are added.
+func stableName(prefix, str string) string {
+	slug := slugify(str)
+	if slug == str {
+		return prefix + slug
+	}
+
+	hash := sha256.Sum256([]byte(str))
+	if len(slug) <= 0 {
+		return prefix + hex.EncodeToString(hash[:4])
+	}
+	return prefix + slug + "-" + hex.EncodeToString(hash[:4])
+}
@@ -0,0 +1,154 @@
+package rf
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// test publishing feeds per source feed, category, and custom filter
+func TestPublishFeeds(t *testing.T) {
+	ctx := context.Background()
+
+	dbClient, err := NewClientWithDB([]string{"key"}, []string{"https://example.com/a.xml", "https://example.com/b.xml"}, filepath.Join(t.TempDir(), "published.db"))
+	if err != nil {
+		t.Fatalf("failed to create client: %s", err)
+	}
+	defer func() { _ = dbClient.Close() }()
+
+	for _, client := range []*Client{
+		NewClient([]string{"key"}, []string{"https://example.com/a.xml", "https://example.com/b.xml"}),
+		dbClient,
+	} {
+		for _, item := range []ItemToCache{
+			{Item: gofeed.Item{GUID: "guid-a1", Categories: []string{"Go", "tech"}}, Title: "A1", Summary: "summary a1", FeedURL: "https://example.com/a.xml"},
+			{Item: gofeed.Item{GUID: "guid-a2", Categories: []string{"tech"}}, Title: "A2", Summary: "summary a2 with key", FeedURL: "https://example.com/a.xml"},
+			{Item: gofeed.Item{GUID: "guid-b1", Categories: []string{"기술"}}, Title: "B1", Summary: "summary b1", FeedURL: "https://example.com/b.xml"},
+			{Item: gofeed.Item{GUID: "guid-b2"}, Title: "B2", Summary: "summary b2", FeedURL: "https://example.com/b.xml"},
+		} {
+			if err := client.cache.Save(ctx, item); err != nil {
+				t.Fatalf("failed to save item: %s", err)
+			}
+		}
+		if categories, err := client.cache.ListCategories(ctx); err != nil || !slices.Equal(categories, []string{"Go", "tech", "기술"}) {
+			t.Errorf("unexpected categories: %v (%v)", categories, err)
+		}
+		if err := client.cache.SaveFeed(ctx, Feed{URL: "https://example.com/a.xml", Title: "Feed A"}); err != nil {
+			t.Fatalf("failed to save feed: %s", err)
+		}
+
+		if err := client.SetCustomPublishedFeeds([]PublishedFeed{
+			{Name: "a", Title: "Dup A"},
+			{Name: "a", Title: "Dup A again"},
+		}); err == nil {
+			t.Errorf("expected error for duplicated names")
+		}
+		if err := client.SetCustomPublishedFeeds([]PublishedFeed{
+			{
+				Name:  "starred",
+				Title: "Starred",
+				Match: func(item CachedItem) bool {
+					return strings.HasSuffix(item.GUID, "1")
+				},
+				Filter: ListFilter{IncludeItemsMarkedAsRead: true},
+			},
+		}); err != nil {
+			t.Fatalf("failed to set custom published feeds: %s", err)
+		}
+
+		// index
+		published, err := client.ListPublishedFeeds(ctx)
+		if err != nil {
+			t.Fatalf("failed to list published feeds: %s", err)
+		}
+		var names []string
+		for _, feed := range published {
+			names = append(names, feed.Name)
+		}
+		if strings.Join(names, ",") != "starred,feed-https-example-com-a-xml-5cc30f15,feed-https-example-com-b-xml-108c7de5,category-go-6cc8519b,category-tech,category-기술" {
+			t.Errorf("unexpected published feeds: %v", names)
+		}
+		if published[1].Title != "Feed A" || published[1].Kind != PublishedFeedKindSource {
+			t.Errorf("unexpected published feed: %+v", published[1])
+		}
+
+		// published feeds
+		for name, expected := range map[string][]string{
+			"starred":                               {"A1", "B1"},
+			"feed-https-example-com-a-xml-5cc30f15": {"A1", "A2"},
+			"category-tech":                         {"A1", "A2"},
+			"category-기술":                           {"B1"},
+		} {
+			bytes, _, err := client.PublishFeed(ctx, name, PublishOptions{Format: PublishFormatJSON})
+			if err != nil {
+				t.Fatalf("failed to publish feed '%s': %s", name, err)
+			}
+			parsed, err := gofeed.NewParser().ParseString(string(bytes))
+			if err != nil {
+				t.Fatalf("failed to parse published feed '%s': %s", name, err)
+			}
+			var titles []string
+			for _, item := range parsed.Items {
+				titles = append(titles, item.Title)
+
+				if strings.Contains(item.Description+item.Content, "key") {
+					t.Errorf("expected API key to be redacted in '%s', got %q", name, item.Description+item.Content)
+				}
+			}
+			slices.Sort(titles)
+			if strings.Join(titles, ",") != strings.Join(expected, ",") {
+				t.Errorf("expected items %v in '%s', got %v", expected, name, titles)
+			}
+		}
+		if _, _, err := client.PublishFeed(ctx, "unknown", PublishOptions{}); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound for unknown published feed, got %v", err)
+		}
+
+		// opml
+		opml, err := client.PublishIndex(ctx, "Index", "https://example.com/feeds/")
+		if err != nil {
+			t.Fatalf("failed to publish index: %s", err)
+		}
+		for _, expected := range []string{
+			`<outline text="custom">`,
+			`xmlUrl="https://example.com/feeds/category-tech"`,
+			`xmlUrl="https://example.com/feeds/category-%EA%B8%B0%EC%88%A0"`,
+		} {
+			if !strings.Contains(string(opml), expected) {
+				t.Errorf("expected '%s' in index, got %s", expected, opml)
+			}
+		}
+	}
+}
+
+// test slugs of published feeds' names
+func TestSlugify(t *testing.T) {
+	for str, expected := range map[string]string{
+		"Hello, World!":        "hello-world",
+		"hnrss.org/newest?a=1": "hnrss-org-newest-a-1",
+		"  --Go--  ":           "go",
+		"한국어 뉴스":               "한국어-뉴스",
+	} {
+		if slug := slugify(str); slug != expected {
+			t.Errorf("expected '%s' for '%s', got '%s'", expected, str, slug)
+		}
+	}
+
+	// names which don't depend on other strings
+	for str, expected := range map[string]string{
+		"tech": "category-tech",
+		"기술":   "category-기술",
+		"C++":  "category-c-f1deb75f",
+		"C#":   "category-c-04022884",
+		"+++":  "category-29f5099b",
+	} {
+		if name := stableName("category-", str); name != expected {
+			t.Errorf("expected '%s' for '%s', got '%s'", expected, str, name)
+		}
+	}
+}
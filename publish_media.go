@@ -0,0 +1,202 @@
+package rf
+
+import (
+	"encoding/xml"
+	"math"
+	"strconv"
+
+	"github.com/gorilla/feeds"
+)
+
+////////////////
+//
+// (media of published items and websub links, which are not supported by `feeds`)
+//
+
+const (
+	mediaNamespace = "http://search.yahoo.com/mrss/"
+	atomNamespace  = "http://www.w3.org/2005/Atom"
+)
+
+// publishedItem is a feed item with its media
+type publishedItem struct {
+	*feeds.Item
+
+	imageURL   string
+	categories []string
+	enclosures []Enclosure
+}
+
+// mediaThumbnail is a `<media:thumbnail>` element of Media RSS
+type mediaThumbnail struct {
+	XMLName xml.Name `xml:"media:thumbnail"`
+	URL     string   `xml:"url,attr"`
+}
+
+// return a thumbnail element of given image url (or nil if it is empty)
+func newMediaThumbnail(imageURL string) *mediaThumbnail {
+	if len(imageURL) <= 0 {
+		return nil
+	}
+	return &mediaThumbnail{URL: imageURL}
+}
+
+// rssWithMedia is an RSS 2.0 document with media of items
+type rssWithMedia struct {
+	XMLName          xml.Name `xml:"rss"`
+	Version          string   `xml:"version,attr"`
+	ContentNamespace string   `xml:"xmlns:content,attr"`
+	MediaNamespace   string   `xml:"xmlns:media,attr"`
+	AtomNamespace    string   `xml:"xmlns:atom,attr,omitempty"`
+	Channel          *rssChannelWithMedia
+}
+
+// rssChannelWithMedia is an RSS 2.0 channel with media of items and websub links
+type rssChannelWithMedia struct {
+	*feeds.RssFeed
+	AtomLinks []rssAtomLink       `xml:"atom:link"`
+	Items     []*rssItemWithMedia `xml:"item"`
+}
+
+// rssAtomLink is an `<atom:link>` element in RSS 2.0 channels
+type rssAtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+// rssItemWithMedia is an RSS 2.0 item with multiple categories and enclosures, and a thumbnail
+type rssItemWithMedia struct {
+	*feeds.RssItem
+	Categories []string              `xml:"category"`
+	Enclosures []*feeds.RssEnclosure `xml:"enclosure"`
+	Thumbnail  *mediaThumbnail
+}
+
+// return an RSS 2.0 document of `rssFeed` with media of `items`, and websub links of `hubURL` and `selfURL` (optional)
+//
+// NOTE: `items` should be in the same order with the ones of `rssFeed`.
+func newRSSWithMedia(rssFeed *feeds.RssFeed, items []publishedItem, hubURL, selfURL string) *rssWithMedia {
+	channel := &rssChannelWithMedia{RssFeed: rssFeed}
+	if len(selfURL) > 0 {
+		channel.AtomLinks = append(channel.AtomLinks, rssAtomLink{Href: selfURL, Rel: "self", Type: PublishContentType})
+	}
+	if len(hubURL) > 0 {
+		channel.AtomLinks = append(channel.AtomLinks, rssAtomLink{Href: hubURL, Rel: "hub"})
+	}
+	for i, rssItem := range rssFeed.Items {
+		item := items[i]
+
+		withMedia := &rssItemWithMedia{
+			RssItem:    rssItem,
+			Categories: item.categories,
+			Thumbnail:  newMediaThumbnail(item.imageURL),
+		}
+		for _, enclosure := range item.enclosures {
+			withMedia.Enclosures = append(withMedia.Enclosures, &feeds.RssEnclosure{
+				Url:    enclosure.URL,
+				Length: enclosure.Length,
+				Type:   enclosure.Type,
+			})
+		}
+		channel.Items = append(channel.Items, withMedia)
+	}
+
+	doc := &rssWithMedia{
+		Version:          "2.0",
+		ContentNamespace: "http://purl.org/rss/1.0/modules/content/",
+		MediaNamespace:   mediaNamespace,
+		Channel:          channel,
+	}
+	if len(channel.AtomLinks) > 0 {
+		doc.AtomNamespace = atomNamespace
+	}
+	return doc
+}
+
+// atomWithMedia is an Atom 1.0 feed with language, websub links, and media of entries
+type atomWithMedia struct {
+	*feeds.AtomFeed
+	Language       string                `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
+	MediaNamespace string                `xml:"xmlns:media,attr"`
+	Links          []feeds.AtomLink      `xml:"link"` // (replaces the single `AtomFeed.Link`)
+	Entries        []*atomEntryWithMedia `xml:"entry"`
+}
+
+// atomEntryWithMedia is an Atom 1.0 entry with multiple categories and a thumbnail
+type atomEntryWithMedia struct {
+	*feeds.AtomEntry
+	Categories []atomCategory `xml:"category"`
+	Thumbnail  *mediaThumbnail
+}
+
+// atomCategory is a `<category>` element of Atom 1.0
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// return an Atom 1.0 feed of `atomFeed` with `language`, media of `items`, and websub links of `hubURL` and `selfURL` (optional)
+//
+// NOTE: `items` should be in the same order with the entries of `atomFeed`.
+func newAtomWithMedia(atomFeed *feeds.AtomFeed, items []publishedItem, language, hubURL, selfURL string) *atomWithMedia {
+	feed := &atomWithMedia{
+		AtomFeed:       atomFeed,
+		Language:       language,
+		MediaNamespace: mediaNamespace,
+	}
+	if atomFeed.Link != nil {
+		feed.Links = append(feed.Links, *atomFeed.Link)
+	}
+	if len(selfURL) > 0 {
+		feed.Links = append(feed.Links, feeds.AtomLink{Href: selfURL, Rel: "self", Type: PublishContentTypeAtom})
+	}
+	if len(hubURL) > 0 {
+		feed.Links = append(feed.Links, feeds.AtomLink{Href: hubURL, Rel: "hub"})
+	}
+	for i, entry := range atomFeed.Entries {
+		item := items[i]
+
+		withMedia := &atomEntryWithMedia{
+			AtomEntry: entry,
+			Thumbnail: newMediaThumbnail(item.imageURL),
+		}
+		for _, category := range item.categories {
+			withMedia.Categories = append(withMedia.Categories, atomCategory{Term: category})
+		}
+		for _, enclosure := range item.enclosures {
+			entry.Links = append(entry.Links, feeds.AtomLink{
+				Href:   enclosure.URL,
+				Rel:    "enclosure",
+				Type:   enclosure.Type,
+				Length: enclosure.Length,
+			})
+		}
+		feed.Entries = append(feed.Entries, withMedia)
+	}
+	return feed
+}
+
+// add media of `items` to the items of `jsonFeed`
+//
+// NOTE: `items` should be in the same order with the ones of `jsonFeed`.
+func addJSONMedia(jsonFeed *feeds.JSONFeed, items []publishedItem) {
+	for i, jsonItem := range jsonFeed.Items {
+		item := items[i]
+
+		if len(item.imageURL) > 0 {
+			jsonItem.Image = item.imageURL
+		}
+		jsonItem.Tags = item.categories
+		for _, enclosure := range item.enclosures {
+			attachment := feeds.JSONAttachment{
+				Url:      enclosure.URL,
+				MIMEType: enclosure.Type,
+			}
+			// NOTE: sizes which do not fit in the attachment's `int32` are omitted
+			if size, err := strconv.ParseInt(enclosure.Length, 10, 64); err == nil && size <= math.MaxInt32 {
+				attachment.Size = int32(size)
+			}
+			jsonItem.Attachments = append(jsonItem.Attachments, attachment)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package rf
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// test parsing publish formats
+func TestParsePublishFormat(t *testing.T) {
+	for str, expected := range map[string]PublishFormat{
+		"rss":    PublishFormatRSS,
+		" Atom ": PublishFormatAtom,
+		"JSON":   PublishFormatJSON,
+	} {
+		if format, err := ParsePublishFormat(str); err != nil || format != expected {
+			t.Errorf("expected '%s' for '%s', got '%s' (%v)", expected, str, format, err)
+		}
+	}
+	if _, err := ParsePublishFormat("csv"); err == nil {
+		t.Errorf("expected error for unsupported format")
+	}
+}
+
+// test `Publish` in all formats
+func TestPublish(t *testing.T) {
+	client := NewClient([]string{"key"}, nil)
+
+	updated := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	items := []CachedItem{
+		{
+			Title:       "Test Article",
+			Link:        "https://example.com/article",
+			GUID:        "guid-pub-1",
+			Description: "A test article",
+			Summary:     "This is a **summary** of the article.",
+		},
+		{
+			Title:   "No Summary",
+			Link:    "https://example.com/article2",
+			GUID:    "guid-pub-2",
+			Summary: "", // should be omitted
+		},
+	}
+	items[0].CreatedAt = updated.Add(-time.Hour)
+	items[0].UpdatedAt = updated
+
+	for format, expectedContentType := range map[PublishFormat]string{
+		PublishFormatRSS:  "application/rss+xml",
+		PublishFormatAtom: "application/atom+xml",
+		PublishFormatJSON: "application/feed+json",
+	} {
+		bytes, contentType, err := client.Publish(format, "Test Feed", "https://example.com", "Test Description", "Author", "email@example.com", items)
+		if err != nil {
+			t.Fatalf("failed to publish in '%s': %s", format, err)
+		}
+		if contentType != expectedContentType {
+			t.Errorf("expected content type '%s' for '%s', got '%s'", expectedContentType, format, contentType)
+		}
+
+		// should be parsed back with the same items
+		parsed, err := gofeed.NewParser().ParseString(string(bytes))
+		if err != nil {
+			t.Fatalf("failed to parse published '%s' feed: %s", format, err)
+		}
+		if parsed.Title != "Test Feed" || len(parsed.Items) != 1 {
+			t.Fatalf("unexpected '%s' feed: %+v", format, parsed)
+		}
+		item := parsed.Items[0]
+		if item.Title != "Test Article" || item.Link != "https://example.com/article" {
+			t.Errorf("unexpected '%s' item: %+v", format, item)
+		}
+		if !strings.Contains(item.Content, "<strong>summary</strong>") || !strings.Contains(item.Content, "GUID:") {
+			t.Errorf("unexpected content of '%s' item: %s", format, item.Content)
+		}
+		if format != PublishFormatRSS && (item.UpdatedParsed == nil || !item.UpdatedParsed.Equal(updated)) {
+			t.Errorf("expected updated time %s of '%s' item, got %v", updated, format, item.UpdatedParsed)
+		}
+	}
+
+	// html content in atom
+	if bytes, _, _ := client.Publish(PublishFormatAtom, "Feed", "https://example.com", "Desc", "Author", "e@e.com", items); !strings.Contains(string(bytes), `<content type="html">`) {
+		t.Errorf("expected html content in atom feed, got %s", bytes)
+	}
+
+	if _, _, err := client.Publish(PublishFormat("csv"), "Feed", "https://example.com", "Desc", "Author", "e@e.com", items); err == nil {
+		t.Errorf("expected error for unsupported format")
+	}
+}
+
+// test `PublishWithOptions`
+func TestPublishWithOptions(t *testing.T) {
+	client := NewClient([]string{"key"}, nil)
+
+	now := time.Now()
+	items := []CachedItem{
+		{Title: "Old", Link: "https://example.com/old", GUID: "guid-old", Description: "old description", Summary: "old summary"},
+		{Title: "New", Link: "https://example.com/new", GUID: "guid-new", Description: "new description", Summary: "new summary", FeedURL: "https://example.com/feed"},
+		{Title: "Failed", Link: "https://example.com/failed", GUID: "guid-failed", Summary: failedSummary("", fmt.Errorf("test error"))},
+		{Title: "Unsummarized", Link: "https://example.com/unsummarized", GUID: "guid-unsummarized", Description: "not yet"},
+	}
+	items[0].CreatedAt = now.Add(-2 * time.Hour)
+	items[1].CreatedAt = now.Add(-1 * time.Hour)
+	items[2].CreatedAt = now.Add(-3 * time.Hour)
+	items[3].CreatedAt = now
+
+	// sort, limit, and footer template
+	bytes, contentType, err := client.PublishWithOptions(items, PublishOptions{
+		Title:          "Feed",
+		Link:           "https://example.com",
+		Language:       "ko",
+		ImageURL:       "https://example.com/image.png",
+		TTL:            30 * time.Minute,
+		Limit:          1,
+		SortOrder:      PublishSortNewestFirst,
+		FooterTemplate: `Source: {{.FeedURL}}`,
+	})
+	if err != nil {
+		t.Fatalf("failed to publish with options: %s", err)
+	}
+	if contentType != PublishContentType {
+		t.Errorf("expected rss by default, got '%s'", contentType)
+	}
+	xmlStr := string(bytes)
+	for _, expected := range []string{"<title>New</title>", "Source: https://example.com/feed", "<ttl>30</ttl>", "<language>ko</language>", "<url>https://example.com/image.png</url>"} {
+		if !strings.Contains(xmlStr, expected) {
+			t.Errorf("expected '%s' in published feed, got %s", expected, xmlStr)
+		}
+	}
+	for _, unexpected := range []string{"<title>Old</title>", "<title>Failed</title>", "<title>Unsummarized</title>", "new description", "GUID:"} {
+		if strings.Contains(xmlStr, unexpected) {
+			t.Errorf("expected no '%s' in published feed, got %s", unexpected, xmlStr)
+		}
+	}
+
+	// include failed and unsummarized ones, without footers
+	bytes, _, err = client.PublishWithOptions(items, PublishOptions{
+		Format:                 PublishFormatAtom,
+		Title:                  "Feed",
+		Link:                   "https://example.com",
+		Language:               "ko",
+		SortOrder:              PublishSortOldestFirst,
+		IncludeUnsummarized:    true,
+		IncludeFailedSummaries: true,
+		IncludeDescription:     true,
+		OmitFooter:             true,
+	})
+	if err != nil {
+		t.Fatalf("failed to publish with options: %s", err)
+	}
+	parsed, err := gofeed.NewParser().ParseString(string(bytes))
+	if err != nil {
+		t.Fatalf("failed to parse published feed: %s", err)
+	}
+	var titles []string
+	for _, item := range parsed.Items {
+		titles = append(titles, item.Title)
+		if strings.Contains(item.Content, "GUID:") {
+			t.Errorf("expected no footer, got %s", item.Content)
+		}
+	}
+	if strings.Join(titles, ",") != "Failed,Old,New,Unsummarized" {
+		t.Errorf("unexpected items: %v", titles)
+	}
+	if parsed.Language != "ko" {
+		t.Errorf("expected language of atom feed, got '%s'", parsed.Language)
+	}
+	if parsed.Items[1].Description != "old description" {
+		t.Errorf("expected description, got '%s'", parsed.Items[1].Description)
+	}
+
+	// values of footers are escaped in their contexts
+	bytes, _, err = client.PublishWithOptions([]CachedItem{
+		{Title: "Escaped", GUID: "javascript:alert(1)", Summary: "summary"},
+		{Title: "Commented", GUID: "guid-commented", Summary: "summary", Comments: "https://example.com/comments?a=1&b=<2>"},
+		{Title: "Tagged", GUID: "tag:example.com,2026:item-1", Summary: "summary"},
+		{Title: "Linked", GUID: "https://example.com/item-1", Summary: "summary"},
+	}, PublishOptions{Format: PublishFormatAtom})
+	if err != nil {
+		t.Fatalf("failed to publish with options: %s", err)
+	}
+	if parsed, err = gofeed.NewParser().ParseString(string(bytes)); err != nil {
+		t.Fatalf("failed to parse published feed: %s", err)
+	}
+	for _, item := range parsed.Items {
+		switch item.Title {
+		case "Escaped":
+			if strings.Contains(item.Content, `href="javascript:`) {
+				t.Errorf("expected unsafe url to be filtered, got %s", item.Content)
+			}
+		case "Commented":
+			if !strings.Contains(item.Content, `href="https://example.com/comments?a=1&amp;b=%3c2%3e"`) ||
+				!strings.Contains(item.Content, `>https://example.com/comments?a=1&amp;b=&lt;2&gt;</a>`) {
+				t.Errorf("expected escaped comments url, got %s", item.Content)
+			}
+		case "Tagged":
+			if !strings.Contains(item.Content, "GUID: tag:example.com,2026:item-1") || strings.Contains(item.Content, "href=") {
+				t.Errorf("expected non-url guid as a plain text, got %s", item.Content)
+			}
+		case "Linked":
+			if !strings.Contains(item.Content, `GUID: <a href="https://example.com/item-1">https://example.com/item-1</a>`) {
+				t.Errorf("expected url guid as a link, got %s", item.Content)
+			}
+		}
+	}
+
+	// invalid footer template
+	if _, _, err := client.PublishWithOptions(items, PublishOptions{FooterTemplate: "{{.Unknown"}); err == nil {
+		t.Errorf("expected error for invalid footer template")
+	}
+}
+
+// test publishing media of items in all formats
+func TestPublishMedia(t *testing.T) {
+	ctx := context.Background()
+
+	// (stored in and fetched from db)
+	dbc, err := newDBCache(filepath.Join(t.TempDir(), "media.db"))
+	if err != nil {
+		t.Fatalf("failed to create dbCache: %s", err)
+	}
+	published := time.Now().Add(-time.Hour)
+	if err := dbc.Save(ctx, ItemToCache{
+		Item: gofeed.Item{
+			GUID:            "guid-podcast",
+			Title:           "Episode 1",
+			Links:           []string{"https://example.com/episode1"},
+			PublishedParsed: &published,
+			Image:           &gofeed.Image{URL: "https://example.com/thumbnail.jpg"},
+			Categories:      []string{"podcast", "tech"},
+			Enclosures: []*gofeed.Enclosure{
+				{URL: "https://example.com/episode1.mp3", Length: "12345", Type: "audio/mpeg"},
+			},
+		},
+		Title:   "Episode 1",
+		Summary: "Summary of episode 1",
+	}); err != nil {
+		t.Fatalf("failed to save item: %s", err)
+	}
+	item, err := dbc.Fetch(ctx, "guid-podcast")
+	if err != nil {
+		t.Fatalf("failed to fetch item: %s", err)
+	}
+	if len(item.Categories) != 2 || len(item.Enclosures) != 1 {
+		t.Fatalf("expected media to be stored, got %+v", item)
+	}
+
+	client := NewClient([]string{"key"}, nil)
+	for _, format := range []PublishFormat{PublishFormatRSS, PublishFormatAtom, PublishFormatJSON} {
+		bytes, _, err := client.PublishWithOptions([]CachedItem{*item}, PublishOptions{Format: format, Title: "Podcast", Link: "https://example.com"})
+		if err != nil {
+			t.Fatalf("failed to publish in '%s': %s", format, err)
+		}
+
+		parsed, err := gofeed.NewParser().ParseString(string(bytes))
+		if err != nil || len(parsed.Items) != 1 {
+			t.Fatalf("failed to parse published '%s' feed: %v", format, err)
+		}
+		parsedItem := parsed.Items[0]
+
+		if strings.Join(parsedItem.Categories, ",") != "podcast,tech" {
+			t.Errorf("expected categories in '%s' feed, got %v", format, parsedItem.Categories)
+		}
+		if len(parsedItem.Enclosures) != 1 || parsedItem.Enclosures[0].URL != "https://example.com/episode1.mp3" || parsedItem.Enclosures[0].Type != "audio/mpeg" {
+			t.Errorf("expected an enclosure in '%s' feed, got %+v", format, parsedItem.Enclosures)
+		}
+		if format == PublishFormatJSON {
+			if parsedItem.Image == nil || parsedItem.Image.URL != "https://example.com/thumbnail.jpg" {
+				t.Errorf("expected image in '%s' feed, got %+v", format, parsedItem.Image)
+			}
+		} else if !strings.Contains(string(bytes), `<media:thumbnail url="https://example.com/thumbnail.jpg">`) {
+			t.Errorf("expected thumbnail in '%s' feed, got %s", format, bytes)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package rf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+////////////////
+//
+// (protection against SSRF)
+//
+
+// SafeDialPolicy is a struct for the policy of outbound requests in safe-dial mode
+//
+// In safe-dial mode, requests to private, loopback, link-local (eg. `169.254.169.254`),
+// and other non-public addresses are blocked after DNS resolution.
+type SafeDialPolicy struct {
+	AllowedSchemes []string // schemes which are allowed (default: "http" and "https")
+	DeniedSchemes  []string // schemes which are always blocked
+
+	// hosts which are allowed even if they resolve to non-public addresses (eg. an internal feed or proxy)
+	//
+	// (a host with a leading dot, eg. ".example.com", matches its subdomains)
+	AllowedHosts []string
+
+	// hosts which are always blocked (matched like `AllowedHosts`)
+	DeniedHosts []string
+}
+
+// BlockedRequestError is returned when an outbound request is blocked in safe-dial mode.
+type BlockedRequestError struct {
+	Host   string
+	Addr   netip.Addr // resolved address (invalid if not resolved yet)
+	Reason string
+}
+
+// Error returns the error message.
+func (e *BlockedRequestError) Error() string {
+	if e.Addr.IsValid() {
+		return fmt.Sprintf("blocked request to '%s' (%s): %s", e.Host, e.Addr, e.Reason)
+	}
+	return fmt.Sprintf("blocked request to '%s': %s", e.Host, e.Reason)
+}
+
+// check if given error is (or wraps) a `BlockedRequestError`
+func isBlockedRequest(err error) bool {
+	var blocked *BlockedRequestError
+	return errors.As(err, &blocked)
+}
+
+// address ranges which are not in `netip.Addr`'s helper functions
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // "this" network
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"), // benchmarking
+}
+
+// check if given address is a public one
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	if addr.IsPrivate() ||
+		addr.IsLoopback() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() ||
+		addr.IsUnspecified() {
+		return false
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// check if `host` matches one of `patterns`
+func matchesHost(host string, patterns []string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if host == pattern || (strings.HasPrefix(pattern, ".") && strings.HasSuffix(host, pattern)) {
+			return true
+		}
+	}
+	return false
+}
+
+// check given url's scheme and host
+func (p *SafeDialPolicy) checkURL(u *url.URL) error {
+	scheme := strings.ToLower(u.Scheme)
+	allowedSchemes := p.AllowedSchemes
+	if len(allowedSchemes) <= 0 {
+		allowedSchemes = []string{"http", "https"}
+	}
+	if !slices.Contains(allowedSchemes, scheme) || slices.Contains(p.DeniedSchemes, scheme) {
+		return &BlockedRequestError{Host: u.Host, Reason: fmt.Sprintf("scheme '%s' is not allowed", u.Scheme)}
+	}
+
+	return p.checkHost(u.Hostname())
+}
+
+// check given host (before resolution)
+func (p *SafeDialPolicy) checkHost(host string) error {
+	if matchesHost(host, p.DeniedHosts) {
+		return &BlockedRequestError{Host: host, Reason: "host is denied"}
+	}
+	return nil
+}
+
+// check given resolved address of `host`
+func (p *SafeDialPolicy) checkAddr(host string, addr netip.Addr) error {
+	if !isPublicAddr(addr) && !matchesHost(host, p.AllowedHosts) {
+		return &BlockedRequestError{Host: host, Addr: addr, Reason: "address is not public"}
+	}
+	return nil
+}
+
+// resolve and check the addresses of `host`
+func (p *SafeDialPolicy) resolve(ctx context.Context, host string) ([]netip.Addr, error) {
+	if err := p.checkHost(host); err != nil {
+		return nil, err
+	}
+
+	var addrs []netip.Addr
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = []netip.Addr{addr}
+	} else if addrs, err = net.DefaultResolver.LookupNetIP(ctx, "ip", host); err != nil {
+		return nil, err
+	}
+
+	for _, addr := range addrs {
+		if err := p.checkAddr(host, addr); err != nil {
+			return nil, err
+		}
+	}
+	return addrs, nil
+}
+
+// dial function which connects only to checked addresses
+func (p *SafeDialPolicy) dialContext(dialer *net.Dialer) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+
+		addrs, err := p.resolve(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		// NOTE: dial the resolved addresses directly, (not to be resolved again, eg. DNS rebinding)
+		var errs []error
+		for _, addr := range addrs {
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(addr.Unmap().String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			errs = append(errs, err)
+		}
+		return nil, errors.Join(errs...)
+	}
+}
+
+// safeTransport is a round tripper which checks requests (including redirects) with a policy
+type safeTransport struct {
+	policy *SafeDialPolicy
+	next   http.RoundTripper
+
+	resolve bool                                  // if true, resolve and check addresses here (when `next` does not dial with the policy)
+	proxy   func(*http.Request) (*url.URL, error) // proxy of `next`, (addresses are resolved and checked here only when it applies)
+}
+
+// RoundTrip checks given request and passes it to the next round tripper.
+func (t *safeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.policy.checkURL(req.URL); err != nil {
+		return nil, err
+	}
+
+	resolve := t.resolve
+	if !resolve && t.proxy != nil {
+		proxyURL, err := t.proxy(req)
+		if err != nil {
+			return nil, err
+		}
+		resolve = proxyURL != nil
+	}
+	if resolve {
+		if _, err := t.policy.resolve(req.Context(), req.URL.Hostname()); err != nil {
+			return nil, err
+		}
+	}
+	return t.next.RoundTrip(req)
+}
+
+// wrap given http client for requesting with the policy
+//
+// NOTE: if the client's transport is not an `*http.Transport`, or its proxy applies to the request
+// (where the dialer only sees the proxy's address), target addresses are checked before the request.
+func (p *SafeDialPolicy) wrap(base *http.Client) *http.Client {
+	client := *base
+
+	next := base.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	if transport, ok := next.(*http.Transport); ok {
+		transport = transport.Clone()
+		transport.DialContext = p.dialContext(&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		})
+		client.Transport = &safeTransport{policy: p, next: transport, proxy: transport.Proxy}
+	} else {
+		client.Transport = &safeTransport{policy: p, next: next, resolve: true}
+	}
+
+	return &client
+}
+
+// SetSafeDial enables safe-dial mode with given policy for all outbound requests, or disables it with nil.
+//
+// NOTE: urls given to url scrappers are checked before crawling, as they are not requested with the http client.
+func (c *Client) SetSafeDial(policy *SafeDialPolicy) {
+	if policy != nil {
+		policy = policy.normalized()
+	}
+	c.safeDial = policy
+	c.updateSafeHTTPClient()
+}
+
+// return a copy of the policy with lowercased schemes, (schemes of urls are compared in lowercase)
+func (p *SafeDialPolicy) normalized() *SafeDialPolicy {
+	lowercased := func(strs []string) (lowered []string) {
+		for _, str := range strs {
+			lowered = append(lowered, strings.ToLower(str))
+		}
+		return lowered
+	}
+
+	normalized := *p
+	normalized.AllowedSchemes = lowercased(p.AllowedSchemes)
+	normalized.DeniedSchemes = lowercased(p.DeniedSchemes)
+	return &normalized
+}
+
+// check given url with the policy of safe-dial mode (if enabled),
+// before requesting it without the http client (eg. with url scrappers)
+func (c *Client) checkSafeDial(ctx context.Context, rawURL string) error {
+	if c.safeDial == nil {
+		return nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse url '%s': %w", rawURL, err)
+	}
+	if err := c.safeDial.checkURL(u); err != nil {
+		return err
+	}
+	_, err = c.safeDial.resolve(ctx, u.Hostname())
+	return err
+}
+
+// rebuild the http client of safe-dial mode
+func (c *Client) updateSafeHTTPClient() {
+	if c.safeDial == nil {
+		c.safeHTTPClient = nil
+		return
+	}
+	c.safeHTTPClient = c.safeDial.wrap(c.baseHTTPClient())
+}
@@ -0,0 +1,175 @@
+package rf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"testing"
+)
+
+// test checking public addresses
+func TestIsPublicAddr(t *testing.T) {
+	for addr, expected := range map[string]bool{
+		"8.8.8.8":         true,
+		"2606:4700::1111": true,
+		"127.0.0.1":       false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.0.1":     false,
+		"169.254.169.254": false,
+		"100.64.0.1":      false,
+		"0.0.0.0":         false,
+		"::1":             false,
+		"fe80::1":         false,
+		"fd00::1":         false,
+		"::ffff:10.0.0.1": false,
+	} {
+		if isPublicAddr(netip.MustParseAddr(addr)) != expected {
+			t.Errorf("expected isPublicAddr(%s) to be %v", addr, expected)
+		}
+	}
+}
+
+// test checking urls with a policy
+func TestSafeDialPolicyCheckURL(t *testing.T) {
+	policy := &SafeDialPolicy{
+		DeniedHosts: []string{".internal.example.com"},
+	}
+
+	for rawURL, blocked := range map[string]bool{
+		"https://example.com/feed":         false,
+		"ftp://example.com/feed":           true,
+		"file:///etc/passwd":               true,
+		"https://api.internal.example.com": true,
+	} {
+		u, _ := url.Parse(rawURL)
+		if err := policy.checkURL(u); isBlockedRequest(err) != blocked {
+			t.Errorf("expected '%s' to be blocked: %v, got %v", rawURL, blocked, err)
+		}
+	}
+}
+
+// test checking urls with a policy of schemes in upper cases
+func TestSafeDialPolicySchemesCase(t *testing.T) {
+	client := NewClient([]string{"key"}, nil)
+	client.SetSafeDial(&SafeDialPolicy{
+		AllowedSchemes: []string{"HTTPS", "FTP"},
+		DeniedSchemes:  []string{"FTP"},
+	})
+
+	for rawURL, blocked := range map[string]bool{
+		"https://example.com/feed": false,
+		"HTTPS://example.com/feed": false,
+		"ftp://example.com/feed":   true,
+		"http://example.com/feed":  true,
+	} {
+		u, _ := url.Parse(rawURL)
+		if err := client.safeDial.checkURL(u); isBlockedRequest(err) != blocked {
+			t.Errorf("expected '%s' to be blocked: %v, got %v", rawURL, blocked, err)
+		}
+	}
+}
+
+// test blocking requests to non-public addresses
+func TestSafeDial(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, "internal secret")
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := NewClient([]string{"key"}, []string{server.URL})
+	client.SetSafeDial(&SafeDialPolicy{})
+
+	_, err := client.FetchFeeds(ctx, false, 7)
+	var blocked *BlockedRequestError
+	if !errors.As(err, &blocked) {
+		t.Fatalf("expected BlockedRequestError, got %v", err)
+	}
+	if blocked.Addr.String() != "127.0.0.1" {
+		t.Errorf("expected blocked address 127.0.0.1, got %s", blocked.Addr)
+	}
+
+	// not summarized via URL context either
+	summarized, err := client.summarize(ctx, "title", server.URL)
+	if !isBlockedRequest(err) || summarized.usedModel != "" {
+		t.Errorf("expected blocked summary without a model, got %v (%s)", err, summarized.usedModel)
+	}
+
+	// allowed hosts
+	client.SetSafeDial(&SafeDialPolicy{AllowedHosts: []string{"127.0.0.1"}})
+	if content, _, err := client.fetch(ctx, 0, server.URL); err != nil || len(content) <= 0 {
+		t.Errorf("expected allowed host to be fetched, got %v", err)
+	}
+
+	// disabled
+	client.SetSafeDial(nil)
+	if _, _, err := client.fetch(ctx, 0, server.URL); err != nil {
+		t.Errorf("expected no errors without safe-dial mode, got %v", err)
+	}
+}
+
+// test safe-dial mode with a proxy, where the dialer only sees the proxy's address
+func TestSafeDialWithProxy(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, "proxied internal secret")
+	}))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+
+	ctx := context.Background()
+	client := NewClient([]string{"key"}, nil)
+	client.SetHTTPTransport(&http.Transport{Proxy: http.ProxyURL(proxyURL)})
+	client.SetSafeDial(&SafeDialPolicy{AllowedHosts: []string{"127.0.0.1"}}) // (proxy itself is allowed)
+
+	if _, _, err := client.fetch(ctx, 0, "http://169.254.169.254/latest/meta-data/"); !isBlockedRequest(err) {
+		t.Errorf("expected internal target behind proxy to be blocked, got %v", err)
+	}
+
+	// urls for url scrappers are also checked
+	if err := client.checkSafeDial(ctx, "http://10.0.0.1/"); !isBlockedRequest(err) {
+		t.Errorf("expected internal url to be blocked before crawling, got %v", err)
+	}
+	if err := client.checkSafeDial(ctx, "http://127.0.0.1/"); err != nil {
+		t.Errorf("expected allowed host not to be blocked, got %v", err)
+	}
+}
+
+// round tripper which responds without requesting
+type okTransport struct{}
+
+func (okTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+}
+
+// test checking target addresses before requests only when proxies apply to them
+func TestSafeTransportProxy(t *testing.T) {
+	proxyURL, _ := url.Parse("http://proxy.example.com:8080")
+	transport := &safeTransport{
+		policy: &SafeDialPolicy{},
+		next:   okTransport{},
+		proxy: func(req *http.Request) (*url.URL, error) {
+			if req.URL.Path == "/proxied" {
+				return proxyURL, nil
+			}
+			return nil, nil
+		},
+	}
+
+	// (left to the dialer)
+	req, _ := http.NewRequest(http.MethodGet, "http://10.0.0.1/direct", nil)
+	if _, err := transport.RoundTrip(req); err != nil {
+		t.Errorf("expected request without proxy not to be checked before the request, got %v", err)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "http://10.0.0.1/proxied", nil)
+	if _, err := transport.RoundTrip(req); !isBlockedRequest(err) {
+		t.Errorf("expected internal target behind proxy to be blocked, got %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -50,19 +51,66 @@ func main() {
 				return
 			}
 
+			// format of feeds (eg. `?format=atom`, default: rss)
+			format := rf.PublishFormatRSS
+			if param := r.URL.Query().Get("format"); param != "" {
+				var err error
+				if format, err = rf.ParsePublishFormat(param); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+			}
+
 			// fetch cached items,
 			items := client.ListCachedItems(true)
 
-			w.Header().Set("Content-Type", rf.PublishContentType)
-			w.Header().Set("Cache-Control", "max-age=60")
+			// generate feeds and serve them
+			if bytes, contentType, err := client.Publish(format, rssTitle, rssLink, rssDescription, rssAuthor, rssEmail, items); err == nil {
+				w.Header().Set("Content-Type", contentType)
+				w.Header().Set("Cache-Control", "max-age=60")
 
-			// generate xml and serve it
-			if bytes, err := client.PublishXML(rssTitle, rssLink, rssDescription, rssAuthor, rssEmail, items); err == nil {
 				if _, err := io.Writer.Write(w, bytes); err != nil {
 					log.Printf("# failed to write data: %s", err)
 				}
 			} else {
-				log.Printf("# failed to serve RSS feeds: %s", err)
+				log.Printf("# failed to serve feeds: %s", err)
+			}
+		})
+
+		// index of separately published feeds (per source feed and category)
+		http.HandleFunc("/feeds/index.opml", func(w http.ResponseWriter, r *http.Request) {
+			baseURL := fmt.Sprintf("http://%s/feeds/", r.Host)
+			if bytes, err := client.PublishIndex(r.Context(), rssTitle, baseURL); err == nil {
+				w.Header().Set("Content-Type", rf.PublishContentTypeOPML)
+				if _, err := io.Writer.Write(w, bytes); err != nil {
+					log.Printf("# failed to write data: %s", err)
+				}
+			} else {
+				log.Printf("# failed to serve index: %s", err)
+			}
+		})
+
+		// separately published feeds (eg. `/feeds/category-tech`)
+		http.HandleFunc("/feeds/{name}", func(w http.ResponseWriter, r *http.Request) {
+			bytes, contentType, err := client.PublishFeed(r.Context(), r.PathValue("name"), rf.PublishOptions{
+				Link:      rssLink,
+				Author:    rssAuthor,
+				Email:     rssEmail,
+				SortOrder: rf.PublishSortNewestFirst,
+			})
+			if err != nil {
+				if errors.Is(err, rf.ErrNotFound) {
+					http.NotFound(w, r)
+				} else {
+					log.Printf("# failed to serve feeds: %s", err)
+				}
+				return
+			}
+
+			w.Header().Set("Content-Type", contentType)
+			w.Header().Set("Cache-Control", "max-age=60")
+			if _, err := io.Writer.Write(w, bytes); err != nil {
+				log.Printf("# failed to write data: %s", err)
 			}
 		})
 
@@ -0,0 +1,275 @@
+package rf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+)
+
+////////////////
+//
+// (delivery of summarized items to sinks)
+//
+
+const (
+	sinkBatchSize           = 20 // maximum number of items delivered to a sink at once
+	maxSinkDeliveryAttempts = 5  // items failed this many times are not retried anymore
+)
+
+// Sink is an interface of destinations which summarized items are delivered to
+type Sink interface {
+	// Name returns the unique name of the sink, used for tracking its deliveries.
+	Name() string
+
+	// Deliver delivers given items, and returns the result of each item it attempted.
+	//
+	// Failed items are retried later, and items without results (eg. not attempted after a rate limit)
+	// are retried without counting their attempts.
+	Deliver(ctx context.Context, items []CachedItem) BatchResults
+}
+
+// PartialSink is an interface of sinks which deliver each item in several parts (eg. chat messages),
+// so that failed items are retried from their first part which was not delivered
+type PartialSink interface {
+	Sink
+
+	// DeliverParts delivers given items like `Deliver`, skipping the first `deliveredParts[guid]` parts of each item.
+	//
+	// Items which failed after some of their parts were delivered have `PartialDeliveryError`s in their results.
+	DeliverParts(ctx context.Context, items []CachedItem, deliveredParts map[string]int) BatchResults
+}
+
+// PartialDeliveryError is an error of an item which failed after some of its parts were delivered
+type PartialDeliveryError struct {
+	DeliveredParts int // number of parts delivered so far (including the skipped ones)
+	Err            error
+}
+
+// Error returns the error message.
+func (e *PartialDeliveryError) Error() string {
+	return fmt.Sprintf("%s (after %d part(s) delivered)", e.Err, e.DeliveredParts)
+}
+
+// Unwrap returns the wrapped error.
+func (e *PartialDeliveryError) Unwrap() error {
+	return e.Err
+}
+
+// return `err` of an item which failed after `deliveredParts` parts, wrapped in a `PartialDeliveryError` if any part was delivered
+func partialDeliveryError(err error, deliveredParts int) error {
+	if err == nil || deliveredParts <= 0 {
+		return err
+	}
+	return &PartialDeliveryError{DeliveredParts: deliveredParts, Err: err}
+}
+
+// sinkHTTPClientKey is the context key of the http client given to sinks while delivering
+type sinkHTTPClientKey struct{}
+
+// return `client` if it is set, or the http client of `Client` which is delivering items with `ctx`
+// (so that requests of sinks go through its proxy and safe-dial mode too)
+func sinkHTTPClient(ctx context.Context, client *http.Client) *http.Client {
+	if client != nil {
+		return client
+	}
+	if client, ok := ctx.Value(sinkHTTPClientKey{}).(*http.Client); ok && client != nil {
+		return client
+	}
+	return defaultHTTPClient
+}
+
+// results of given items which were delivered (or failed with `err`) all together
+func batchResults(items []CachedItem, err error) (results BatchResults) {
+	for _, item := range items {
+		results = append(results, BatchResult{GUID: item.GUID, Err: err})
+	}
+	return results
+}
+
+// SinkItem is a struct for a cached item serialized for sinks
+type SinkItem struct {
+	GUID          string   `json:"guid"`
+	Title         string   `json:"title"`
+	OriginalTitle string   `json:"original_title,omitempty"`
+	Link          string   `json:"link,omitempty"`
+	Comments      string   `json:"comments,omitempty"`
+	Author        string   `json:"author,omitempty"`
+	PublishDate   string   `json:"publish_date,omitempty"`
+	FeedURL       string   `json:"feed_url,omitempty"`
+	ImageURL      string   `json:"image_url,omitempty"`
+	Categories    []string `json:"categories,omitempty"`
+
+	Summary     string `json:"summary"`      // in markdown
+	SummaryHTML string `json:"summary_html"` // decorated as HTML
+
+	CachedAt time.Time `json:"cached_at"`
+}
+
+// NewSinkItem converts given cached item to a `SinkItem`.
+func NewSinkItem(item CachedItem) SinkItem {
+	return SinkItem{
+		GUID:          item.GUID,
+		Title:         item.Title,
+		OriginalTitle: item.OriginalTitle,
+		Link:          item.Link,
+		Comments:      item.Comments,
+		Author:        item.Author,
+		PublishDate:   item.PublishDate,
+		FeedURL:       item.FeedURL,
+		ImageURL:      item.ImageURL,
+		Categories:    item.Categories,
+		Summary:       item.Summary,
+		SummaryHTML:   decorateHTML(item.Summary),
+		CachedAt:      item.CreatedAt,
+	}
+}
+
+// SetSinks sets the sinks which newly summarized items are delivered to.
+//
+// Items are delivered after each `SummarizeAndCacheFeeds`, or with `DeliverToSinks`.
+func (c *Client) SetSinks(sinks []Sink) error {
+	names := map[string]bool{}
+	for i, sink := range sinks {
+		if sink == nil || len(sink.Name()) <= 0 {
+			return fmt.Errorf("sink at %d is nil or has an empty name", i)
+		}
+		if names[sink.Name()] {
+			return fmt.Errorf("duplicated name of sinks: '%s'", sink.Name())
+		}
+		names[sink.Name()] = true
+	}
+
+	c.sinks = slices.Clone(sinks)
+
+	return nil
+}
+
+// DeliverToSinks delivers the successfully summarized items which are not delivered yet to each sink,
+// oldest first and in batches.
+//
+// Deliveries are tracked per item and sink, so items are not delivered twice to the same sink,
+// and failed ones are retried on the next call (up to `maxSinkDeliveryAttempts` times).
+//
+// Sinks without their own http clients send requests with the client's one (see `SetHTTPClient`),
+// which is not restricted by safe-dial policies, as urls of sinks are set by the operator.
+//
+// NOTE: all cached items (including the ones cached before the sink was set) are delivered to a new sink.
+// Concurrent calls are serialized, so items are not delivered twice.
+func (c *Client) DeliverToSinks(ctx context.Context) error {
+	if len(c.sinks) <= 0 {
+		return nil
+	}
+
+	c.deliverMu.Lock()
+	defer c.deliverMu.Unlock()
+
+	ctx = context.WithValue(ctx, sinkHTTPClientKey{}, c.baseHTTPClient())
+
+	// (iterate all cached items, as listing them is capped)
+	var items []CachedItem
+	if err := c.cache.ForEach(ctx, func(item CachedItem) error {
+		if len(item.Summary) > 0 && !isError(item.Summary) {
+			items = append(items, item)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	items = redactItems(items, c.googleAIAPIKeys)
+
+	var errs []error
+	for _, sink := range c.sinks {
+		if err := c.deliverToSink(ctx, sink, items); err != nil {
+			errs = append(errs, fmt.Errorf("failed to deliver to sink '%s': %w", sink.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deliver given items which are not delivered yet to `sink`
+func (c *Client) deliverToSink(ctx context.Context, sink Sink, items []CachedItem) error {
+	deliveries, err := c.cache.ListDeliveries(ctx, sink.Name())
+	if err != nil {
+		return err
+	}
+	tracked := map[string]Delivery{}
+	for _, delivery := range deliveries {
+		tracked[delivery.GUID] = delivery
+	}
+
+	var pending []CachedItem
+	for _, item := range items {
+		if delivery, exists := tracked[item.GUID]; exists && (delivery.Delivered() || delivery.Attempts >= maxSinkDeliveryAttempts) {
+			continue
+		}
+		pending = append(pending, item)
+	}
+
+	var errs []error
+	for batch := range slices.Chunk(pending, sinkBatchSize) {
+		v(c.verbose, "delivering %d items to sink '%s'", len(batch), sink.Name())
+
+		var results BatchResults
+		if partial, ok := sink.(PartialSink); ok {
+			deliveredParts := map[string]int{}
+			for _, item := range batch {
+				if parts := tracked[item.GUID].DeliveredParts; parts > 0 {
+					deliveredParts[item.GUID] = parts
+				}
+			}
+			results = partial.DeliverParts(ctx, batch, deliveredParts)
+		} else {
+			results = sink.Deliver(ctx, batch)
+		}
+		if err := results.Err(); err != nil {
+			errs = append(errs, err)
+		}
+
+		inBatch := map[string]bool{}
+		for _, item := range batch {
+			inBatch[item.GUID] = true
+		}
+
+		now := time.Now()
+		attempted := map[string]bool{}
+		for _, result := range results {
+			if !inBatch[result.GUID] || attempted[result.GUID] {
+				continue
+			}
+			attempted[result.GUID] = true
+
+			delivery := tracked[result.GUID]
+			delivery.Sink, delivery.GUID = sink.Name(), result.GUID
+			delivery.Attempts++
+			if result.Err != nil {
+				delivery.LastError = redactText(result.Err.Error(), c.googleAIAPIKeys)
+
+				var partial *PartialDeliveryError
+				if errors.As(result.Err, &partial) {
+					delivery.DeliveredParts = partial.DeliveredParts
+				}
+			} else {
+				delivery.DeliveredAt = &now
+				delivery.LastError = ""
+			}
+
+			if err := c.cache.SaveDelivery(ctx, delivery); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		// stop here if the sink did not attempt all items (eg. rate limited)
+		if len(attempted) < len(batch) {
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ListDeliveries lists the deliveries of cached items to the sink with given name.
+func (c *Client) ListDeliveries(ctx context.Context, sink string) ([]Delivery, error) {
+	return c.cache.ListDeliveries(ctx, sink)
+}
@@ -0,0 +1,383 @@
+package rf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+////////////////
+//
+// (common things of chat sinks, eg. telegram, slack, and discord)
+//
+
+const (
+	maxChatRetries    = 3                // maximum number of retries on rate limits
+	minChatRetryAfter = time.Second      // rate limits without (or with shorter) delays are waited for this long
+	maxChatRetryAfter = 60 * time.Second // rate limits longer than this are not waited for (but retried on the next delivery)
+
+	maxChatErrorBodySize = 1024 // response bodies of failed chat requests are truncated to this size (in bytes) in errors
+)
+
+// chatMarkup is a set of functions for converting HTML to the markup of a chat platform
+type chatMarkup struct {
+	escape func(text string) string
+
+	bold   func(inner string) string
+	italic func(inner string) string
+	strike func(inner string) string
+	code   func(text string) string // (escaped)
+	pre    func(text string) string // (escaped)
+	link   func(href, inner string) string
+	quote  func(inner string) string
+}
+
+// errChatRateLimited is returned when a chat request is rate limited for too long (or too many times)
+var errChatRateLimited = errors.New("rate limited")
+
+var _consecutiveNewlines = regexp.MustCompile(`\n{3,}`)
+
+// elements which are converted to blocks
+var _chatBlocks = map[string]bool{
+	"p": true, "div": true, "table": true, "pre": true, "blockquote": true, "ul": true, "ol": true, "hr": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+// elements which contain blocks only
+var _chatBlockContainers = map[string]bool{
+	"body": true, "ul": true, "ol": true, "blockquote": true, "table": true, "thead": true, "tbody": true, "tr": true,
+}
+
+// convert markdown `body` to the markup of a chat platform
+func markdownToChat(body string, markup chatMarkup) string {
+	node, err := chatBody(body)
+	if err != nil {
+		return markup.escape(body)
+	}
+	return strings.TrimSpace(_consecutiveNewlines.ReplaceAllString(chatInner(node, markup), "\n\n"))
+}
+
+// convert markdown `body` to blocks (eg. paragraphs) in the markup of a chat platform,
+// each of which has at most `limit` characters
+//
+// NOTE: blocks longer than `limit` are split as plain text, so that no markup (eg. links or tags) is cut in the middle.
+func markdownToChatBlocks(body string, markup chatMarkup, limit int) (blocks []string) {
+	node, err := chatBody(body)
+	if err != nil {
+		return splitChatText(body, limit, markup.escape)
+	}
+
+	// (consecutive inline nodes are converted to a block)
+	var inline, inlineText strings.Builder
+	flush := func() {
+		blocks = append(blocks, chatBlock(inline.String(), inlineText.String(), markup.escape, limit)...)
+		inline.Reset()
+		inlineText.Reset()
+	}
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode || !_chatBlocks[child.Data] {
+			inline.WriteString(chatNode(child, markup))
+			inlineText.WriteString(chatText(child))
+			continue
+		}
+		flush()
+
+		wrap := markup.escape
+		if child.Data == "pre" {
+			wrap = func(text string) string { return markup.pre(markup.escape(text)) }
+		}
+		blocks = append(blocks, chatBlock(chatNode(child, markup), chatText(child), wrap, limit)...)
+	}
+	flush()
+
+	return blocks
+}
+
+// a block of `converted` markup, or chunks of its plain `text` converted with `wrap` if it is longer than `limit`
+func chatBlock(converted, text string, wrap func(text string) string, limit int) []string {
+	converted = strings.TrimSpace(_consecutiveNewlines.ReplaceAllString(converted, "\n\n"))
+	if len(converted) <= 0 {
+		return nil
+	}
+	if utf8.RuneCountInString(converted) <= limit {
+		return []string{converted}
+	}
+	return splitChatText(strings.TrimSpace(text), limit, wrap)
+}
+
+// parse markdown `body` into the body node of HTML
+func chatBody(body string) (*html.Node, error) {
+	doc, err := html.Parse(strings.NewReader(decorateHTML(body)))
+	if err != nil {
+		return nil, err
+	}
+	for node := range doc.Descendants() {
+		if node.Type == html.ElementNode && node.Data == "body" {
+			return node, nil
+		}
+	}
+	return nil, fmt.Errorf("no body in converted html")
+}
+
+// convert the children of given node
+func chatInner(node *html.Node, markup chatMarkup) string {
+	var sb strings.Builder
+	index := 0
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		// (skip whitespaces between blocks)
+		if child.Type == html.TextNode && _chatBlockContainers[node.Data] && len(strings.TrimSpace(child.Data)) <= 0 {
+			continue
+		}
+
+		if child.Type == html.ElementNode && child.Data == "li" {
+			index++
+			if node.Data == "ol" {
+				sb.WriteString(strconv.Itoa(index) + ". ")
+			} else {
+				sb.WriteString("• ")
+			}
+		}
+		sb.WriteString(chatNode(child, markup))
+	}
+	return sb.String()
+}
+
+// convert given node
+func chatNode(node *html.Node, markup chatMarkup) string {
+	switch node.Type {
+	case html.TextNode:
+		return markup.escape(node.Data)
+	case html.ElementNode:
+		// (handled below)
+	default:
+		return ""
+	}
+
+	switch node.Data {
+	case "strong", "b":
+		return markup.bold(chatInner(node, markup))
+	case "em", "i":
+		return markup.italic(chatInner(node, markup))
+	case "del", "s", "strike":
+		return markup.strike(chatInner(node, markup))
+	case "code":
+		return markup.code(markup.escape(chatText(node)))
+	case "pre":
+		return markup.pre(markup.escape(strings.TrimSuffix(chatText(node), "\n"))) + "\n\n"
+	case "a":
+		href := ""
+		for _, attr := range node.Attr {
+			if attr.Key == "href" {
+				href = attr.Val
+			}
+		}
+		if len(href) <= 0 {
+			return chatInner(node, markup)
+		}
+		return markup.link(href, chatInner(node, markup))
+	case "img":
+		for _, attr := range node.Attr {
+			if attr.Key == "alt" {
+				return markup.escape(attr.Val)
+			}
+		}
+		return ""
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		return markup.bold(strings.TrimSpace(chatInner(node, markup))) + "\n\n"
+	case "p", "div", "table":
+		return strings.TrimSpace(chatInner(node, markup)) + "\n\n"
+	case "tr", "li":
+		return strings.TrimSpace(chatInner(node, markup)) + "\n"
+	case "ul", "ol":
+		return chatInner(node, markup) + "\n"
+	case "blockquote":
+		return markup.quote(strings.TrimSpace(chatInner(node, markup))) + "\n\n"
+	case "br", "hr":
+		return "\n"
+	case "td", "th":
+		return strings.TrimSpace(chatInner(node, markup)) + " "
+	case "script", "style":
+		return ""
+	}
+	return chatInner(node, markup)
+}
+
+// text content of given node
+func chatText(node *html.Node) string {
+	if node.Type == html.TextNode {
+		return node.Data
+	}
+
+	var sb strings.Builder
+	for n := range node.Descendants() {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+	}
+	return sb.String()
+}
+
+// split `text` into chunks of at most `limit` characters,
+// preferably at paragraphs, then lines, then spaces
+func splitChatMessage(text string, limit int) (chunks []string) {
+	for utf8.RuneCountInString(text) > limit {
+		// the longest prefix in the limit
+		cut := len(text)
+		if runes := []rune(text); len(runes) > limit {
+			cut = len(string(runes[:limit]))
+		}
+
+		at := -1
+		for _, sep := range []string{"\n\n", "\n", " "} {
+			if idx := strings.LastIndex(text[:cut], sep); idx > 0 {
+				at = idx
+				break
+			}
+		}
+		if at < 0 {
+			at = cut
+		}
+
+		if chunk := strings.TrimSpace(text[:at]); len(chunk) > 0 {
+			chunks = append(chunks, chunk)
+		}
+		text = strings.TrimSpace(text[at:])
+	}
+	if len(text) > 0 {
+		chunks = append(chunks, text)
+	}
+	return chunks
+}
+
+// split plain `text` into chunks which have at most `limit` characters after converted with `wrap` (eg. escaped)
+func splitChatText(text string, limit int, wrap func(text string) string) (chunks []string) {
+	for _, piece := range splitChatMessage(text, limit) {
+		chunk := wrap(piece)
+		if length, over := utf8.RuneCountInString(piece), utf8.RuneCountInString(chunk)-limit; over > 0 && length > 1 {
+			chunks = append(chunks, splitChatText(piece, max(1, length-over), wrap)...)
+			continue
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// join `blocks` with blank lines into chunks of at most `limit` characters
+// (a block longer than `limit` becomes a chunk by itself)
+func joinChatBlocks(blocks []string, limit int) (chunks []string) {
+	var chunk string
+	for _, block := range blocks {
+		if len(chunk) > 0 && utf8.RuneCountInString(chunk)+2+utf8.RuneCountInString(block) > limit {
+			chunks = append(chunks, chunk)
+			chunk = ""
+		}
+		if len(chunk) > 0 {
+			chunk += "\n\n"
+		}
+		chunk += block
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// truncate `text` to `limit` characters, with an ellipsis
+func truncateChatText(text string, limit int) string {
+	if runes := []rune(text); len(runes) > limit {
+		return string(runes[:limit-1]) + "…"
+	}
+	return text
+}
+
+// POST `payload` as JSON to `url`, waiting and retrying on rate limits (http 429)
+//
+// `retryAfter` returns the delay of a rate-limited response from its body (optional, in addition to the `Retry-After` header).
+func postChatJSON(
+	ctx context.Context,
+	client *http.Client,
+	url string,
+	headers map[string]string,
+	payload any,
+	retryAfter func(body []byte) time.Duration,
+) (respBody []byte, err error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	client = sinkHTTPClient(ctx, client)
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send request: %w", err)
+		}
+		respBody, err = io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests {
+			var delay time.Duration
+			if retryAfter != nil {
+				delay = retryAfter(respBody)
+			}
+			if delay <= 0 {
+				if seconds, err := strconv.ParseFloat(resp.Header.Get("Retry-After"), 64); err == nil {
+					delay = time.Duration(seconds * float64(time.Second))
+				}
+			}
+			delay = max(delay, minChatRetryAfter)
+			if attempt >= maxChatRetries || delay > maxChatRetryAfter {
+				return nil, fmt.Errorf("%w (retry after %s)", errChatRateLimited, delay)
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(delay):
+				continue
+			}
+		}
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return nil, fmt.Errorf("request failed with http status %d: %s", resp.StatusCode, strings.TrimSpace(truncateText(string(respBody), maxChatErrorBodySize)))
+		}
+		return respBody, nil
+	}
+}
+
+// check if delivering to a chat platform should stop after given error (remaining items are retried later)
+func shouldStopChatDelivery(ctx context.Context, err error) bool {
+	return errors.Is(err, errChatRateLimited) || ctx.Err() != nil
+}
+
+// redact `secret` in given error (eg. tokens in urls of request errors)
+func redactChatError(err error, secret string) error {
+	if err == nil || len(secret) <= 0 || !strings.Contains(err.Error(), secret) {
+		return err
+	}
+	return errors.New(redactText(err.Error(), []string{secret}))
+}
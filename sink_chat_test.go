@@ -0,0 +1,405 @@
+package rf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+)
+
+const testChatSummary = "## Heading\n\nThis is **bold**, *italic* and `code` with a [link](https://example.com/a_b) & a < b.\n\n- first\n- second\n\n> quoted\n\n```\nfmt.Println(\"hi\")\n```"
+
+// test converting markdown to the markup of each platform
+func TestMarkdownToChat(t *testing.T) {
+	for name, tc := range map[string]struct {
+		markup   chatMarkup
+		expected []string
+	}{
+		"telegram": {
+			markup: telegramMarkup,
+			expected: []string{
+				"<b>Heading</b>\n\nThis is <b>bold</b>, <i>italic</i> and <code>code</code> with a <a href=\"https://example.com/a_b\">link</a> &amp; a &lt; b.",
+				"• first\n• second",
+				"<blockquote>quoted</blockquote>",
+				"<pre>fmt.Println(&#34;hi&#34;)</pre>",
+			},
+		},
+		"slack": {
+			markup: slackMarkup,
+			expected: []string{
+				"*Heading*\n\nThis is *bold*, _italic_ and `code` with a <https://example.com/a_b|link> &amp; a &lt; b.",
+				"• first\n• second",
+				"> quoted",
+				"```fmt.Println(\"hi\")```",
+			},
+		},
+		"discord": {
+			markup: discordMarkup,
+			expected: []string{
+				"**Heading**\n\nThis is **bold**, *italic* and `code` with a [link](https://example.com/a_b) & a < b.",
+				"• first\n• second",
+				"> quoted",
+				"```\nfmt.Println(\"hi\")\n```",
+			},
+		},
+	} {
+		converted := markdownToChat(testChatSummary, tc.markup)
+		for _, expected := range tc.expected {
+			if !strings.Contains(converted, expected) {
+				t.Errorf("[%s] expected '%s' in converted, got %s", name, expected, converted)
+			}
+		}
+	}
+
+	// escaped in discord
+	if converted := markdownToChat("snake_case and 2*3", discordMarkup); converted != `snake\_case and 2\*3` {
+		t.Errorf("unexpected escaped text: %s", converted)
+	}
+}
+
+// test splitting long messages
+func TestSplitChatMessage(t *testing.T) {
+	paragraph := strings.Repeat("가나다 ", 20) // 80 runes
+	text := strings.TrimSpace(strings.Repeat(paragraph+"\n\n", 5))
+
+	chunks := splitChatMessage(text, 200)
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %d: %q", len(chunks), chunks)
+	}
+	for _, chunk := range chunks {
+		if utf8.RuneCountInString(chunk) > 200 || strings.HasPrefix(chunk, "\n") {
+			t.Errorf("unexpected chunk: %q", chunk)
+		}
+	}
+
+	// without any separators
+	chunks = splitChatMessage(strings.Repeat("가", 250), 100)
+	if len(chunks) != 3 || chunks[2] != strings.Repeat("가", 50) {
+		t.Errorf("unexpected chunks: %q", chunks)
+	}
+
+	if chunks := splitChatMessage("short", 100); len(chunks) != 1 || chunks[0] != "short" {
+		t.Errorf("unexpected chunks: %q", chunks)
+	}
+}
+
+// test splitting long summaries with links and code blocks into messages without broken markup
+func TestSplitLongChatSummary(t *testing.T) {
+	var sb strings.Builder
+	for i := range 40 {
+		fmt.Fprintf(&sb, "Paragraph %d with a [link to somewhere](https://example.com/%d?a=1&b=2) and **bold text** in it, %s\n\n", i, i, strings.Repeat("lorem ipsum ", 10))
+		if i%10 == 0 {
+			fmt.Fprintf(&sb, "```\nfor i := 0; i < %d; i++ {\n\tfmt.Println(\"<%d>\")\n}\n```\n\n", i, i)
+		}
+	}
+	fmt.Fprintf(&sb, "```\n%s```\n\n", strings.Repeat("x := a < b && c > d\n", 300))           // a code block longer than the limits
+	fmt.Fprintf(&sb, "> %s\n\n", strings.Repeat("quoted [link](https://example.com/q) ", 150)) // a quote longer than the limits
+	summary := sb.String()
+	if utf8.RuneCountInString(summary) <= maxTelegramMessageLength {
+		t.Fatalf("summary is too short: %d", utf8.RuneCountInString(summary))
+	}
+	item := CachedItem{Title: "Long", Link: "https://example.com/long", Summary: summary, Comments: "https://example.com/comments"}
+
+	// telegram
+	messages := telegramMessages(item)
+	if len(messages) < 2 {
+		t.Errorf("expected multiple telegram messages, got %d", len(messages))
+	}
+	for _, message := range messages {
+		if utf8.RuneCountInString(message) > maxTelegramMessageLength {
+			t.Errorf("telegram message is too long: %d", utf8.RuneCountInString(message))
+		}
+		for _, tag := range []string{"a", "b", "pre", "code", "blockquote"} {
+			if opened, closed := strings.Count(message, "<"+tag+">")+strings.Count(message, "<"+tag+" "), strings.Count(message, "</"+tag+">"); opened != closed {
+				t.Errorf("unbalanced <%s> in telegram message (%d opened, %d closed): %s", tag, opened, closed, message)
+			}
+		}
+	}
+
+	// slack
+	blocks := slackBlocks(item)
+	for _, block := range blocks[1 : len(blocks)-1] {
+		if utf8.RuneCountInString(block.Text.Text) > maxSlackSectionLength {
+			t.Errorf("slack section is too long: %d", utf8.RuneCountInString(block.Text.Text))
+		}
+		if opened, closed := strings.Count(block.Text.Text, "<"), strings.Count(block.Text.Text, ">"); opened != closed {
+			t.Errorf("broken links in slack section (%d opened, %d closed): %s", opened, closed, block.Text.Text)
+		}
+		if strings.Count(block.Text.Text, "```")%2 != 0 {
+			t.Errorf("unclosed code block in slack section: %s", block.Text.Text)
+		}
+	}
+
+	// discord
+	for _, embed := range discordEmbeds(item) {
+		if utf8.RuneCountInString(embed.Description) > maxDiscordDescriptionLength {
+			t.Errorf("discord description is too long: %d", utf8.RuneCountInString(embed.Description))
+		}
+		if strings.Count(embed.Description, "```")%2 != 0 {
+			t.Errorf("unclosed code block in discord description: %s", embed.Description)
+		}
+		if strings.Count(embed.Description, "](") != strings.Count(embed.Description, "[link")+strings.Count(embed.Description, "[Comments") {
+			t.Errorf("broken links in discord description: %s", embed.Description)
+		}
+	}
+}
+
+// chatStub is a stub server of chat platforms, which rate-limits the first request
+type chatStub struct {
+	mu       sync.Mutex
+	requests []map[string]any
+	paths    []string
+	headers  []http.Header
+	limited  bool
+}
+
+// start a stub server which responds to rate-limited requests with `limit`, and others with `ok`
+func newChatStub(t *testing.T, limit func(w http.ResponseWriter), ok string) (*chatStub, *httptest.Server) {
+	stub := &chatStub{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stub.mu.Lock()
+		defer stub.mu.Unlock()
+
+		if !stub.limited {
+			stub.limited = true
+			limit(w)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		var req map[string]any
+		_ = json.Unmarshal(body, &req)
+		stub.requests = append(stub.requests, req)
+		stub.paths = append(stub.paths, r.URL.Path)
+		stub.headers = append(stub.headers, r.Header.Clone())
+
+		_, _ = io.WriteString(w, ok)
+	}))
+	t.Cleanup(server.Close)
+	return stub, server
+}
+
+// test items for chat sinks
+func testChatItems() []CachedItem {
+	return []CachedItem{
+		{GUID: "chat-1", Title: "First", Link: "https://example.com/1", Comments: "https://example.com/1/comments", Summary: testChatSummary},
+		{GUID: "chat-2", Title: "Second", Link: "https://example.com/2", Summary: strings.TrimSpace(strings.Repeat(strings.Repeat("long ", 200)+"\n\n", 10))}, // ~10000 chars
+	}
+}
+
+// test delivering to telegram
+func TestTelegramSink(t *testing.T) {
+	stub, server := newChatStub(t, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = io.WriteString(w, `{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 0","parameters":{"retry_after":0}}`)
+	}, `{"ok":true}`)
+
+	sink := NewTelegramSink("telegram", "123:secret", "-100123")
+	sink.SetBaseURL(server.URL + "/")
+	if err := sink.Deliver(context.Background(), testChatItems()).Err(); err != nil {
+		t.Fatalf("failed to deliver to telegram: %s", err)
+	}
+
+	if len(stub.requests) != 4 { // 1 + 3 (split)
+		t.Fatalf("expected 4 messages, got %d", len(stub.requests))
+	}
+	if stub.paths[0] != "/bot123:secret/sendMessage" || stub.requests[0]["chat_id"] != "-100123" || stub.requests[0]["parse_mode"] != "HTML" {
+		t.Errorf("unexpected request: %s %+v", stub.paths[0], stub.requests[0])
+	}
+	text := stub.requests[0]["text"].(string)
+	if !strings.HasPrefix(text, `<a href="https://example.com/1"><b>First</b></a>`) || !strings.HasSuffix(text, `<a href="https://example.com/1/comments">Comments</a>`) {
+		t.Errorf("unexpected text: %s", text)
+	}
+	for _, req := range stub.requests[1:] {
+		if length := utf8.RuneCountInString(req["text"].(string)); length > maxTelegramMessageLength {
+			t.Errorf("expected messages in the limit, got %d", length)
+		}
+	}
+
+	// items sent before a long rate limit are delivered, and the remaining ones are not attempted
+	requests := 0
+	limitedServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests++; requests > 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = io.WriteString(w, `{"ok":false,"parameters":{"retry_after":3600}}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"ok":true}`)
+	}))
+	defer limitedServer.Close()
+	sink.SetBaseURL(limitedServer.URL)
+	results := sink.Deliver(context.Background(), append(testChatItems(), CachedItem{GUID: "chat-3", Title: "Third", Summary: "third"}))
+	if len(results) != 2 || results[0].Err != nil || results[1].Err == nil {
+		t.Errorf("unexpected results: %+v", results)
+	}
+
+	// errors without tokens
+	sink.SetBaseURL("http://127.0.0.1:1")
+	if err := sink.Deliver(context.Background(), testChatItems()).Err(); err == nil || strings.Contains(err.Error(), "secret") {
+		t.Errorf("expected error without token, got %v", err)
+	}
+}
+
+// test resuming items split into several messages from their first failed message
+func TestChatSinkResumesParts(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var texts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		var req map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		texts = append(texts, req["text"].(string))
+		if len(texts) == 3 { // (the second message of the long item fails once)
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"ok":false,"description":"Bad Request"}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"ok":true}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithDB([]string{"key"}, nil, filepath.Join(t.TempDir(), "resume.db"))
+	if err != nil {
+		t.Fatalf("failed to create client: %s", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	sink := NewTelegramSink("telegram", "123:secret", "-100123")
+	sink.SetBaseURL(server.URL)
+	_ = client.SetSinks([]Sink{sink})
+	for _, item := range testChatItems() {
+		if err := client.cache.Save(ctx, ItemToCache{Item: testFeedItem(item.GUID, item.Title), Title: item.Title, Summary: item.Summary}); err != nil {
+			t.Fatalf("failed to save item: %s", err)
+		}
+	}
+
+	if err := client.DeliverToSinks(ctx); err == nil {
+		t.Errorf("expected error of the failed message")
+	}
+	deliveries, _ := client.ListDeliveries(ctx, "telegram")
+	if len(deliveries) != 2 || deliveries[1].Delivered() || deliveries[1].DeliveredParts != 1 {
+		t.Fatalf("expected delivered parts to be tracked, got %+v", deliveries)
+	}
+
+	if err := client.DeliverToSinks(ctx); err != nil {
+		t.Fatalf("failed to deliver again: %s", err)
+	}
+	if len(texts) != 5 || texts[3] != texts[2] || texts[4] == texts[1] {
+		t.Errorf("expected delivery to resume from the failed message, got %d messages", len(texts))
+	}
+	if deliveries, _ := client.ListDeliveries(ctx, "telegram"); len(deliveries) != 2 || !deliveries[1].Delivered() {
+		t.Errorf("expected all items to be delivered, got %+v", deliveries)
+	}
+}
+
+// test delivering to slack
+func TestSlackSink(t *testing.T) {
+	stub, server := newChatStub(t, func(w http.ResponseWriter) {
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}, `{"ok":true}`)
+
+	sink := NewSlackSink("slack", "xoxb-token", "C123")
+	sink.SetBaseURL(server.URL)
+	if err := sink.Deliver(context.Background(), testChatItems()).Err(); err != nil {
+		t.Fatalf("failed to deliver to slack: %s", err)
+	}
+
+	if len(stub.requests) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(stub.requests))
+	}
+	if stub.paths[0] != "/chat.postMessage" || stub.headers[0].Get("Authorization") != "Bearer xoxb-token" || stub.requests[0]["channel"] != "C123" {
+		t.Errorf("unexpected request: %s %+v", stub.paths[0], stub.requests[0])
+	}
+	blocks := stub.requests[0]["blocks"].([]any)
+	if header := blocks[0].(map[string]any); header["type"] != "header" {
+		t.Errorf("unexpected header block: %+v", header)
+	}
+	if ctx := blocks[len(blocks)-1].(map[string]any); !strings.Contains(fmt.Sprint(ctx["elements"]), "<https://example.com/1/comments|Comments>") {
+		t.Errorf("unexpected context block: %+v", ctx)
+	}
+	longBlocks := stub.requests[1]["blocks"].([]any)
+	if len(longBlocks) < 5 { // header + 4 sections (split) + context
+		t.Errorf("expected long summary to be split into sections, got %d blocks", len(longBlocks))
+	}
+	for _, block := range longBlocks {
+		if text, ok := block.(map[string]any)["text"].(map[string]any); ok && utf8.RuneCountInString(text["text"].(string)) > maxSlackSectionLength {
+			t.Errorf("expected sections in the limit")
+		}
+	}
+
+	// api errors with http status 200
+	_, errServer := newChatStub(t, func(w http.ResponseWriter) {
+		_, _ = io.WriteString(w, `{"ok":false,"error":"channel_not_found"}`)
+	}, `{"ok":false,"error":"channel_not_found"}`)
+	sink.SetBaseURL(errServer.URL)
+	if err := sink.Deliver(context.Background(), testChatItems()).Err(); err == nil || !strings.Contains(err.Error(), "channel_not_found") {
+		t.Errorf("expected api error, got %v", err)
+	}
+}
+
+// test delivering to discord
+func TestDiscordSink(t *testing.T) {
+	stub, server := newChatStub(t, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = io.WriteString(w, `{"message":"You are being rate limited.","retry_after":0.01,"global":false}`)
+	}, ``)
+
+	sink := NewDiscordSink("discord", "123", "token")
+	sink.SetBaseURL(server.URL)
+	if err := sink.Deliver(context.Background(), testChatItems()).Err(); err != nil {
+		t.Fatalf("failed to deliver to discord: %s", err)
+	}
+
+	if len(stub.requests) < 2 || len(stub.requests) >= 4 { // (embeds grouped in the limit of 6000 chars)
+		t.Fatalf("expected embeds to be grouped into 2 or 3 messages, got %d", len(stub.requests))
+	}
+	if stub.paths[0] != "/webhooks/123/token" {
+		t.Errorf("unexpected path: %s", stub.paths[0])
+	}
+	var embeds int
+	for _, req := range stub.requests {
+		total := 0
+		for _, embed := range req["embeds"].([]any) {
+			embed := embed.(map[string]any)
+			description, _ := embed["description"].(string)
+			title, _ := embed["title"].(string)
+			if utf8.RuneCountInString(description) > maxDiscordDescriptionLength {
+				t.Errorf("expected descriptions in the limit")
+			}
+			total += utf8.RuneCountInString(description) + utf8.RuneCountInString(title)
+			embeds++
+		}
+		if total > maxDiscordEmbedsLength {
+			t.Errorf("expected messages in the limit, got %d", total)
+		}
+	}
+	first := stub.requests[0]["embeds"].([]any)[0].(map[string]any)
+	if first["title"] != "First" || first["url"] != "https://example.com/1" || !strings.Contains(first["description"].(string), "[Comments](https://example.com/1/comments)") {
+		t.Errorf("unexpected embed: %+v", first)
+	}
+	if embeds != 4 { // 1 + 3 (split)
+		t.Errorf("expected 4 embeds, got %d", embeds)
+	}
+
+	// rate limits too long to wait for
+	_, limitedServer := newChatStub(t, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = io.WriteString(w, `{"retry_after":3600}`)
+	}, ``)
+	sink.SetBaseURL(limitedServer.URL)
+	if err := sink.Deliver(context.Background(), testChatItems()).Err(); err == nil || !strings.Contains(err.Error(), "rate limited") {
+		t.Errorf("expected rate limit error, got %v", err)
+	}
+}
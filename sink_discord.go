@@ -0,0 +1,182 @@
+package rf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	defaultDiscordBaseURL = "https://discord.com/api"
+
+	maxDiscordTitleLength       = 256
+	maxDiscordDescriptionLength = 4096
+	maxDiscordEmbeds            = 10   // per message
+	maxDiscordEmbedsLength      = 6000 // of all embeds in a message
+)
+
+// discordMarkup is the markdown of discord messages
+var discordMarkup = chatMarkup{
+	escape: func(text string) string {
+		return strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "~", `\~`, "`", "\\`", "|", `\|`, ">", `\>`).Replace(text)
+	},
+	bold:   func(inner string) string { return wrapChatMarkup(inner, "**") },
+	italic: func(inner string) string { return wrapChatMarkup(inner, "*") },
+	strike: func(inner string) string { return wrapChatMarkup(inner, "~~") },
+	code:   func(text string) string { return "`" + text + "`" },
+	pre:    func(text string) string { return "```\n" + text + "\n```" },
+	link: func(href, inner string) string {
+		return fmt.Sprintf("[%s](%s)", inner, strings.NewReplacer("(", "%28", ")", "%29").Replace(href))
+	},
+	quote: func(inner string) string { return "> " + strings.ReplaceAll(inner, "\n", "\n> ") },
+}
+
+// discordEmbed is an embed of discord messages
+type discordEmbed struct {
+	Title       string `json:"title,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// length of the embed, counted for the limit of messages
+func (e discordEmbed) length() int {
+	return utf8.RuneCountInString(e.Title) + utf8.RuneCountInString(e.Description)
+}
+
+// DiscordSink is a sink which posts summarized items to a discord channel with a webhook
+type DiscordSink struct {
+	name         string
+	webhookID    string
+	webhookToken string
+	baseURL      string
+
+	httpClient *http.Client // nil for the http client of `Client` delivering items
+}
+
+// NewDiscordSink returns a new sink which posts items as embeds with the webhook of `webhookID` and `webhookToken`.
+// (eg. "https://discord.com/api/webhooks/{webhookID}/{webhookToken}")
+//
+// Summaries longer than discord's limits are split into several embeds (and messages).
+func NewDiscordSink(name, webhookID, webhookToken string) *DiscordSink {
+	return &DiscordSink{
+		name:         name,
+		webhookID:    webhookID,
+		webhookToken: webhookToken,
+		baseURL:      defaultDiscordBaseURL,
+	}
+}
+
+// SetBaseURL sets the base url of discord api.
+func (s *DiscordSink) SetBaseURL(baseURL string) {
+	s.baseURL = strings.TrimSuffix(baseURL, "/")
+}
+
+// SetHTTPClient sets the http client of discord api requests. (default: the http client of `Client`)
+func (s *DiscordSink) SetHTTPClient(client *http.Client) {
+	s.httpClient = client
+}
+
+// Name returns the name of the sink.
+func (s *DiscordSink) Name() string {
+	return s.name
+}
+
+// Deliver posts given items to the channel, with as many embeds in a message as the limits allow.
+//
+// An item fails if any of the messages with its embeds fails, and all of its embeds are posted again when retried.
+// (as embeds of several items can be in a message, items are delivered at least once)
+func (s *DiscordSink) Deliver(ctx context.Context, items []CachedItem) BatchResults {
+	var embeds []discordEmbed
+	var owners []int // (indices of items which embeds belong to)
+	for i, item := range items {
+		for _, embed := range discordEmbeds(item) {
+			embeds = append(embeds, embed)
+			owners = append(owners, i)
+		}
+	}
+
+	errs := make([]error, len(items))
+	attempted := len(items)
+	offset := 0
+	for _, message := range groupDiscordEmbeds(embeds) {
+		messageOwners := owners[offset : offset+len(message)]
+		offset += len(message)
+
+		if err := s.execute(ctx, message); err != nil {
+			for _, i := range messageOwners {
+				errs[i] = fmt.Errorf("failed to post item '%s' to discord: %w", items[i].GUID, err)
+			}
+
+			if shouldStopChatDelivery(ctx, err) {
+				attempted = messageOwners[len(messageOwners)-1] + 1
+				break
+			}
+		}
+	}
+
+	var results BatchResults
+	for i, item := range items[:attempted] {
+		results = append(results, BatchResult{GUID: item.GUID, Err: errs[i]})
+	}
+	return results
+}
+
+// build discord embeds of given item
+func discordEmbeds(item CachedItem) (embeds []discordEmbed) {
+	blocks := markdownToChatBlocks(item.Summary, discordMarkup, maxDiscordDescriptionLength)
+	if len(item.Comments) > 0 {
+		blocks = append(blocks, discordMarkup.link(item.Comments, "Comments"))
+	}
+
+	for i, chunk := range joinChatBlocks(blocks, maxDiscordDescriptionLength) {
+		embed := discordEmbed{Description: chunk}
+		if i == 0 {
+			embed.Title = truncateChatText(item.Title, maxDiscordTitleLength)
+			embed.URL = item.Link
+		}
+		embeds = append(embeds, embed)
+	}
+	if len(embeds) <= 0 {
+		embeds = append(embeds, discordEmbed{Title: truncateChatText(item.Title, maxDiscordTitleLength), URL: item.Link})
+	}
+
+	return embeds
+}
+
+// group given embeds into messages in the limits
+func groupDiscordEmbeds(embeds []discordEmbed) (messages [][]discordEmbed) {
+	var message []discordEmbed
+	length := 0
+	for _, embed := range embeds {
+		if len(message) > 0 && (len(message) >= maxDiscordEmbeds || length+embed.length() > maxDiscordEmbedsLength) {
+			messages = append(messages, message)
+			message, length = nil, 0
+		}
+		message = append(message, embed)
+		length += embed.length()
+	}
+	if len(message) > 0 {
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+// execute the webhook with given embeds
+func (s *DiscordSink) execute(ctx context.Context, embeds []discordEmbed) error {
+	_, err := postChatJSON(ctx, s.httpClient, fmt.Sprintf("%s/webhooks/%s/%s", s.baseURL, s.webhookID, s.webhookToken), nil, map[string]any{
+		"embeds": embeds,
+	}, func(body []byte) time.Duration {
+		var resp struct {
+			RetryAfter float64 `json:"retry_after"` // in seconds
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return 0
+		}
+		return time.Duration(resp.RetryAfter * float64(time.Second))
+	})
+	return redactChatError(err, s.webhookToken)
+}
@@ -0,0 +1,113 @@
+package rf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileSink is a sink which appends summarized items to a file as JSON lines
+type FileSink struct {
+	name string
+	path string
+
+	mu sync.Mutex
+}
+
+// NewFileSink returns a new sink which appends each item as a line of `SinkItem` JSON to the file at `path`.
+func NewFileSink(name, path string) *FileSink {
+	return &FileSink{
+		name: name,
+		path: path,
+	}
+}
+
+// Name returns the name of the sink.
+func (s *FileSink) Name() string {
+	return s.name
+}
+
+// Deliver appends given items to the file, all at once.
+func (s *FileSink) Deliver(ctx context.Context, items []CachedItem) BatchResults {
+	return batchResults(items, s.write(items))
+}
+
+// append given items to the file as JSON lines
+func (s *FileSink) write(items []CachedItem) error {
+	var lines []byte
+	for _, item := range items {
+		line, err := json.Marshal(NewSinkItem(item))
+		if err != nil {
+			return fmt.Errorf("failed to marshal item '%s': %w", item.GUID, err)
+		}
+		lines = append(append(lines, line...), '\n')
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open file of sink: %w", err)
+	}
+	if _, err := f.Write(lines); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write to file of sink: %w", err)
+	}
+	return f.Close()
+}
+
+// DirectorySink is a sink which writes summarized items to a directory, one JSON file per item
+type DirectorySink struct {
+	name string
+	dir  string
+}
+
+// NewDirectorySink returns a new sink which writes each item as a `SinkItem` JSON file in `dir`.
+//
+// Files are named after the hashes of items' guids, so a redelivered item overwrites its file.
+func NewDirectorySink(name, dir string) *DirectorySink {
+	return &DirectorySink{
+		name: name,
+		dir:  dir,
+	}
+}
+
+// Name returns the name of the sink.
+func (s *DirectorySink) Name() string {
+	return s.name
+}
+
+// Deliver writes given items to the directory, one file per item.
+func (s *DirectorySink) Deliver(ctx context.Context, items []CachedItem) BatchResults {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return batchResults(items, fmt.Errorf("failed to create directory of sink: %w", err))
+	}
+
+	var results BatchResults
+	for _, item := range items {
+		results = append(results, BatchResult{GUID: item.GUID, Err: s.write(item)})
+	}
+	return results
+}
+
+// write given item to its own file
+func (s *DirectorySink) write(item CachedItem) error {
+	bytes, err := json.MarshalIndent(NewSinkItem(item), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal item '%s': %w", item.GUID, err)
+	}
+
+	// (written to a temporary file first, for not leaving partially written ones)
+	path := filepath.Join(s.dir, guidHash(item.GUID)+".json")
+	if err := os.WriteFile(path+".tmp", bytes, 0o644); err != nil {
+		return fmt.Errorf("failed to write item '%s': %w", item.GUID, err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("failed to write item '%s': %w", item.GUID, err)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package rf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+const (
+	defaultSlackBaseURL = "https://slack.com/api"
+
+	maxSlackHeaderLength  = 150  // of plain text in header blocks
+	maxSlackSectionLength = 3000 // of mrkdwn text in section blocks
+	maxSlackBlocks        = 50   // per message
+)
+
+// slackMarkup is the mrkdwn of slack messages
+var slackMarkup = chatMarkup{
+	escape: func(text string) string {
+		return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+	},
+	bold:   func(inner string) string { return wrapChatMarkup(inner, "*") },
+	italic: func(inner string) string { return wrapChatMarkup(inner, "_") },
+	strike: func(inner string) string { return wrapChatMarkup(inner, "~") },
+	code:   func(text string) string { return "`" + text + "`" },
+	pre:    func(text string) string { return "```" + text + "```" },
+	link: func(href, inner string) string {
+		return fmt.Sprintf("<%s|%s>", href, strings.ReplaceAll(inner, "|", "¦"))
+	},
+	quote: func(inner string) string { return "> " + strings.ReplaceAll(inner, "\n", "\n> ") },
+}
+
+// wrap non-empty `inner` with `mark` (eg. "*bold*")
+func wrapChatMarkup(inner, mark string) string {
+	if trimmed := strings.TrimSpace(inner); len(trimmed) > 0 {
+		return mark + trimmed + mark
+	}
+	return inner
+}
+
+// slackBlock is a block of slack messages
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+// slackText is a text object of slack blocks
+type slackText struct {
+	Type string `json:"type"` // "plain_text" or "mrkdwn"
+	Text string `json:"text"`
+}
+
+// SlackSink is a sink which posts summarized items to a slack channel with a bot token
+type SlackSink struct {
+	name    string
+	token   string
+	channel string
+	baseURL string
+
+	httpClient *http.Client // nil for the http client of `Client` delivering items
+}
+
+// NewSlackSink returns a new sink which posts items to `channel` with the bot `token`, in mrkdwn blocks.
+//
+// Summaries longer than slack's limits are split into several blocks (and messages).
+func NewSlackSink(name, token, channel string) *SlackSink {
+	return &SlackSink{
+		name:    name,
+		token:   token,
+		channel: channel,
+		baseURL: defaultSlackBaseURL,
+	}
+}
+
+// SetBaseURL sets the base url of slack web api.
+func (s *SlackSink) SetBaseURL(baseURL string) {
+	s.baseURL = strings.TrimSuffix(baseURL, "/")
+}
+
+// SetHTTPClient sets the http client of slack web api requests. (default: the http client of `Client`)
+func (s *SlackSink) SetHTTPClient(client *http.Client) {
+	s.httpClient = client
+}
+
+// Name returns the name of the sink.
+func (s *SlackSink) Name() string {
+	return s.name
+}
+
+// Deliver posts given items to the channel, one (or more, if too long) message per item.
+func (s *SlackSink) Deliver(ctx context.Context, items []CachedItem) BatchResults {
+	return s.DeliverParts(ctx, items, nil)
+}
+
+// DeliverParts posts given items to the channel like `Deliver`, skipping the messages which were already posted.
+func (s *SlackSink) DeliverParts(ctx context.Context, items []CachedItem, deliveredParts map[string]int) (results BatchResults) {
+	for _, item := range items {
+		var err error
+		messages := slices.Collect(slices.Chunk(slackBlocks(item), maxSlackBlocks))
+		for i := deliveredParts[item.GUID]; i < len(messages); i++ {
+			if err = s.postMessage(ctx, truncateChatText(item.Title, maxSlackSectionLength), messages[i]); err != nil {
+				err = partialDeliveryError(fmt.Errorf("failed to post item '%s' to slack: %w", item.GUID, err), i)
+				break
+			}
+		}
+		results = append(results, BatchResult{GUID: item.GUID, Err: err})
+
+		if shouldStopChatDelivery(ctx, err) {
+			break
+		}
+	}
+	return results
+}
+
+// build slack blocks of given item
+func slackBlocks(item CachedItem) (blocks []slackBlock) {
+	blocks = append(blocks, slackBlock{
+		Type: "header",
+		Text: &slackText{Type: "plain_text", Text: truncateChatText(item.Title, maxSlackHeaderLength)},
+	})
+	for _, chunk := range joinChatBlocks(markdownToChatBlocks(item.Summary, slackMarkup, maxSlackSectionLength), maxSlackSectionLength) {
+		blocks = append(blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: chunk},
+		})
+	}
+
+	var links []string
+	if len(item.Link) > 0 {
+		links = append(links, slackMarkup.link(item.Link, "Original"))
+	}
+	if len(item.Comments) > 0 {
+		links = append(links, slackMarkup.link(item.Comments, "Comments"))
+	}
+	if len(links) > 0 {
+		blocks = append(blocks, slackBlock{
+			Type:     "context",
+			Elements: []slackText{{Type: "mrkdwn", Text: strings.Join(links, " | ")}},
+		})
+	}
+
+	return blocks
+}
+
+// post a message with blocks (and a fallback text)
+func (s *SlackSink) postMessage(ctx context.Context, text string, blocks []slackBlock) error {
+	body, err := postChatJSON(ctx, s.httpClient, s.baseURL+"/chat.postMessage", map[string]string{
+		"Authorization": "Bearer " + s.token,
+	}, map[string]any{
+		"channel": s.channel,
+		"text":    text,
+		"blocks":  blocks,
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	// (errors are returned with http status 200)
+	var resp struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	if !resp.OK {
+		return fmt.Errorf("slack api error: %s", resp.Error)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package rf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTelegramBaseURL = "https://api.telegram.org"
+
+	maxTelegramMessageLength = 4096
+)
+
+// telegramMarkup is the HTML subset of telegram messages
+var telegramMarkup = chatMarkup{
+	escape: html.EscapeString,
+	bold:   func(inner string) string { return "<b>" + inner + "</b>" },
+	italic: func(inner string) string { return "<i>" + inner + "</i>" },
+	strike: func(inner string) string { return "<s>" + inner + "</s>" },
+	code:   func(text string) string { return "<code>" + text + "</code>" },
+	pre:    func(text string) string { return "<pre>" + text + "</pre>" },
+	link: func(href, inner string) string {
+		return fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(href), inner)
+	},
+	quote: func(inner string) string { return "<blockquote>" + inner + "</blockquote>" },
+}
+
+// TelegramSink is a sink which sends summarized items to a telegram chat with a bot
+type TelegramSink struct {
+	name    string
+	token   string
+	chatID  string
+	baseURL string
+
+	httpClient *http.Client // nil for the http client of `Client` delivering items
+}
+
+// NewTelegramSink returns a new sink which sends items to `chatID` with the bot of `token`, in telegram HTML.
+//
+// Messages longer than telegram's limit are split into several ones.
+func NewTelegramSink(name, token, chatID string) *TelegramSink {
+	return &TelegramSink{
+		name:    name,
+		token:   token,
+		chatID:  chatID,
+		baseURL: defaultTelegramBaseURL,
+	}
+}
+
+// SetBaseURL sets the base url of telegram bot api. (eg. for a local bot api server)
+func (s *TelegramSink) SetBaseURL(baseURL string) {
+	s.baseURL = strings.TrimSuffix(baseURL, "/")
+}
+
+// SetHTTPClient sets the http client of telegram bot api requests. (default: the http client of `Client`)
+func (s *TelegramSink) SetHTTPClient(client *http.Client) {
+	s.httpClient = client
+}
+
+// Name returns the name of the sink.
+func (s *TelegramSink) Name() string {
+	return s.name
+}
+
+// Deliver sends given items to the chat, one (or more, if too long) message per item.
+func (s *TelegramSink) Deliver(ctx context.Context, items []CachedItem) BatchResults {
+	return s.DeliverParts(ctx, items, nil)
+}
+
+// DeliverParts sends given items to the chat like `Deliver`, skipping the messages which were already sent.
+func (s *TelegramSink) DeliverParts(ctx context.Context, items []CachedItem, deliveredParts map[string]int) (results BatchResults) {
+	for _, item := range items {
+		var err error
+		messages := telegramMessages(item)
+		for i := deliveredParts[item.GUID]; i < len(messages); i++ {
+			if err = s.sendMessage(ctx, messages[i]); err != nil {
+				err = partialDeliveryError(fmt.Errorf("failed to send item '%s' to telegram: %w", item.GUID, err), i)
+				break
+			}
+		}
+		results = append(results, BatchResult{GUID: item.GUID, Err: err})
+
+		if shouldStopChatDelivery(ctx, err) {
+			break
+		}
+	}
+	return results
+}
+
+// build telegram messages of given item
+func telegramMessages(item CachedItem) []string {
+	title := telegramMarkup.bold(html.EscapeString(item.Title))
+	if len(item.Link) > 0 {
+		title = telegramMarkup.link(item.Link, title)
+	}
+	blocks := append([]string{title}, markdownToChatBlocks(item.Summary, telegramMarkup, maxTelegramMessageLength)...)
+	if len(item.Comments) > 0 {
+		blocks = append(blocks, telegramMarkup.link(item.Comments, "Comments"))
+	}
+
+	return joinChatBlocks(blocks, maxTelegramMessageLength)
+}
+
+// send a message in telegram HTML
+func (s *TelegramSink) sendMessage(ctx context.Context, text string) error {
+	_, err := postChatJSON(ctx, s.httpClient, fmt.Sprintf("%s/bot%s/sendMessage", s.baseURL, s.token), nil, map[string]any{
+		"chat_id":    s.chatID,
+		"text":       text,
+		"parse_mode": "HTML",
+	}, func(body []byte) time.Duration {
+		var resp struct {
+			Parameters struct {
+				RetryAfter int `json:"retry_after"`
+			} `json:"parameters"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return 0
+		}
+		return time.Duration(resp.Parameters.RetryAfter) * time.Second
+	})
+	return redactChatError(err, s.token)
+}
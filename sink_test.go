@@ -0,0 +1,397 @@
+package rf
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// testSink is a sink which records delivered items, failing while `fail` is set (or for `failGUIDs`)
+type testSink struct {
+	name      string
+	fail      bool
+	failGUIDs map[string]bool
+	limit     int        // maximum number of items attempted at once (0 = unlimited)
+	delivered [][]string // guids of delivered batches
+}
+
+func (s *testSink) Name() string {
+	return s.name
+}
+
+func (s *testSink) Deliver(ctx context.Context, items []CachedItem) (results BatchResults) {
+	if s.limit > 0 && len(items) > s.limit {
+		items = items[:s.limit]
+	}
+
+	var guids []string
+	for _, item := range items {
+		if s.fail || s.failGUIDs[item.GUID] {
+			results = append(results, BatchResult{GUID: item.GUID, Err: fmt.Errorf("sink unavailable")})
+			continue
+		}
+		guids = append(guids, item.GUID)
+		results = append(results, BatchResult{GUID: item.GUID})
+	}
+	if len(guids) > 0 {
+		s.delivered = append(s.delivered, guids)
+	}
+	return results
+}
+
+// test delivering summarized items to sinks with tracking
+func TestDeliverToSinks(t *testing.T) {
+	ctx := context.Background()
+
+	dbc, err := newDBCache(filepath.Join(t.TempDir(), "sink.db"))
+	if err != nil {
+		t.Fatalf("failed to create dbCache: %s", err)
+	}
+	defer func() { _ = dbc.Close() }()
+
+	for name, cache := range map[string]FeedsItemsCacheV2{
+		"mem": newMemCache(),
+		"db":  dbc,
+	} {
+		t.Run(name, func(t *testing.T) {
+			client := NewClient([]string{"key"}, nil)
+			client.cache = cache
+
+			if err := client.SetSinks([]Sink{&testSink{name: "dup"}, &testSink{name: "dup"}}); err == nil {
+				t.Errorf("expected error for duplicated names of sinks")
+			}
+
+			ok, failing := &testSink{name: "ok"}, &testSink{name: "failing", fail: true}
+			if err := client.SetSinks([]Sink{ok, failing}); err != nil {
+				t.Fatalf("failed to set sinks: %s", err)
+			}
+
+			for _, item := range []ItemToCache{
+				{Item: testFeedItem("sink-1", "Item 1"), Title: "Item 1", Summary: "summary 1"},
+				{Item: testFeedItem("sink-2", "Item 2"), Title: "Item 2", Summary: failedSummary("", fmt.Errorf("test error"))},
+				{Item: testFeedItem("sink-3", "Item 3"), Title: "Item 3", Summary: "summary 3"},
+			} {
+				if err := cache.Save(ctx, item); err != nil {
+					t.Fatalf("failed to save item: %s", err)
+				}
+			}
+
+			// first delivery
+			if err := client.DeliverToSinks(ctx); err == nil || !strings.Contains(err.Error(), "failing") {
+				t.Errorf("expected error of the failing sink, got %v", err)
+			}
+			if len(ok.delivered) != 1 || len(ok.delivered[0]) != 2 {
+				t.Errorf("expected 2 summarized items to be delivered, got %v", ok.delivered)
+			}
+			deliveries, err := client.ListDeliveries(ctx, "failing")
+			if err != nil || len(deliveries) != 2 || deliveries[0].Delivered() || deliveries[0].Attempts != 1 || deliveries[0].LastError != "sink unavailable" {
+				t.Errorf("unexpected deliveries of failing sink: %+v (%v)", deliveries, err)
+			}
+
+			// not delivered twice, and failures are retried
+			failing.fail = false
+			if err := cache.Save(ctx, ItemToCache{Item: testFeedItem("sink-4", "Item 4"), Title: "Item 4", Summary: "summary 4"}); err != nil {
+				t.Fatalf("failed to save item: %s", err)
+			}
+			if err := client.DeliverToSinks(ctx); err != nil {
+				t.Errorf("failed to deliver: %s", err)
+			}
+			if len(ok.delivered) != 2 || strings.Join(ok.delivered[1], ",") != "sink-4" {
+				t.Errorf("expected only the new item to be delivered, got %v", ok.delivered)
+			}
+			if len(failing.delivered) != 1 || len(failing.delivered[0]) != 3 {
+				t.Errorf("expected failed items to be retried, got %v", failing.delivered)
+			}
+			deliveries, _ = client.ListDeliveries(ctx, "failing")
+			if len(deliveries) != 3 || !deliveries[0].Delivered() || deliveries[0].Attempts != 2 || deliveries[0].LastError != "" {
+				t.Errorf("unexpected deliveries after retry: %+v", deliveries)
+			}
+
+			// deleted along with items
+			if err := cache.DeleteMany(ctx, []string{"sink-1"}).Err(); err != nil {
+				t.Fatalf("failed to delete item: %s", err)
+			}
+			if deliveries, _ := client.ListDeliveries(ctx, "ok"); len(deliveries) != 2 {
+				t.Errorf("expected deliveries to be deleted along with items, got %+v", deliveries)
+			}
+		})
+	}
+}
+
+// test giving up deliveries after too many failures
+func TestDeliverToSinksMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	client := NewClient([]string{"key"}, nil)
+
+	failing := &testSink{name: "failing", fail: true}
+	_ = client.SetSinks([]Sink{failing})
+	_ = client.cache.Save(ctx, ItemToCache{Item: testFeedItem("sink-max", "Item"), Title: "Item", Summary: "summary"})
+
+	for range maxSinkDeliveryAttempts + 1 {
+		_ = client.DeliverToSinks(ctx)
+	}
+	failing.fail = false
+	if err := client.DeliverToSinks(ctx); err != nil || len(failing.delivered) != 0 {
+		t.Errorf("expected no more retries, got %v (%v)", failing.delivered, err)
+	}
+	if deliveries, _ := client.ListDeliveries(ctx, "failing"); len(deliveries) != 1 || deliveries[0].Attempts != maxSinkDeliveryAttempts {
+		t.Errorf("unexpected deliveries: %+v", deliveries)
+	}
+}
+
+// test retrying only the failed (or not attempted) items of a batch
+func TestDeliverToSinksPartially(t *testing.T) {
+	ctx := context.Background()
+	client := NewClient([]string{"key"}, nil)
+
+	sink := &testSink{name: "partial", failGUIDs: map[string]bool{"partial-2": true}}
+	_ = client.SetSinks([]Sink{sink})
+	for _, guid := range []string{"partial-1", "partial-2", "partial-3"} {
+		_ = client.cache.Save(ctx, ItemToCache{Item: testFeedItem(guid, guid), Title: guid, Summary: "summary"})
+	}
+
+	if err := client.DeliverToSinks(ctx); err == nil || !strings.Contains(err.Error(), "partial-2") {
+		t.Errorf("expected error of the failed item, got %v", err)
+	}
+	sink.failGUIDs = nil
+	if err := client.DeliverToSinks(ctx); err != nil {
+		t.Errorf("failed to deliver: %s", err)
+	}
+	if len(sink.delivered) != 2 || strings.Join(sink.delivered[1], ",") != "partial-2" {
+		t.Errorf("expected only the failed item to be delivered again, got %v", sink.delivered)
+	}
+
+	// items which were not attempted are not counted
+	limited := &testSink{name: "limited", limit: 1}
+	_ = client.SetSinks([]Sink{limited})
+	_ = client.DeliverToSinks(ctx)
+	deliveries, _ := client.ListDeliveries(ctx, "limited")
+	if len(deliveries) != 1 || !deliveries[0].Delivered() {
+		t.Errorf("expected only the attempted item to be tracked, got %+v", deliveries)
+	}
+	_ = client.DeliverToSinks(ctx)
+	_ = client.DeliverToSinks(ctx)
+	if deliveries, _ := client.ListDeliveries(ctx, "limited"); len(deliveries) != 3 || len(limited.delivered) != 3 {
+		t.Errorf("expected remaining items to be delivered later, got %+v", deliveries)
+	}
+}
+
+// test delivering with a webhook sink
+func TestWebhookSink(t *testing.T) {
+	secret := []byte("secret")
+
+	var received WebhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !VerifyWebhookSignature(secret, body, r.Header.Get(WebhookSignatureHeader)) {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_ = json.Unmarshal(body, &received)
+	}))
+	defer server.Close()
+
+	item := CachedItem{GUID: "guid-webhook", Title: "Title", Link: "https://example.com", Summary: "**summary**", Categories: []string{"tech"}}
+
+	sink := NewWebhookSink("webhook", server.URL, string(secret))
+	sink.SetHeaders(map[string]string{"Authorization": "Bearer token"})
+	if err := sink.Deliver(context.Background(), []CachedItem{item}).Err(); err != nil {
+		t.Fatalf("failed to deliver to webhook: %s", err)
+	}
+	if received.Sink != "webhook" || len(received.Items) != 1 {
+		t.Fatalf("unexpected payload: %+v", received)
+	}
+	if received.Items[0].GUID != "guid-webhook" || received.Items[0].SummaryHTML != "<p><strong>summary</strong></p>\n" || received.Items[0].Categories[0] != "tech" {
+		t.Errorf("unexpected item in payload: %+v", received.Items[0])
+	}
+
+	// wrong secret
+	if err := NewWebhookSink("webhook", server.URL, "wrong").Deliver(context.Background(), []CachedItem{item}).Err(); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("expected error for wrong signature, got %v", err)
+	}
+}
+
+// test sending requests of sinks with the client's http client
+func TestSinksWithClientHTTPClient(t *testing.T) {
+	ctx := context.Background()
+
+	var requested atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested.Add(1)
+	}))
+	defer server.Close()
+
+	transport := &countingTransport{}
+
+	client := NewClient([]string{"key"}, nil)
+	client.SetHTTPTransport(transport)
+	client.SetSafeDial(&SafeDialPolicy{})
+	sink := NewWebhookSink("webhook", server.URL, "")
+	_ = client.SetSinks([]Sink{sink})
+	_ = client.cache.Save(ctx, ItemToCache{Item: testFeedItem("sink-http-1", "Item 1"), Title: "Item 1", Summary: "summary"})
+
+	// client's http client, not blocked in safe-dial mode (urls of sinks are set by the operator)
+	if err := client.DeliverToSinks(ctx); err != nil || requested.Load() != 1 || transport.count.Load() != 1 {
+		t.Errorf("expected request with client's http client, got %v (%d requests, %d through transport)", err, requested.Load(), transport.count.Load())
+	}
+
+	// sink's own http client
+	sink.SetHTTPClient(http.DefaultClient)
+	_ = client.cache.Save(ctx, ItemToCache{Item: testFeedItem("sink-http-2", "Item 2"), Title: "Item 2", Summary: "summary"})
+	if err := client.DeliverToSinks(ctx); err != nil || requested.Load() != 2 || transport.count.Load() != 1 {
+		t.Errorf("expected request with sink's own http client, got %v (%d requests, %d through transport)", err, requested.Load(), transport.count.Load())
+	}
+}
+
+// test delivering all cached items to a new sink
+func TestDeliverToNewSink(t *testing.T) {
+	ctx := context.Background()
+
+	client, err := NewClientWithDB([]string{"key"}, nil, filepath.Join(t.TempDir(), "new-sink.db"))
+	if err != nil {
+		t.Fatalf("failed to create client: %s", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	var items []ItemToCache
+	for i := range listLimit + 5 {
+		guid := fmt.Sprintf("new-sink-%03d", i)
+		items = append(items, ItemToCache{Item: testFeedItem(guid, guid), Title: guid, Summary: "summary"})
+	}
+	if err := client.cache.SaveMany(ctx, items).Err(); err != nil {
+		t.Fatalf("failed to save items: %s", err)
+	}
+
+	sink := &testSink{name: "new"}
+	_ = client.SetSinks([]Sink{sink})
+	if err := client.DeliverToSinks(ctx); err != nil {
+		t.Fatalf("failed to deliver to sinks: %s", err)
+	}
+	var delivered []string
+	for _, batch := range sink.delivered {
+		delivered = append(delivered, batch...)
+	}
+	if len(delivered) != listLimit+5 || !slices.Contains(delivered, "new-sink-000") || !slices.Contains(delivered, "new-sink-104") {
+		t.Errorf("expected all %d items to be delivered, got %d", listLimit+5, len(delivered))
+	}
+}
+
+// test delivering with file and directory sinks
+func TestFileSinks(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	items := []CachedItem{
+		{GUID: "guid-file-1", Title: "Item 1", Summary: "summary 1"},
+		{GUID: "guid-file-2", Title: "Item 2", Summary: "summary 2"},
+	}
+
+	// json lines, appended
+	path := filepath.Join(dir, "items.jsonl")
+	sink := NewFileSink("file", path)
+	for _, item := range items {
+		if err := sink.Deliver(ctx, []CachedItem{item}).Err(); err != nil {
+			t.Fatalf("failed to deliver to file: %s", err)
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open file: %s", err)
+	}
+	defer func() { _ = f.Close() }()
+	var guids []string
+	for scanner := bufio.NewScanner(f); scanner.Scan(); {
+		var item SinkItem
+		if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+			t.Fatalf("failed to parse line: %s", err)
+		}
+		guids = append(guids, item.GUID)
+	}
+	if strings.Join(guids, ",") != "guid-file-1,guid-file-2" {
+		t.Errorf("unexpected lines: %v", guids)
+	}
+
+	// one file per item
+	itemsDir := filepath.Join(dir, "items")
+	if err := NewDirectorySink("dir", itemsDir).Deliver(ctx, items).Err(); err != nil {
+		t.Fatalf("failed to deliver to directory: %s", err)
+	}
+	bytes, err := os.ReadFile(filepath.Join(itemsDir, guidHash("guid-file-2")+".json"))
+	if err != nil {
+		t.Fatalf("failed to read item file: %s", err)
+	}
+	var item SinkItem
+	if err := json.Unmarshal(bytes, &item); err != nil || item.Title != "Item 2" {
+		t.Errorf("unexpected item file: %s (%v)", bytes, err)
+	}
+	if entries, _ := os.ReadDir(itemsDir); len(entries) != 2 {
+		t.Errorf("expected 2 item files, got %d", len(entries))
+	}
+}
+
+// make sure `SummarizeAndCacheFeeds` delivers to sinks even without items
+func TestSummarizeAndCacheFeedsDeliversToSinks(t *testing.T) {
+	ctx := context.Background()
+	client := NewClient([]string{"key"}, nil)
+
+	sink := &testSink{name: "sink"}
+	_ = client.SetSinks([]Sink{sink})
+	_ = client.cache.Save(ctx, ItemToCache{Item: testFeedItem("sink-summarized", "Item"), Title: "Item", Summary: "summary"})
+
+	if err := client.SummarizeAndCacheFeeds(ctx, []gofeed.Feed{}); err != nil {
+		t.Errorf("failed to summarize and cache feeds: %s", err)
+	}
+	if len(sink.delivered) != 1 {
+		t.Errorf("expected cached items to be delivered, got %v", sink.delivered)
+	}
+}
+
+// test concurrent deliveries to sinks (items should be delivered only once)
+func TestDeliverToSinksConcurrently(t *testing.T) {
+	ctx := context.Background()
+
+	client := NewClient([]string{"key"}, nil)
+	sink := &testSink{name: "concurrent"}
+	if err := client.SetSinks([]Sink{sink}); err != nil {
+		t.Fatalf("failed to set sinks: %s", err)
+	}
+	for i := range 10 {
+		guid := fmt.Sprintf("concurrent-%d", i)
+		_ = client.cache.Save(ctx, ItemToCache{Item: testFeedItem(guid, guid), Title: guid, Summary: "summary"})
+	}
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			if err := client.DeliverToSinks(ctx); err != nil {
+				t.Errorf("failed to deliver to sinks: %s", err)
+			}
+		})
+	}
+	wg.Wait()
+
+	delivered := 0
+	for _, batch := range sink.delivered {
+		delivered += len(batch)
+	}
+	if delivered != 10 {
+		t.Errorf("expected 10 items to be delivered once, got %d deliveries", delivered)
+	}
+}
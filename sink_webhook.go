@@ -0,0 +1,125 @@
+package rf
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// WebhookSignatureHeader is the header of webhook requests with the HMAC-SHA256 signature of their bodies (eg. "sha256=0123abcd...")
+	WebhookSignatureHeader = `X-Signature-256`
+
+	webhookSignaturePrefix = "sha256="
+
+	maxWebhookErrorBodySize = 1024 // response bodies of failed webhook requests are truncated to this size (in bytes) in errors
+)
+
+// WebhookPayload is a struct for the JSON body of webhook requests
+type WebhookPayload struct {
+	Sink   string     `json:"sink"`
+	SentAt time.Time  `json:"sent_at"`
+	Items  []SinkItem `json:"items"`
+}
+
+// WebhookSink is a sink which POSTs summarized items as JSON to a url
+type WebhookSink struct {
+	name    string
+	url     string
+	secret  []byte
+	headers map[string]string
+
+	httpClient *http.Client // nil for the http client of `Client` delivering items
+}
+
+// NewWebhookSink returns a new webhook sink which POSTs `WebhookPayload`s to `url`.
+//
+// If `secret` is not empty, request bodies are signed with it in the `WebhookSignatureHeader` header.
+func NewWebhookSink(name, url, secret string) *WebhookSink {
+	return &WebhookSink{
+		name:   name,
+		url:    url,
+		secret: []byte(secret),
+	}
+}
+
+// SetHeaders sets additional headers of webhook requests. (eg. for authorization)
+func (s *WebhookSink) SetHeaders(headers map[string]string) {
+	s.headers = headers
+}
+
+// SetHTTPClient sets the http client of webhook requests. (default: the http client of `Client`)
+func (s *WebhookSink) SetHTTPClient(client *http.Client) {
+	s.httpClient = client
+}
+
+// Name returns the name of the sink.
+func (s *WebhookSink) Name() string {
+	return s.name
+}
+
+// Deliver POSTs given items to the webhook url, all in one request.
+func (s *WebhookSink) Deliver(ctx context.Context, items []CachedItem) BatchResults {
+	return batchResults(items, s.post(ctx, items))
+}
+
+// POST given items in a webhook payload
+func (s *WebhookSink) post(ctx context.Context, items []CachedItem) error {
+	payload := WebhookPayload{
+		Sink:   s.name,
+		SentAt: time.Now(),
+	}
+	for _, item := range items {
+		payload.Items = append(payload.Items, NewSinkItem(item))
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range s.headers {
+		req.Header.Set(key, value)
+	}
+	if len(s.secret) > 0 {
+		req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(s.secret, body))
+	}
+
+	resp, err := sinkHTTPClient(ctx, s.httpClient).Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookErrorBodySize))
+		return fmt.Errorf("webhook request failed with http status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return nil
+}
+
+// SignWebhookPayload returns the signature of given webhook request `body` with `secret`. (eg. "sha256=0123abcd...")
+func SignWebhookPayload(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return webhookSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature checks if `signature` (from the `WebhookSignatureHeader` header) is valid for `body` and `secret`.
+func VerifyWebhookSignature(secret, body []byte, signature string) bool {
+	return hmac.Equal([]byte(SignWebhookPayload(secret, body)), []byte(signature))
+}
@@ -0,0 +1,341 @@
+package rf
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+////////////////
+//
+// (export of cached items as a static HTML site)
+//
+
+const (
+	defaultSiteItemsPerPage = 20
+
+	siteFeedFilename = "feed.xml"
+)
+
+// embedded default templates of static sites
+//
+//go:embed templates/site/*.html
+var siteTemplates embed.FS
+
+// names of the templates of static sites
+const (
+	siteTemplateLayout = "layout.html" // defines "layout", which renders "content"
+	siteTemplateList   = "list.html"   // defines "content" of index, tag, and feed pages
+	siteTemplateItem   = "item.html"   // defines "content" of item pages
+)
+
+// SiteOptions is a struct for the options of `ExportSite`
+type SiteOptions struct {
+	Title        string
+	Description  string
+	Link         string // link of the site, used in its RSS feed (optional)
+	ItemsPerPage int    // (default: 20)
+
+	Templates fs.FS // templates which override the embedded ones with the same names (optional, eg. `os.DirFS("./templates")`)
+}
+
+// SiteInfo is a struct for the information of a static site, given to templates
+type SiteInfo struct {
+	Title       string
+	Description string
+	FeedPath    string // path to the RSS feed
+	GeneratedAt time.Time
+}
+
+// SiteLink is a struct for a link to a tag or feed page, given to templates
+type SiteLink struct {
+	Name  string
+	Path  string // path from the root of the site
+	Count int    // number of items
+}
+
+// SiteItem is a struct for a cached item, given to templates
+type SiteItem struct {
+	Path        string // path from the root of the site
+	Title       string
+	Link        string
+	Comments    string
+	Author      string
+	PublishDate string
+	Summary     template.HTML // summary decorated as HTML
+
+	Feed *SiteLink
+	Tags []SiteLink
+}
+
+// SitePagination is a struct for the pagination of a list page, given to templates
+type SitePagination struct {
+	Page  int
+	Pages int
+	Prev  string // path to the previous (newer) page (empty if none)
+	Next  string // path to the next (older) page (empty if none)
+}
+
+// SitePage is a struct for a page of a static site, given to templates
+type SitePage struct {
+	Site  SiteInfo
+	Root  string // relative path to the root of the site (eg. "../")
+	Title string
+
+	Items      []SiteItem // (list pages)
+	Pagination SitePagination
+	Item       *SiteItem // (item pages)
+
+	Feeds []SiteLink
+	Tags  []SiteLink
+}
+
+// ExportSite renders given cached items into a static HTML site in `dir`, with:
+// paginated index pages, item pages, tag and feed pages, and an RSS feed.
+//
+// Items without successful summaries are omitted. Existing files in `dir` are overwritten, but not removed.
+func (c *Client) ExportSite(ctx context.Context, dir string, items []CachedItem, opts SiteOptions) error {
+	listTmpl, err := parseSiteTemplates(opts.Templates, siteTemplateList)
+	if err != nil {
+		return err
+	}
+	itemTmpl, err := parseSiteTemplates(opts.Templates, siteTemplateItem)
+	if err != nil {
+		return err
+	}
+
+	// successful summaries only, newest first
+	items = slices.DeleteFunc(slices.Clone(items), func(item CachedItem) bool {
+		return len(item.Summary) <= 0 || isError(item.Summary)
+	})
+	slices.SortStableFunc(items, func(a, b CachedItem) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+
+	// titles of feeds
+	feedTitles := map[string]string{}
+	if feeds, err := c.cache.ListFeeds(ctx); err == nil {
+		for _, feed := range feeds {
+			feedTitles[feed.URL] = feed.Title
+		}
+	}
+
+	// links to tags and feeds
+	tags, feeds := map[string]*SiteLink{}, map[string]*SiteLink{}
+	tagItems, feedItems := map[string][]SiteItem{}, map[string][]SiteItem{}
+	var siteItems []SiteItem
+	for _, item := range items {
+		siteItem := SiteItem{
+			Path:        path.Join("items", siteItemFilename(item.GUID)),
+			Title:       item.Title,
+			Link:        item.Link,
+			Comments:    item.Comments,
+			Author:      item.Author,
+			PublishDate: item.PublishDate,
+			Summary:     template.HTML(decorateHTML(item.Summary)),
+		}
+		if len(item.FeedURL) > 0 {
+			feed, exists := feeds[item.FeedURL]
+			if !exists {
+				feed = &SiteLink{
+					Name: cmp.Or(feedTitles[item.FeedURL], item.FeedURL),
+					Path: path.Join("feeds", stableName("feed-", item.FeedURL)+".html"),
+				}
+				feeds[item.FeedURL] = feed
+			}
+			feed.Count++
+			siteItem.Feed = feed
+		}
+		for _, category := range distinctCategories(item.Categories) {
+			tag, exists := tags[category]
+			if !exists {
+				tag = &SiteLink{
+					Name: category,
+					Path: path.Join("tags", stableName("tag-", category)+".html"),
+				}
+				tags[category] = tag
+			}
+			tag.Count++
+		}
+		siteItems = append(siteItems, siteItem)
+	}
+	for i, item := range items {
+		categories := distinctCategories(item.Categories)
+		for _, category := range categories {
+			siteItems[i].Tags = append(siteItems[i].Tags, *tags[category])
+		}
+		for _, category := range categories {
+			tagItems[category] = append(tagItems[category], siteItems[i])
+		}
+		if len(item.FeedURL) > 0 {
+			feedItems[item.FeedURL] = append(feedItems[item.FeedURL], siteItems[i])
+		}
+	}
+	sortedLinks := func(links map[string]*SiteLink) (sorted []SiteLink) {
+		for _, link := range links {
+			sorted = append(sorted, *link)
+		}
+		slices.SortFunc(sorted, func(a, b SiteLink) int {
+			return cmp.Or(cmp.Compare(b.Count, a.Count), strings.Compare(a.Name, b.Name))
+		})
+		return sorted
+	}
+
+	base := SitePage{
+		Site: SiteInfo{
+			Title:       opts.Title,
+			Description: opts.Description,
+			FeedPath:    siteFeedFilename,
+			GeneratedAt: time.Now(),
+		},
+		Feeds: sortedLinks(feeds),
+		Tags:  sortedLinks(tags),
+	}
+
+	for _, subdir := range []string{"", "items", "tags", "feeds"} {
+		if err := os.MkdirAll(filepath.Join(dir, subdir), 0o755); err != nil {
+			return fmt.Errorf("failed to create directory for site: %w", err)
+		}
+	}
+
+	// index pages
+	perPage := cmp.Or(opts.ItemsPerPage, defaultSiteItemsPerPage)
+	if err := writeSiteListPages(dir, listTmpl, base, "", "index", siteItems, perPage); err != nil {
+		return err
+	}
+
+	// tag and feed pages
+	for category, tag := range tags {
+		if err := writeSiteListPages(dir, listTmpl, base, "#"+category, strings.TrimSuffix(tag.Path, ".html"), tagItems[category], perPage); err != nil {
+			return err
+		}
+	}
+	for feedURL, feed := range feeds {
+		if err := writeSiteListPages(dir, listTmpl, base, feed.Name, strings.TrimSuffix(feed.Path, ".html"), feedItems[feedURL], perPage); err != nil {
+			return err
+		}
+	}
+
+	// item pages
+	for _, item := range siteItems {
+		page := base
+		page.Root = "../"
+		page.Title = item.Title
+		page.Item = &item
+		if err := writeSitePage(dir, item.Path, itemTmpl, page); err != nil {
+			return err
+		}
+	}
+
+	// rss feed
+	feed, _, err := c.PublishWithOptions(items, PublishOptions{
+		Format:             PublishFormatRSS,
+		Title:              opts.Title,
+		Link:               opts.Link,
+		Description:        opts.Description,
+		Limit:              perPage,
+		IncludeDescription: true,
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, siteFeedFilename), feed, 0o644); err != nil {
+		return fmt.Errorf("failed to write feed of site: %w", err)
+	}
+
+	return nil
+}
+
+// parse the layout and `name` templates, preferring the ones in `overrides`
+func parseSiteTemplates(overrides fs.FS, name string) (*template.Template, error) {
+	tmpl := template.New(name)
+	for _, filename := range []string{siteTemplateLayout, name} {
+		var src []byte
+		var err error
+		if overrides != nil {
+			src, err = fs.ReadFile(overrides, filename)
+		}
+		if overrides == nil || errors.Is(err, fs.ErrNotExist) {
+			src, err = siteTemplates.ReadFile(path.Join("templates/site", filename))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template '%s': %w", filename, err)
+		}
+		if tmpl, err = tmpl.Parse(string(src)); err != nil {
+			return nil, fmt.Errorf("failed to parse template '%s': %w", filename, err)
+		}
+	}
+	return tmpl, nil
+}
+
+// write paginated list pages of `items` (eg. "index.html", "index-2.html", ...)
+func writeSiteListPages(dir string, tmpl *template.Template, base SitePage, title, prefix string, items []SiteItem, perPage int) error {
+	pages := max((len(items)+perPage-1)/perPage, 1)
+	pagePath := func(page int) string {
+		if page <= 1 {
+			return prefix + ".html"
+		}
+		return fmt.Sprintf("%s-%d.html", prefix, page)
+	}
+
+	for page := 1; page <= pages; page++ {
+		sitePage := base
+		sitePage.Title = title
+		if strings.Contains(prefix, "/") {
+			sitePage.Root = "../"
+		}
+		sitePage.Items = items[(page-1)*perPage : min(page*perPage, len(items))]
+		sitePage.Pagination = SitePagination{Page: page, Pages: pages}
+		if page > 1 {
+			sitePage.Pagination.Prev = pagePath(page - 1)
+		}
+		if page < pages {
+			sitePage.Pagination.Next = pagePath(page + 1)
+		}
+
+		if err := writeSitePage(dir, pagePath(page), tmpl, sitePage); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// render `page` with `tmpl` into the file at `name` in `dir`
+func writeSitePage(dir, name string, tmpl *template.Template, page SitePage) error {
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "layout", page); err != nil {
+		return fmt.Errorf("failed to render page '%s': %w", name, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write page '%s': %w", name, err)
+	}
+	return nil
+}
+
+// filename of the page of the item with given guid
+func siteItemFilename(guid string) string {
+	return guidHash(guid) + ".html"
+}
+
+// distinct ones of `categories`, in the order of their first appearances
+func distinctCategories(categories []string) (distinct []string) {
+	seen := map[string]bool{}
+	for _, category := range categories {
+		if !seen[category] {
+			seen[category] = true
+			distinct = append(distinct, category)
+		}
+	}
+	return distinct
+}
@@ -0,0 +1,127 @@
+package rf
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// test exporting cached items as a static site
+func TestExportSite(t *testing.T) {
+	ctx := context.Background()
+	client := NewClient([]string{"key"}, nil)
+
+	now := time.Now()
+	var items []CachedItem
+	for i := range 3 {
+		item := CachedItem{
+			Title:      fmt.Sprintf("Item %d", i),
+			Link:       fmt.Sprintf("https://example.com/item%d", i),
+			Comments:   fmt.Sprintf("https://example.com/item%d/comments", i),
+			GUID:       fmt.Sprintf("guid-site-%d", i),
+			Summary:    fmt.Sprintf("Summary of **item %d**", i),
+			FeedURL:    "https://example.com/feed.xml",
+			Categories: []string{"Go"},
+		}
+		item.CreatedAt = now.Add(-time.Duration(i) * time.Hour)
+		items = append(items, item)
+	}
+	items[0].Categories = append(items[0].Categories, "news", "Go") // (with a duplicate which is not adjacent)
+	items = append(items,
+		CachedItem{Title: "Failed", GUID: "guid-site-failed", Summary: failedSummary("", fmt.Errorf("test error"))},
+		CachedItem{Title: "Unsummarized", GUID: "guid-site-unsummarized"},
+	)
+
+	dir := t.TempDir()
+	if err := client.ExportSite(ctx, dir, items, SiteOptions{Title: "My Site", Link: "https://example.com", ItemsPerPage: 2}); err != nil {
+		t.Fatalf("failed to export site: %s", err)
+	}
+	read := func(name string) string {
+		bytes, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatalf("failed to read '%s': %s", name, err)
+		}
+		return string(bytes)
+	}
+
+	// paginated index pages, newest first
+	index := read("index.html")
+	for _, expected := range []string{
+		`<link rel="alternate" type="application/rss+xml" title="My Site" href="feed.xml">`,
+		`<a href="items/` + siteItemFilename("guid-site-0") + `">Item 0</a>`,
+		`<a href="index-2.html">Older &raquo;</a>`,
+		`<a href="tags/tag-go-6cc8519b.html">Go</a> (3)`,
+		`<a href="feeds/feed-https-example-com-feed-xml-7a775db7.html">https://example.com/feed.xml</a> (3)`,
+	} {
+		if !strings.Contains(index, expected) {
+			t.Errorf("expected '%s' in index page, got %s", expected, index)
+		}
+	}
+	for _, unexpected := range []string{"Item 2", "Failed", "Unsummarized"} {
+		if strings.Contains(index, unexpected) {
+			t.Errorf("expected no '%s' in index page", unexpected)
+		}
+	}
+	if page := read("index-2.html"); !strings.Contains(page, "Item 2") || !strings.Contains(page, `<a href="index.html">&laquo; Newer</a>`) {
+		t.Errorf("unexpected second index page: %s", page)
+	}
+
+	// item pages
+	page := read("items/" + siteItemFilename("guid-site-0"))
+	for _, expected := range []string{
+		`href="../feed.xml"`,
+		"Summary of <strong>item 0</strong>",
+		`<a href="https://example.com/item0">Original</a>`,
+		`<a href="https://example.com/item0/comments">Comments</a>`,
+		`<a href="../tags/tag-news.html">#news</a>`,
+	} {
+		if !strings.Contains(page, expected) {
+			t.Errorf("expected '%s' in item page, got %s", expected, page)
+		}
+	}
+
+	if strings.Count(page, `<a href="../tags/tag-go-6cc8519b.html">#Go</a>`) != 1 {
+		t.Errorf("expected duplicated tags to be shown once in item page, got %s", page)
+	}
+
+	// tag and feed pages
+	if page := read("tags/tag-news.html"); !strings.Contains(page, "Item 0") || strings.Contains(page, "Item 1") {
+		t.Errorf("unexpected tag page: %s", page)
+	}
+	if page := read("feeds/feed-https-example-com-feed-xml-7a775db7-2.html"); !strings.Contains(page, "Item 2") || !strings.Contains(page, `<a href="../feeds/feed-https-example-com-feed-xml-7a775db7.html">&laquo; Newer</a>`) {
+		t.Errorf("unexpected feed page: %s", page)
+	}
+
+	// rss feed
+	parsed, err := gofeed.NewParser().ParseString(read("feed.xml"))
+	if err != nil || parsed.Title != "My Site" || len(parsed.Items) != 2 {
+		t.Errorf("unexpected feed of site: %+v (%v)", parsed, err)
+	}
+
+	// overridden template
+	if err := client.ExportSite(ctx, dir, items, SiteOptions{
+		Title: "My Site",
+		Templates: fstest.MapFS{
+			"item.html": {Data: []byte(`{{define "content"}}<p class="custom">{{.Item.Title}}</p>{{end}}`)},
+		},
+	}); err != nil {
+		t.Fatalf("failed to export site with custom templates: %s", err)
+	}
+	if page := read("items/" + siteItemFilename("guid-site-1")); !strings.Contains(page, `<p class="custom">Item 1</p>`) || !strings.Contains(page, "<title>Item 1 - My Site</title>") {
+		t.Errorf("unexpected item page with custom template: %s", page)
+	}
+
+	// invalid template
+	if err := client.ExportSite(ctx, dir, items, SiteOptions{
+		Templates: fstest.MapFS{"list.html": {Data: []byte(`{{define "content"}}`)}},
+	}); err == nil {
+		t.Errorf("expected error for invalid template")
+	}
+}
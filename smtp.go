@@ -0,0 +1,117 @@
+package rf
+
+import (
+	"cmp"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultSMTPTimeout = 30 * time.Second
+)
+
+// SMTPSecurity is a type for the security of SMTP connections
+type SMTPSecurity string
+
+// SMTPSecurity constants
+const (
+	SMTPSecurityStartTLS SMTPSecurity = "starttls" // upgraded with STARTTLS (usually on port 587)
+	SMTPSecurityTLS      SMTPSecurity = "tls"      // implicit TLS (usually on port 465)
+	SMTPSecurityNone     SMTPSecurity = "none"     // plain text (for local servers only)
+)
+
+// SMTPServer is a struct for the configuration of an SMTP server
+type SMTPServer struct {
+	Host     string
+	Port     int
+	Security SMTPSecurity // (default: starttls)
+
+	Username string // for PLAIN auth (optional)
+	Password string
+
+	TLSConfig *tls.Config   // (optional, eg. for custom root CAs)
+	Timeout   time.Duration // timeout of each delivery (default: 30 seconds)
+}
+
+// send `msg` from `from` to `to` through the SMTP server
+func (s SMTPServer) send(ctx context.Context, from string, to []string, msg []byte) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, cmp.Or(s.Timeout, defaultSMTPTimeout))
+	defer cancel()
+
+	tlsConfig := s.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	} else {
+		tlsConfig = tlsConfig.Clone()
+	}
+	if len(tlsConfig.ServerName) <= 0 {
+		tlsConfig.ServerName = s.Host
+	}
+
+	// connect,
+	addr := net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+	var conn net.Conn
+	if s.Security == SMTPSecurityTLS {
+		conn, err = (&tls.Dialer{Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to smtp server '%s': %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("failed to start smtp session with '%s': %w", addr, err)
+	}
+	defer func() { _ = client.Close() }()
+
+	// upgrade to tls, (if needed)
+	if cmp.Or(s.Security, SMTPSecurityStartTLS) == SMTPSecurityStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("smtp server '%s' does not support STARTTLS", addr)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("failed to start tls with '%s': %w", addr, err)
+		}
+	}
+
+	// authenticate,
+	if len(s.Username) > 0 {
+		if err := client.Auth(smtp.PlainAuth("", s.Username, s.Password, s.Host)); err != nil {
+			return fmt.Errorf("failed to authenticate with '%s': %w", addr, err)
+		}
+	}
+
+	// and send
+	if err := client.Mail(from); err != nil {
+		return fmt.Errorf("failed to set sender '%s': %w", from, err)
+	}
+	for _, rcpt := range to {
+		if err := client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("failed to set recipient '%s': %w", rcpt, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start sending message: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		_ = w.Close()
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return client.Quit()
+}
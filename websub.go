@@ -0,0 +1,502 @@
+package rf
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+////////////////
+//
+// (websub: subscribing to the hubs of feeds, and pinging the hub of published feeds)
+//
+
+const (
+	defaultWebSubLeaseSeconds                   = 7 * 24 * 60 * 60 // 7 days
+	defaultWebSubIgnoreItemsPublishedBeforeDays = 7
+
+	webSubRenewBefore   = 24 * time.Hour // subscriptions are renewed when they expire in this duration (or half of the requested lease)
+	webSubRetryInterval = time.Hour      // pending (or denied) subscriptions are requested again after this duration
+
+	webSubSecretLength = 32 // in bytes
+
+	webSubDiscoveryBytes = 64 * 1024 // hubs are discovered in this many bytes at the head of raw feeds
+	webSubPushQueueSize  = 100       // pushes are rejected (and retried by hubs) while this many ones are waiting
+
+	maxWebSubErrorBodySize = 1024 // response bodies of failed hub requests are truncated to this size (in bytes) in errors
+)
+
+// WebSubSignatureHeader is the header of signatures of pushed contents.
+const WebSubSignatureHeader = "X-Hub-Signature"
+
+// WebSubOptions is a struct for the options of websub subscriptions
+type WebSubOptions struct {
+	CallbackURL string // public url where `WebSubHandler` is served (eg. "https://example.com/websub")
+
+	LeaseSeconds                   int  // requested lease of subscriptions (default: 7 days)
+	IgnoreItemsPublishedBeforeDays uint // pushed items published before this many days are ignored (default: 7)
+
+	// called with the feed of newly pushed items (default: summarize and cache them with `SummarizeAndCacheFeeds`)
+	//
+	// NOTE: pushes are queued and passed one by one, in a goroutine.
+	OnPush func(ctx context.Context, feed gofeed.Feed)
+}
+
+// SetWebSub enables subscribing to the websub hubs of feeds with `opts`.
+//
+// Feeds which advertise their hubs are subscribed to while fetched with `FetchFeeds`,
+// and their pushed items are received with `WebSubHandler`, which should be served at `opts.CallbackURL`.
+// (feeds are still fetched with `FetchFeeds` as before, for the ones which are not pushed)
+func (c *Client) SetWebSub(opts WebSubOptions) error {
+	callback, err := url.Parse(opts.CallbackURL)
+	if err != nil || (callback.Scheme != "http" && callback.Scheme != "https") || len(callback.Host) <= 0 {
+		return fmt.Errorf("not a valid callback url of websub: '%s'", opts.CallbackURL)
+	}
+
+	opts.LeaseSeconds = cmp.Or(opts.LeaseSeconds, defaultWebSubLeaseSeconds)
+	opts.IgnoreItemsPublishedBeforeDays = cmp.Or(opts.IgnoreItemsPublishedBeforeDays, defaultWebSubIgnoreItemsPublishedBeforeDays)
+	if opts.OnPush == nil {
+		opts.OnPush = func(ctx context.Context, feed gofeed.Feed) {
+			if err := c.SummarizeAndCacheFeeds(ctx, []gofeed.Feed{feed}); err != nil {
+				log.Printf("failed to summarize pushed items of '%s': %s", feedURLOf(feed), redactText(err.Error(), c.googleAIAPIKeys))
+			}
+		}
+	}
+
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	c.webSub = &opts
+	if c.webSubPushes == nil {
+		c.webSubPushes = make(chan gofeed.Feed, webSubPushQueueSize)
+		go c.processWebSubPushes(c.webSubPushes)
+	}
+	return nil
+}
+
+// return the options of websub subscriptions (nil if not subscribing)
+//
+// NOTE: returned options are not modified, as `SetWebSub` replaces them.
+func (c *Client) getWebSub() *WebSubOptions {
+	c.stateMu.RLock()
+	defer c.stateMu.RUnlock()
+
+	return c.webSub
+}
+
+// pass the feeds of queued pushes to `WebSubOptions.OnPush` (of the latest options) one by one
+func (c *Client) processWebSubPushes(pushes <-chan gofeed.Feed) {
+	for feed := range pushes {
+		c.getWebSub().OnPush(context.Background(), feed)
+	}
+}
+
+// callback url of the websub subscription for the feed at `feedURL`
+func webSubCallbackURL(opts *WebSubOptions, feedURL string) string {
+	return strings.TrimSuffix(opts.CallbackURL, "/") + "/" + guidHash(feedURL)
+}
+
+// fill the websub hub and topic of feed, resetting its subscription if they changed
+func (f *Feed) fillWebSub(hub, topic string) {
+	if f.HubURL != hub || f.TopicURL != topic {
+		f.WebSubRequestedAt, f.WebSubLeaseUntil = time.Time{}, time.Time{}
+	}
+	f.HubURL, f.TopicURL = hub, topic
+}
+
+// headWriter is a writer which keeps only the first `limit` bytes written to it
+type headWriter struct {
+	buf   []byte
+	limit int
+}
+
+// Write keeps `p` up to the limit, and always succeeds.
+func (w *headWriter) Write(p []byte) (int, error) {
+	if n := min(len(p), w.limit-len(w.buf)); n > 0 {
+		w.buf = append(w.buf, p[:n]...)
+	}
+	return len(p), nil
+}
+
+var _linkHeader = regexp.MustCompile(`<([^>]*)>([^<]*)`)
+var _linkHeaderRel = regexp.MustCompile(`(?i)\brel\s*=\s*"?([^";,]+)"?`)
+
+// discover the websub hub and topic of the `fetched` feed from `feedURL`,
+// with `Link` headers of the response, or `<link rel="hub">` elements in the head of its raw `data`
+func discoverWebSub(data []byte, header http.Header, fetched *gofeed.Feed, feedURL string) (hub, topic string) {
+	var self string
+	for _, value := range header.Values("Link") {
+		for _, match := range _linkHeader.FindAllStringSubmatch(value, -1) {
+			for _, rel := range _linkHeaderRel.FindAllStringSubmatch(match[2], -1) {
+				rels := strings.Fields(strings.ToLower(rel[1]))
+				if slices.Contains(rels, "hub") && len(hub) <= 0 {
+					hub = match[1]
+				}
+				if slices.Contains(rels, "self") && len(self) <= 0 {
+					self = match[1]
+				}
+			}
+		}
+	}
+	if len(hub) <= 0 {
+		hub = hubLinkInFeed(data)
+	}
+	if len(hub) <= 0 {
+		return "", ""
+	}
+
+	// (relative to the feed's url)
+	if base, err := url.Parse(feedURL); err == nil {
+		if resolved, err := base.Parse(hub); err == nil {
+			hub = resolved.String()
+		}
+	}
+	if fetched != nil {
+		self = cmp.Or(self, fetched.FeedLink)
+	}
+
+	return hub, cmp.Or(self, feedURL)
+}
+
+// find the href of the first `<link rel="hub">` (or `<atom:link rel="hub">`) element before items in given raw feed
+func hubLinkInFeed(data []byte) string {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil // (already transcoded to UTF-8)
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return ""
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "item", "entry":
+			return ""
+		case "link":
+			var rel, href string
+			for _, attr := range start.Attr {
+				switch attr.Name.Local {
+				case "rel":
+					rel = attr.Value
+				case "href":
+					href = attr.Value
+				}
+			}
+			if slices.Contains(strings.Fields(strings.ToLower(rel)), "hub") && len(href) > 0 {
+				return strings.TrimSpace(href)
+			}
+		}
+	}
+}
+
+// subscribe to the websub hub of the feed at `feedURL`, if it is not subscribed yet or expiring
+//
+// NOTE: failures are only logged, for not failing the fetch itself.
+func (c *Client) subscribeWebSub(ctx context.Context, feedURL string) {
+	opts := c.getWebSub()
+	if opts == nil {
+		return
+	}
+
+	feed, err := c.cache.FetchFeed(ctx, feedURL)
+	if err != nil {
+		log.Printf("failed to fetch feed '%s' for websub: %s", feedURL, err)
+		return
+	}
+	now := time.Now()
+	renewBefore := min(webSubRenewBefore, time.Duration(opts.LeaseSeconds)*time.Second/2)
+	if len(feed.HubURL) <= 0 ||
+		feed.WebSubLeaseUntil.Sub(now) > renewBefore ||
+		(!feed.WebSubRequestedAt.IsZero() && now.Sub(feed.WebSubRequestedAt) < webSubRetryInterval) {
+		return
+	}
+
+	if len(feed.WebSubSecret) <= 0 {
+		secret := make([]byte, webSubSecretLength)
+		_, _ = rand.Read(secret)
+		feed.WebSubSecret = hex.EncodeToString(secret)
+	}
+	feed.WebSubRequestedAt = now
+
+	// NOTE: saved before the request, as hubs may verify it before responding
+	if err := c.cache.SaveFeed(ctx, *feed); err != nil {
+		log.Printf("failed to save feed '%s' for websub: %s", feedURL, err)
+		return
+	}
+
+	v(c.verbose, "subscribing to websub hub '%s' for topic: %s", feed.HubURL, feed.TopicURL)
+
+	if err := c.requestWebSubHub(ctx, c.getHTTPClient(), feed.HubURL, url.Values{
+		"hub.mode":          {"subscribe"},
+		"hub.topic":         {feed.TopicURL},
+		"hub.callback":      {webSubCallbackURL(opts, feedURL)},
+		"hub.secret":        {feed.WebSubSecret},
+		"hub.lease_seconds": {strconv.Itoa(opts.LeaseSeconds)},
+	}); err != nil {
+		log.Printf("failed to subscribe to websub hub '%s' for feed '%s': %s", feed.HubURL, feedURL, err)
+	}
+}
+
+// send a form request to the websub hub at `hubURL`
+func (c *Client) requestWebSubHub(ctx context.Context, client *http.Client, hubURL string, form url.Values) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hubURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", c.getUserAgent())
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebSubErrorBodySize))
+		return fmt.Errorf("request failed with http status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return nil
+}
+
+// WebSubHandler returns the http handler of websub callbacks, which should be served at `WebSubOptions.CallbackURL` and its sub paths.
+//
+// It verifies the intents of subscriptions requested by this client (GET),
+// and receives pushed contents (POST) whose signatures are valid for the secrets of subscriptions.
+func (c *Client) WebSubHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		opts := c.getWebSub()
+		if opts == nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		feed, err := c.webSubFeed(r.Context(), path.Base(r.URL.Path))
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				http.NotFound(w, r)
+			} else {
+				log.Printf("failed to find feed of websub callback '%s': %s", r.URL.Path, err)
+				http.Error(w, "cache unavailable", http.StatusServiceUnavailable)
+			}
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			c.verifyWebSub(w, r, opts, feed)
+		case http.MethodPost:
+			c.receiveWebSub(w, r, opts, feed)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// find the feed with given `id` of websub callbacks
+func (c *Client) webSubFeed(ctx context.Context, id string) (*Feed, error) {
+	feeds, err := c.cache.ListFeeds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, feed := range feeds {
+		if len(feed.HubURL) > 0 && guidHash(feed.URL) == id {
+			return &feed, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: feed of websub callback '%s'", ErrNotFound, id)
+}
+
+// verify the intent of a subscription (or its denial)
+func (c *Client) verifyWebSub(w http.ResponseWriter, r *http.Request, opts *WebSubOptions, feed *Feed) {
+	query := r.URL.Query()
+
+	switch query.Get("hub.mode") {
+	case "subscribe":
+		challenge := query.Get("hub.challenge")
+		if feed.WebSubRequestedAt.IsZero() || query.Get("hub.topic") != feed.TopicURL || len(challenge) <= 0 {
+			http.NotFound(w, r)
+			return
+		}
+
+		lease, _ := strconv.Atoi(query.Get("hub.lease_seconds"))
+		if lease <= 0 {
+			lease = opts.LeaseSeconds
+		}
+		feed.WebSubRequestedAt = time.Time{}
+		feed.WebSubLeaseUntil = time.Now().Add(time.Duration(lease) * time.Second)
+		if err := c.cache.SaveFeed(r.Context(), *feed); err != nil {
+			log.Printf("failed to save websub subscription of feed '%s': %s", feed.URL, err)
+			http.Error(w, "cache unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		v(c.verbose, "subscribed to websub hub '%s' for feed '%s' until %s", feed.HubURL, feed.URL, feed.WebSubLeaseUntil.Format(time.RFC3339))
+
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = io.WriteString(w, challenge)
+	case "denied":
+		// NOTE: denials are accepted only for pending requests
+		if feed.WebSubRequestedAt.IsZero() || query.Get("hub.topic") != feed.TopicURL {
+			http.NotFound(w, r)
+			return
+		}
+
+		log.Printf("websub subscription of feed '%s' was denied by '%s': %s", feed.URL, feed.HubURL, query.Get("hub.reason"))
+
+		// NOTE: `WebSubRequestedAt` is kept, for requesting again after `webSubRetryInterval`
+		feed.WebSubLeaseUntil = time.Time{}
+		if err := c.cache.SaveFeed(r.Context(), *feed); err != nil {
+			log.Printf("failed to save websub subscription of feed '%s': %s", feed.URL, err)
+		}
+		w.WriteHeader(http.StatusOK)
+	default: // (unsubscriptions are never requested by this client)
+		http.NotFound(w, r)
+	}
+}
+
+// receive pushed contents of the feed, and pass its new items to `WebSubOptions.OnPush`
+func (c *Client) receiveWebSub(w http.ResponseWriter, r *http.Request, opts *WebSubOptions, feed *Feed) {
+	body, err := io.ReadAll(newLimitedReader(r.Body, c.contentSizeLimits.feed()))
+	if err != nil {
+		if errors.Is(err, ErrContentTooLarge) {
+			http.Error(w, "content too large", http.StatusRequestEntityTooLarge)
+		} else {
+			http.Error(w, "failed to read content", http.StatusBadRequest)
+		}
+		return
+	}
+
+	// NOTE: contents with invalid signatures are acknowledged but ignored (as the spec says)
+	if !verifyWebSubSignature(feed.WebSubSecret, body, r.Header.Get(WebSubSignatureHeader)) {
+		log.Printf("ignoring pushed content of feed '%s' with an invalid signature", feed.URL)
+
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	reader, err := feedReader(bytes.NewReader(body), r.Header.Get("Content-Type"))
+	if err != nil {
+		http.Error(w, "failed to read content", http.StatusBadRequest)
+		return
+	}
+	pushed, err := gofeed.NewParser().Parse(reader)
+	if err != nil {
+		http.Error(w, "failed to parse content", http.StatusBadRequest)
+		return
+	}
+	if pushed.Custom == nil {
+		pushed.Custom = map[string]string{}
+	}
+	pushed.Custom[customKeyFeedURL] = feed.URL
+
+	if err := c.filterFetchedItems(r.Context(), feed.URL, pushed, true, opts.IgnoreItemsPublishedBeforeDays); err != nil {
+		log.Printf("failed to filter pushed items of feed '%s': %s", feed.URL, err)
+		http.Error(w, "cache unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	v(c.verbose, "received %d new item(s) pushed for feed: %s", len(pushed.Items), feed.URL)
+
+	if len(pushed.Items) > 0 {
+		select {
+		case c.webSubPushes <- *pushed:
+		default:
+			log.Printf("rejecting pushed content of feed '%s', as too many pushes are waiting", feed.URL)
+
+			http.Error(w, "too many pushes", http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// verify `signature` (eg. "sha256=0123abcd...") of pushed `body` with `secret`
+func verifyWebSubSignature(secret string, body []byte, signature string) bool {
+	method, signed, ok := strings.Cut(signature, "=")
+	if !ok || len(secret) <= 0 {
+		return false
+	}
+
+	var hashFn func() hash.Hash
+	switch strings.ToLower(method) {
+	case "sha1":
+		hashFn = sha1.New
+	case "sha256":
+		hashFn = sha256.New
+	case "sha384":
+		hashFn = sha512.New384
+	case "sha512":
+		hashFn = sha512.New
+	default:
+		return false
+	}
+	expected, err := hex.DecodeString(signed)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(hashFn, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// SetWebSubHub sets the websub hub which is pinged with `topicURLs` (urls of the published feeds)
+// when there are new summaries. (see `PublishOptions.HubURL` for advertising it in the published feeds)
+func (c *Client) SetWebSubHub(hubURL string, topicURLs []string) {
+	c.webSubHubURL = hubURL
+	c.webSubTopicURLs = slices.Clone(topicURLs)
+}
+
+// PingWebSubHub notifies the websub hub set with `SetWebSubHub` that its topics (published feeds) were updated.
+func (c *Client) PingWebSubHub(ctx context.Context) error {
+	if len(c.webSubHubURL) <= 0 {
+		return fmt.Errorf("websub hub is not set")
+	}
+
+	var errs []error
+	for _, topic := range c.webSubTopicURLs {
+		v(c.verbose, "pinging websub hub '%s' for topic: %s", c.webSubHubURL, topic)
+
+		if err := c.requestWebSubHub(ctx, c.baseHTTPClient(), c.webSubHubURL, url.Values{
+			"hub.mode": {"publish"},
+			"hub.url":  {topic},
+		}); err != nil {
+			errs = append(errs, fmt.Errorf("failed to ping websub hub '%s' for topic '%s': %w", c.webSubHubURL, topic, err))
+		}
+	}
+	return errors.Join(errs...)
+}
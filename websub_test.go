@@ -0,0 +1,397 @@
+package rf
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// rss feed with given hub link and guids of items for testing
+func testWebSubFeed(hub string, guids ...string) string {
+	var items strings.Builder
+	for _, guid := range guids {
+		fmt.Fprintf(&items, `
+    <item>
+      <title>Article %[1]s</title>
+      <link>https://example.com/%[1]s</link>
+      <guid>%[1]s</guid>
+      <pubDate>%[2]s</pubDate>
+    </item>`, guid, time.Now().Format(time.RFC1123Z))
+	}
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">
+  <channel>
+    <title>Pushed Feed</title>
+    <link>https://example.com</link>
+    <atom:link href="` + hub + `" rel="hub"/>` + items.String() + `
+  </channel>
+</rss>`
+}
+
+// sign given body for pushing
+func signWebSub(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// test discovering websub hubs and topics of feeds
+func TestDiscoverWebSub(t *testing.T) {
+	atomFeed := `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Atom Feed</title>
+  <link href="https://example.com/"/>
+  <link href="https://example.com/atom.xml" rel="self"/>
+  <link href="/hub" rel="hub"/>
+  <entry><title>Entry</title><link href="https://example.com/hub-in-entry" rel="hub"/></entry>
+</feed>`
+
+	for name, tc := range map[string]struct {
+		data          string
+		header        http.Header
+		feedURL       string
+		expectedHub   string
+		expectedTopic string
+	}{
+		"rss": {
+			data:          testWebSubFeed("https://hub.example.com/", "guid"),
+			feedURL:       "https://example.com/rss.xml",
+			expectedHub:   "https://hub.example.com/",
+			expectedTopic: "https://example.com/rss.xml",
+		},
+		"atom with relative hub": {
+			data:          atomFeed,
+			feedURL:       "https://example.com/feed",
+			expectedHub:   "https://example.com/hub",
+			expectedTopic: "https://example.com/atom.xml",
+		},
+		"link headers": {
+			data: atomFeed,
+			header: http.Header{"Link": {
+				`<https://header-hub.example.com/>; rel="hub", <https://example.com/topic>; rel="self"`,
+			}},
+			feedURL:       "https://example.com/feed",
+			expectedHub:   "https://header-hub.example.com/",
+			expectedTopic: "https://example.com/topic",
+		},
+		"no hub": {
+			data:    testRSSFeed("https://example.com/self"),
+			feedURL: "https://example.com/feed",
+		},
+		"hub in items only": {
+			data:    strings.Replace(atomFeed, `<link href="/hub" rel="hub"/>`, "", 1),
+			feedURL: "https://example.com/feed",
+		},
+	} {
+		fetched, err := gofeed.NewParser().ParseString(tc.data)
+		if err != nil {
+			t.Fatalf("[%s] failed to parse feed: %s", name, err)
+		}
+		header := tc.header
+		if header == nil {
+			header = http.Header{}
+		}
+
+		hub, topic := discoverWebSub([]byte(tc.data), header, fetched, tc.feedURL)
+		if hub != tc.expectedHub || topic != tc.expectedTopic {
+			t.Errorf("[%s] expected hub '%s' and topic '%s', got '%s' and '%s'", name, tc.expectedHub, tc.expectedTopic, hub, topic)
+		}
+	}
+}
+
+// test subscribing to websub hubs, and receiving pushed contents
+func TestWebSubSubscription(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var subscriptions []url.Values
+	var challenged string
+
+	hub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+
+		mu.Lock()
+		subscriptions = append(subscriptions, r.PostForm)
+		mu.Unlock()
+
+		// verify the intent (synchronously)
+		query := url.Values{
+			"hub.mode":          {"subscribe"},
+			"hub.topic":         {r.PostForm.Get("hub.topic")},
+			"hub.challenge":     {"challenge-1234"},
+			"hub.lease_seconds": {r.PostForm.Get("hub.lease_seconds")},
+		}
+		resp, err := http.Get(r.PostForm.Get("hub.callback") + "?" + query.Encode())
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+
+		mu.Lock()
+		challenged = string(body)
+		mu.Unlock()
+
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer hub.Close()
+
+	feedServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprint(w, testWebSubFeed(hub.URL, "websub-1"))
+	}))
+	defer feedServer.Close()
+
+	client := NewClient([]string{"key"}, []string{feedServer.URL})
+
+	callback := httptest.NewServer(client.WebSubHandler())
+	defer callback.Close()
+
+	pushes := make(chan gofeed.Feed, 10)
+	if err := client.SetWebSub(WebSubOptions{
+		CallbackURL: callback.URL + "/websub",
+		OnPush: func(ctx context.Context, feed gofeed.Feed) {
+			pushes <- feed
+		},
+	}); err != nil {
+		t.Fatalf("failed to set websub: %s", err)
+	}
+	if err := client.SetWebSub(WebSubOptions{CallbackURL: "/relative"}); err == nil {
+		t.Errorf("expected error with an invalid callback url")
+	}
+
+	// subscribed while fetching
+	for range 2 {
+		if _, err := client.FetchFeeds(ctx, true, 7); err != nil {
+			t.Fatalf("failed to fetch feeds: %s", err)
+		}
+	}
+	if len(subscriptions) != 1 {
+		t.Fatalf("expected 1 subscription request, got %d", len(subscriptions))
+	}
+	form := subscriptions[0]
+	if form.Get("hub.mode") != "subscribe" || form.Get("hub.topic") != feedServer.URL || form.Get("hub.lease_seconds") != fmt.Sprint(defaultWebSubLeaseSeconds) || len(form.Get("hub.secret")) <= 0 {
+		t.Errorf("unexpected subscription request: %+v", form)
+	}
+	if challenged != "challenge-1234" {
+		t.Errorf("expected challenge to be echoed, got '%s'", challenged)
+	}
+	feed, _ := client.cache.FetchFeed(ctx, feedServer.URL)
+	if feed.HubURL != hub.URL || !feed.WebSubRequestedAt.IsZero() || time.Until(feed.WebSubLeaseUntil) < 6*24*time.Hour {
+		t.Errorf("unexpected subscription of feed: %+v", feed)
+	}
+
+	// verifications of unknown topics or callbacks are rejected
+	callbackURL := form.Get("hub.callback")
+	for _, target := range []string{
+		callbackURL + "?hub.mode=subscribe&hub.topic=https://example.com/other&hub.challenge=abc",
+		callbackURL + "?hub.mode=unsubscribe&hub.topic=" + url.QueryEscape(feedServer.URL) + "&hub.challenge=abc",
+		callback.URL + "/websub/unknown?hub.mode=subscribe&hub.topic=" + url.QueryEscape(feedServer.URL) + "&hub.challenge=abc",
+		callbackURL + "?hub.mode=denied&hub.topic=" + url.QueryEscape(feedServer.URL), // (not pending)
+		callbackURL + "?hub.mode=denied&hub.topic=https://example.com/other",
+	} {
+		if resp, err := http.Get(target); err != nil || resp.StatusCode != http.StatusNotFound {
+			t.Errorf("expected verification of '%s' to be rejected, got %v", target, resp.Status)
+		}
+	}
+	if feed, _ := client.cache.FetchFeed(ctx, feedServer.URL); feed.WebSubLeaseUntil.IsZero() {
+		t.Errorf("expected subscription to be kept after rejected denials, got %+v", feed)
+	}
+
+	// push contents
+	push := func(body, signature string) int {
+		req, _ := http.NewRequest(http.MethodPost, callbackURL, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/rss+xml")
+		req.Header.Set(WebSubSignatureHeader, signature)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to push: %s", err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+	secret := form.Get("hub.secret")
+
+	_ = client.cache.Save(ctx, ItemToCache{Item: testFeedItem("websub-cached", "Cached"), Title: "Cached", Summary: "Summary", FeedURL: feedServer.URL})
+	body := testWebSubFeed(hub.URL, "websub-cached", "websub-2")
+	if status := push(body, signWebSub(secret, []byte(body))); status != http.StatusAccepted {
+		t.Fatalf("expected push to be accepted, got %d", status)
+	}
+	select {
+	case pushed := <-pushes:
+		if len(pushed.Items) != 1 || pushed.Items[0].GUID != "websub-2" || feedURLOf(pushed) != feedServer.URL {
+			t.Errorf("expected only the new item to be pushed, got %+v", pushed.Items)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for pushed items")
+	}
+
+	// (signatures with other algorithms)
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write([]byte(body))
+	if !verifyWebSubSignature(secret, []byte(body), "sha1="+hex.EncodeToString(mac.Sum(nil))) {
+		t.Errorf("expected sha1 signature to be valid")
+	}
+
+	// contents with invalid signatures are acknowledged but ignored
+	body = testWebSubFeed(hub.URL, "websub-3")
+	for _, signature := range []string{"", signWebSub("wrong", []byte(body)), "md5=abcd"} {
+		if status := push(body, signature); status != http.StatusAccepted {
+			t.Errorf("expected push with invalid signature to be acknowledged, got %d", status)
+		}
+	}
+	select {
+	case pushed := <-pushes:
+		t.Errorf("expected pushes with invalid signatures to be ignored, got %+v", pushed.Items)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// test changing options of websub and moving feeds while pushes are processed (run with -race)
+func TestWebSubConcurrently(t *testing.T) {
+	ctx := context.Background()
+
+	feedURLs := []string{"https://example.com/concurrent.xml", "https://example.com/moved.xml"}
+	client := NewClient([]string{"key"}, feedURLs[:1])
+	if err := client.cache.SaveFeed(ctx, Feed{URL: feedURLs[0]}); err != nil {
+		t.Fatalf("failed to save feed: %s", err)
+	}
+
+	var processed sync.WaitGroup
+	opts := WebSubOptions{
+		CallbackURL: "https://example.com/websub",
+		OnPush: func(ctx context.Context, feed gofeed.Feed) {
+			defer processed.Done()
+			if _, err := client.ListPublishedFeeds(ctx); err != nil {
+				t.Errorf("failed to list published feeds: %s", err)
+			}
+		},
+	}
+	if err := client.SetWebSub(opts); err != nil {
+		t.Fatalf("failed to set websub: %s", err)
+	}
+
+	const count = 20
+	processed.Add(count)
+
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		for range count {
+			client.webSubPushes <- gofeed.Feed{Title: "pushed"}
+		}
+	})
+	wg.Go(func() {
+		for range count {
+			if err := client.SetWebSub(opts); err != nil {
+				t.Errorf("failed to set websub: %s", err)
+			}
+		}
+	})
+	wg.Go(func() {
+		for i := range 4 {
+			if err := client.moveFeed(ctx, feedURLs[i%2], feedURLs[(i+1)%2]); err != nil {
+				t.Errorf("failed to move feed: %s", err)
+			}
+		}
+	})
+	wg.Wait()
+	processed.Wait()
+
+	if urls := client.getFeedsURLs(); len(urls) != 1 || urls[0] != feedURLs[0] {
+		t.Errorf("expected feed to be moved back, got %v", urls)
+	}
+}
+
+// test pinging websub hubs of published feeds
+func TestPingWebSubHub(t *testing.T) {
+	ctx := context.Background()
+
+	var forms []url.Values
+	failing := false
+	hub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing {
+			http.Error(w, "unknown topic", http.StatusBadRequest)
+			return
+		}
+		_ = r.ParseForm()
+		forms = append(forms, r.PostForm)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer hub.Close()
+
+	client := NewClient([]string{"key"}, nil)
+	if err := client.PingWebSubHub(ctx); err == nil {
+		t.Errorf("expected error without hub")
+	}
+
+	client.SetWebSubHub(hub.URL, []string{"https://example.com/feed.xml", "https://example.com/atom.xml"})
+	if err := client.PingWebSubHub(ctx); err != nil {
+		t.Fatalf("failed to ping hub: %s", err)
+	}
+	if len(forms) != 2 || forms[0].Get("hub.mode") != "publish" || forms[1].Get("hub.url") != "https://example.com/atom.xml" {
+		t.Errorf("unexpected pings: %+v", forms)
+	}
+
+	failing = true
+	if err := client.PingWebSubHub(ctx); err == nil || !strings.Contains(err.Error(), "unknown topic") {
+		t.Errorf("expected error from failing hub, got %v", err)
+	}
+}
+
+// test advertising websub hubs in published feeds
+func TestPublishWebSubLinks(t *testing.T) {
+	client := NewClient([]string{"key"}, nil)
+	items := []CachedItem{
+		{GUID: "https://example.com/1", Title: "Item", Link: "https://example.com/1", Summary: "summary"},
+	}
+
+	for _, format := range []PublishFormat{PublishFormatRSS, PublishFormatAtom} {
+		bytes, _, err := client.PublishWithOptions(items, PublishOptions{
+			Format:  format,
+			Title:   "Published",
+			Link:    "https://example.com/",
+			HubURL:  "https://hub.example.com/",
+			SelfURL: "https://example.com/feed",
+		})
+		if err != nil {
+			t.Fatalf("[%s] failed to publish: %s", format, err)
+		}
+
+		fetched, err := gofeed.NewParser().ParseString(string(bytes))
+		if err != nil {
+			t.Fatalf("[%s] failed to parse published feed: %s", format, err)
+		}
+		hub, topic := discoverWebSub(bytes, http.Header{}, fetched, "https://example.com/other")
+		if hub != "https://hub.example.com/" || topic != "https://example.com/feed" {
+			t.Errorf("[%s] unexpected hub '%s' and topic '%s'", format, hub, topic)
+		}
+		if fetched.Link != "https://example.com/" {
+			t.Errorf("[%s] expected link to be kept, got '%s'", format, fetched.Link)
+		}
+	}
+
+	bytes, _, err := client.PublishWithOptions(items, PublishOptions{Format: PublishFormatJSON, HubURL: "https://hub.example.com/"})
+	if err != nil || !strings.Contains(string(bytes), `"type": "WebSub"`) {
+		t.Errorf("expected hubs in json feed, got %s (%v)", bytes, err)
+	}
+
+	// no websub links by default
+	bytes, _, _ = client.PublishWithOptions(items, PublishOptions{Format: PublishFormatRSS})
+	if strings.Contains(string(bytes), "atom:link") {
+		t.Errorf("expected no websub links, got %s", bytes)
+	}
+}